@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// fileNameColor is the SGR sequence for file names in headers and prefixes,
+// magenta as in grep.
+const (
+	fileNameColor = "\x1b[35m"
+	colorReset    = "\x1b[0m"
+)
+
+// resolveColor decides whether to color output for a --color mode. auto
+// colors only a terminal, and never when NO_COLOR is set
+// (https://no-color.org); always overrides both.
+func resolveColor(mode string, noColor, terminal bool) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		return terminal && !noColor, nil
+	default:
+		return false, fmt.Errorf("invalid color mode: %s (use 'auto', 'always' or 'never')", mode)
+	}
+}
+
+// isTerminal reports whether w writes to a terminal or console rather than a
+// file or pipe.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorName wraps a file name in fileNameColor when on is set.
+func colorName(name string, on bool) string {
+	if !on {
+		return name
+	}
+	return fileNameColor + name + colorReset
+}
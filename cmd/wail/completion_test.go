@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCompletion_Shells(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		t.Run(shell, func(t *testing.T) {
+			var out bytes.Buffer
+			rootCmd.SetOut(&out)
+			rootCmd.SetArgs([]string{"completion", shell})
+			defer rootCmd.SetOut(nil)
+
+			if err := rootCmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if !strings.Contains(out.String(), "wail") {
+				t.Errorf("expected completion script mentioning wail, got %d bytes", out.Len())
+			}
+		})
+	}
+}
+
+func TestCompletion_UnknownShell(t *testing.T) {
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	rootCmd.SetArgs([]string{"completion", "tcsh"})
+	defer rootCmd.SetOut(nil)
+	defer rootCmd.SetErr(nil)
+
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("expected error for unsupported shell")
+	}
+}
+
+func TestCompletion_OutputEncodingValues(t *testing.T) {
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"__complete", "--output-encoding="})
+	defer rootCmd.SetOut(nil)
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	got := out.String()
+	for _, want := range []string{"utf-16le", "windows-1252", "shift_jis"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in --output-encoding completions, got %q", want, got)
+		}
+	}
+}
+
+func TestCompletion_FollowValues(t *testing.T) {
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"__complete", "--follow="})
+	defer rootCmd.SetOut(nil)
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	got := out.String()
+	for _, want := range []string{"name", "descriptor"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in --follow completions, got %q", want, got)
+		}
+	}
+}
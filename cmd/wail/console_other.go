@@ -0,0 +1,15 @@
+//go:build !windows
+
+package main
+
+import "io"
+
+// setupConsole is a no-op outside Windows; Unix terminals take UTF-8 as-is.
+func setupConsole(out io.Writer) (restore func()) {
+	return func() {}
+}
+
+// enableColor is a no-op outside Windows; terminals interpret escapes as-is.
+func enableColor(out io.Writer) (restore func()) {
+	return func() {}
+}
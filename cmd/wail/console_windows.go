@@ -0,0 +1,65 @@
+//go:build windows
+
+package main
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// cpUTF8 is the Windows code page identifier for UTF-8 (CP_UTF8).
+const cpUTF8 = 65001
+
+// setupConsole switches a real console's output code page to UTF-8 so UTF-8
+// log content isn't garbled on legacy code pages, and returns a function that
+// restores the original code page. Redirected output (files, pipes) is left
+// alone, and any failure falls through silently since some terminals reject it.
+func setupConsole(out io.Writer) (restore func()) {
+	restore = func() {}
+
+	f, ok := out.(*os.File)
+	if !ok {
+		return restore
+	}
+
+	var mode uint32
+	if err := windows.GetConsoleMode(windows.Handle(f.Fd()), &mode); err != nil {
+		return restore // Not a console
+	}
+
+	original, err := windows.GetConsoleOutputCP()
+	if err != nil || original == cpUTF8 {
+		return restore
+	}
+	if err := windows.SetConsoleOutputCP(cpUTF8); err != nil {
+		return restore
+	}
+	return func() { windows.SetConsoleOutputCP(original) }
+}
+
+// enableColor turns on escape-sequence handling for a real console, which
+// older Windows consoles leave off, and returns a function that restores the
+// original mode. Redirected output is left alone.
+func enableColor(out io.Writer) (restore func()) {
+	restore = func() {}
+
+	f, ok := out.(*os.File)
+	if !ok {
+		return restore
+	}
+	h := windows.Handle(f.Fd())
+
+	var mode uint32
+	if err := windows.GetConsoleMode(h, &mode); err != nil {
+		return restore // Not a console
+	}
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+		return restore
+	}
+	if err := windows.SetConsoleMode(h, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING); err != nil {
+		return restore
+	}
+	return func() { windows.SetConsoleMode(h, mode) }
+}
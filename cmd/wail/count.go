@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/jmurray2011/wail/internal/tail"
+	"github.com/spf13/cobra"
+)
+
+// runCount tails each path as usual but discards what it would output,
+// printing to output instead how many lines (or, with -c or a c= count
+// in specs, bytes) each produced, labelled as labels says, and with totals a total. Filters such
+// as --grep apply first, so with one the count is of matching lines. When
+// following, every path is followed at once and the counts are printed when
+// following stops, e.g. on Ctrl-C. It returns true if any input failed;
+// those get no count.
+func runCount(ctx context.Context, cmd *cobra.Command, paths []string, base tail.TailerConfig, specs fileSpecs, output io.Writer, labels fileLabels, totals bool) bool {
+	counters := make([]*countingWriter, len(paths))
+	errs := make([]error, len(paths))
+
+	count := func(i int) {
+		counters[i] = &countingWriter{w: io.Discard, delim: base.OutputDelimiter}
+		config := specs.config(base, paths[i])
+		if paths[i] == "-" {
+			errs[i] = newTailer(config).TailReader(ctx, os.Stdin, counters[i])
+			return
+		}
+		errs[i] = newTailer(config).Tail(ctx, counters[i])
+	}
+
+	// Following never ends on its own, so one file can't wait for another
+	if base.Follow {
+		var wg sync.WaitGroup
+		for i := range paths {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				count(i)
+			}()
+		}
+		wg.Wait()
+	} else {
+		for i := range paths {
+			count(i)
+		}
+	}
+
+	headerPrinted := false
+	failed := false
+	var total int64
+	for i, path := range paths {
+		name := labels.name(path)
+		if err := errs[i]; err != nil {
+			if path == "-" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "wail: standard input: %v\n", err)
+			} else {
+				reportError(cmd.ErrOrStderr(), path, err)
+			}
+			failed = true
+			continue
+		}
+
+		n := counters[i].lines
+		if config := specs.config(base, path); config.Bytes > 0 || config.BytesSet {
+			n = counters[i].bytes
+		}
+		total += n
+
+		switch {
+		case labels.prefix:
+			fmt.Fprintf(output, "%s%d\n", labels.linePrefix(name), n)
+		case labels.headers:
+			hw := &headerWriter{out: labels.headerDest(output), w: output, name: name, note: labels.note, printed: &headerPrinted, color: labels.color, compact: labels.compact}
+			fmt.Fprintf(hw, "%d\n", n)
+		default:
+			fmt.Fprintf(output, "%d\n", n)
+		}
+	}
+
+	// The total follows the same visibility rules as the --totals footer
+	if totals && labels.headers {
+		fmt.Fprintf(labels.headerDest(output), "%s==> total <==\n", labels.headerGap())
+		fmt.Fprintf(output, "%d\n", total)
+	}
+	return failed
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// outputEncodingNames are the charsets offered when completing
+// --output-encoding; any other name parseOutputEncoding knows works too.
+var outputEncodingNames = []string{
+	"utf-8", "utf-16le", "utf-16be",
+	"windows-1252", "iso-8859-1", "iso-8859-15", "windows-1250", "iso-8859-2",
+	"windows-1251", "koi8-r",
+	"shift_jis", "euc-jp", "gbk", "gb18030", "big5", "euc-kr",
+}
+
+// parseOutputEncoding returns the charset --output-encoding names, by any
+// name a browser knows it by (windows-1252, latin1, utf-16le, shift_jis...),
+// or nil for UTF-8, which output already is.
+func parseOutputEncoding(name string) (encoding.Encoding, error) {
+	if name == "" {
+		return nil, nil
+	}
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output-encoding value: %s", name)
+	}
+	if enc == unicode.UTF8 {
+		return nil, nil
+	}
+	return enc, nil
+}
+
+// encodingWriter transcodes the UTF-8 written to it into another charset on
+// w; a character the charset lacks is written as its replacement character.
+// Writers following several files share it, so writes are serialised.
+type encodingWriter struct {
+	mu sync.Mutex
+	tw *transform.Writer
+}
+
+func newEncodingWriter(w io.Writer, enc encoding.Encoding) *encodingWriter {
+	return &encodingWriter{tw: transform.NewWriter(w, encoding.ReplaceUnsupported(enc.NewEncoder()))}
+}
+
+func (e *encodingWriter) Write(p []byte) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.tw.Write(p)
+}
+
+// Close writes out the end of a character split across writes, if output
+// ended part way through one. It doesn't close w.
+func (e *encodingWriter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.tw.Close()
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestCLI_OutputEncoding(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte("héllo\nwörld €\n"), 0644)
+	zeroFile := filepath.Join(dir, "zero.txt")
+	os.WriteFile(zeroFile, []byte("é\x00ü\x00"), 0644)
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{"windows-1252", []string{"--output-encoding", "windows-1252", testFile}, "h\xe9llo\nw\xf6rld \x80\n", ""},
+		{"latin1 is windows-1252", []string{"--output-encoding", "latin1", "-n", "1", testFile}, "w\xf6rld \x80\n", ""},
+		{"missing characters replaced", []string{"--output-encoding", "iso-8859-2", "-n", "1", testFile}, "w\xf6rld \x1a\n", ""},
+		{"utf-8 passes through", []string{"--output-encoding", "utf-8", testFile}, "héllo\nwörld €\n", ""},
+		{"NUL delimiters encoded", []string{"--output-encoding", "utf-16le", "-z", zeroFile}, "\xe9\x00\x00\x00\xfc\x00\x00\x00", ""},
+		{"byte mode left as is", []string{"--output-encoding", "windows-1252", "-c", "4", testFile}, "€\n", ""},
+		{"unknown charset", []string{"--output-encoding", "klingon", testFile}, "", "invalid output-encoding value: klingon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(tt.args)
+			err := cmd.Execute()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Execute() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputEncodingNames(t *testing.T) {
+	// Every name offered for completion is one the flag accepts
+	for _, name := range outputEncodingNames {
+		if _, err := parseOutputEncoding(name); err != nil {
+			t.Errorf("parseOutputEncoding(%q) error = %v", name, err)
+		}
+	}
+}
+
+func TestCLI_OutputEncoding_UTF16RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	content := "plain ascii\nnaïve café\n日本語のログ\nemoji 🎉\n"
+	os.WriteFile(testFile, []byte(content), 0644)
+
+	var stdout bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--output-encoding", "utf-16le", testFile})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// Every character takes two or four bytes, with no byte order mark
+	if got := stdout.Bytes(); !bytes.HasPrefix(got, []byte("p\x00l\x00")) {
+		t.Fatalf("output starts %q, want UTF-16LE with no BOM", got[:min(len(got), 8)])
+	}
+	decoded, err := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewDecoder().Bytes(stdout.Bytes())
+	if err != nil {
+		t.Fatalf("decoding output: %v", err)
+	}
+	if got := string(decoded); got != content {
+		t.Errorf("round trip = %q, want %q", got, content)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jmurray2011/wail/internal/filesystem"
+)
+
+// readFilesFrom reads the list of paths to tail for --files-from from the
+// file name, or from stdin if name is "-". Paths are one per line, or
+// NUL-separated with zero; blank entries and those starting with # are
+// skipped. Glob patterns are expanded, and one matching nothing is kept as
+// given, as a shell would, so it is reported as missing.
+func readFilesFrom(name string, stdin io.Reader, zero bool) ([]string, error) {
+	r := stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf("reading files-from list: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	scanner := bufio.NewScanner(r)
+	if zero {
+		scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+			if i := bytes.IndexByte(data, 0); i >= 0 {
+				return i + 1, data[:i], nil
+			}
+			if atEOF && len(data) > 0 {
+				return len(data), data, nil
+			}
+			return 0, nil, nil
+		})
+	}
+
+	var paths []string
+	for scanner.Scan() {
+		entry := strings.TrimSuffix(scanner.Text(), "\r")
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if filesystem.IsSFTPPath(entry) || !strings.ContainsAny(entry, "*?[") {
+			paths = append(paths, entry)
+			continue
+		}
+		matches, err := filepath.Glob(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q in files-from list: %w", entry, err)
+		}
+		if len(matches) == 0 {
+			matches = []string{entry}
+		}
+		paths = append(paths, matches...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading files-from list: %w", err)
+	}
+	return paths, nil
+}
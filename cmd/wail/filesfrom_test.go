@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadFilesFrom(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.log", "b.log", "c.txt"} {
+		os.WriteFile(filepath.Join(dir, name), nil, 0644)
+	}
+	glob := filepath.Join(dir, "*.log")
+	missing := filepath.Join(dir, "*.gz")
+
+	tests := []struct {
+		name  string
+		input string
+		zero  bool
+		want  []string
+	}{
+		{"lines", "# app logs\n/var/log/one.log\n\n/var/log/two.log\r\n", false, []string{"/var/log/one.log", "/var/log/two.log"}},
+		{"NUL-separated", "/var/log/with\nnewline.log\x00/var/log/two.log", true, []string{"/var/log/with\nnewline.log", "/var/log/two.log"}},
+		{"glob", glob + "\n", false, []string{filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log")}},
+		{"glob matching nothing", missing + "\n", false, []string{missing}},
+		{"sftp path", "sftp://web1/var/log/*.log\n", false, []string{"sftp://web1/var/log/*.log"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readFilesFrom("-", strings.NewReader(tt.input), tt.zero)
+			if err != nil {
+				t.Fatalf("readFilesFrom() error: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("readFilesFrom() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLI_FilesFrom(t *testing.T) {
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "one.log")
+	file2 := filepath.Join(dir, "two.log")
+	os.WriteFile(file1, []byte("one\n"), 0644)
+	os.WriteFile(file2, []byte("two\n"), 0644)
+	manifest := filepath.Join(dir, "files.txt")
+	os.WriteFile(manifest, []byte("# generated\n"+file1+"\n\n"+file2+"\n"), 0644)
+
+	var out bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--files-from", manifest})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want := "==> " + file1 + " <==\none\n\n==> " + file2 + " <==\ntwo\n"
+	if got := out.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestCLI_FilesFromEmpty(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), "files.txt")
+	os.WriteFile(manifest, []byte("# nothing yet\n"), 0644)
+
+	cmd := newTestCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--files-from", manifest})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "no files listed") {
+		t.Errorf("Execute() error = %v, want a no files error", err)
+	}
+}
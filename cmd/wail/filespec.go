@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/jmurray2011/wail/internal/tail"
+)
+
+// fileSpecPattern matches a count given after a path, as in app.log:n=20 or
+// audit.log:c=+1K. The count can't hold a colon, so one earlier in the path,
+// as in C:\logs\app.log, sftp://host/app.log or archive.zip::app.log, is
+// left alone.
+var fileSpecPattern = regexp.MustCompile(`:([nc])=([^:=]*)$`)
+
+// fileSpec is a count of lines or bytes for one file, replacing -n or -c.
+type fileSpec struct {
+	bytes  bool
+	count  int64
+	anchor numAnchor
+}
+
+// fileSpecs holds the count each path was given, by path.
+type fileSpecs map[string]fileSpec
+
+// splitFileSpecs strips the count from each argument given one, returning
+// the paths and the counts they were given. An argument naming a file that
+// exists is taken as it is, so a file whose name ends in ":n=5" can still be
+// tailed.
+func splitFileSpecs(args []string) ([]string, fileSpecs, error) {
+	paths := make([]string, len(args))
+	specs := fileSpecs{}
+	for i, arg := range args {
+		paths[i] = arg
+		m := fileSpecPattern.FindStringSubmatchIndex(arg)
+		if m == nil {
+			continue
+		}
+		if _, err := os.Stat(arg); err == nil {
+			continue
+		}
+		path, kind, value := arg[:m[0]], arg[m[2]:m[3]], arg[m[4]:m[5]]
+		if path == "" || value == "" {
+			return nil, nil, fmt.Errorf("invalid file count %q (use PATH:n=N or PATH:c=N)", arg)
+		}
+		count, anchor, err := parseNumArg(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid file count %q: %w", arg, err)
+		}
+		if kind == "c" && anchor == anchorAllButLast {
+			return nil, nil, fmt.Errorf("invalid file count %q: ~N is only supported with n=", arg)
+		}
+		paths[i] = path
+		specs[path] = fileSpec{bytes: kind == "c", count: count, anchor: anchor}
+	}
+	return paths, specs, nil
+}
+
+// config returns base set up to tail path, with the count path was given, if
+// any, in place of the one from -n or -c. Standard input ("-") keeps an
+// empty Path, as TailReader expects.
+func (s fileSpecs) config(base tail.TailerConfig, path string) tail.TailerConfig {
+	config := base
+	if path != "-" {
+		config.Path = path
+	}
+	spec, ok := s[path]
+	if !ok {
+		return config
+	}
+
+	// The count is the whole selection; nothing chosen by the flags remains
+	config.HeadTail = 0
+	config.StartOffset, config.StartOffsetSet = 0, false
+	config.BytesThrough = 0
+	config.FromStart = spec.anchor == anchorStart
+	config.AllButLast = spec.anchor == anchorAllButLast
+	if spec.bytes {
+		config.Lines, config.LinesSet = 0, false
+		config.Bytes, config.BytesSet = spec.count, true
+	} else {
+		config.Lines, config.LinesSet = int(spec.count), true
+		config.Bytes, config.BytesSet = 0, false
+	}
+	return config
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitFileSpecs(t *testing.T) {
+	dir := t.TempDir()
+	literal := filepath.Join(dir, "odd:n=5")
+	os.WriteFile(literal, nil, 0644)
+
+	paths, specs, err := splitFileSpecs([]string{"app.log:n=20", "audit.log:c=+1K", `C:\logs\app.log`, "archive.zip::app.log", literal, "plain.log"})
+	if err != nil {
+		t.Fatalf("splitFileSpecs() error = %v", err)
+	}
+	wantPaths := []string{"app.log", "audit.log", `C:\logs\app.log`, "archive.zip::app.log", literal, "plain.log"}
+	if strings.Join(paths, "|") != strings.Join(wantPaths, "|") {
+		t.Errorf("paths = %q, want %q", paths, wantPaths)
+	}
+	if got, want := specs["app.log"], (fileSpec{count: 20, anchor: anchorEnd}); got != want {
+		t.Errorf("app.log spec = %+v, want %+v", got, want)
+	}
+	if got, want := specs["audit.log"], (fileSpec{bytes: true, count: 1024, anchor: anchorStart}); got != want {
+		t.Errorf("audit.log spec = %+v, want %+v", got, want)
+	}
+	if len(specs) != 2 {
+		t.Errorf("specs = %v, want only app.log and audit.log", specs)
+	}
+
+	for _, arg := range []string{"app.log:n=", ":n=5", "app.log:n=x", "app.log:c=~5"} {
+		if _, _, err := splitFileSpecs([]string{arg}); err == nil || !strings.Contains(err.Error(), "invalid file count") {
+			t.Errorf("splitFileSpecs(%q) error = %v, want invalid file count", arg, err)
+		}
+	}
+}
+
+func TestCLI_PerFileCounts(t *testing.T) {
+	dir := t.TempDir()
+	app := filepath.Join(dir, "app.log")
+	audit := filepath.Join(dir, "audit.log")
+	other := filepath.Join(dir, "other.log")
+	os.WriteFile(app, []byte("a1\na2\na3\na4\na5\n"), 0644)
+	os.WriteFile(audit, []byte("b1\nb2\nb3\nb4\nb5\n"), 0644)
+	os.WriteFile(other, []byte("c1\nc2\nc3\nc4\nc5\n"), 0644)
+
+	var stdout bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"-q", "-n", "1", app + ":n=3", audit + ":n=+4", other})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// Each file gets its own count; one without keeps -n
+	if got, want := stdout.String(), "a3\na4\na5\nb4\nb5\nc5\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestCLI_PerFileCountsChecked(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"-f", "app.log:n=~2"}, "cannot follow with n=~N"},
+		{[]string{"--grep", "x", "app.log:c=5"}, "cannot combine c=N with --grep"},
+		{[]string{"--output-encoding", "latin1", "app.log:n=5", "audit.log:c=5"}, "cannot combine c=N with --output-encoding"},
+	}
+
+	for _, tt := range tests {
+		cmd := newTestCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(tt.args)
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%v: error = %v, want one containing %q", tt.args, err, tt.wantErr)
+		}
+	}
+}
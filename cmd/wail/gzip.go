@@ -0,0 +1,63 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// gzipWriter compresses everything written to it onto w. Writers following
+// several files share it, so writes are serialised.
+type gzipWriter struct {
+	mu      sync.Mutex
+	zw      *gzip.Writer
+	pending bool // written to since the last flush
+}
+
+func newGzipWriter(w io.Writer) *gzipWriter {
+	return &gzipWriter{zw: gzip.NewWriter(w)}
+}
+
+func (g *gzipWriter) Write(p []byte) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.pending = g.pending || len(p) > 0
+	return g.zw.Write(p)
+}
+
+// Flush writes out everything compressed so far, so a reader can
+// decompress it before the stream ends.
+func (g *gzipWriter) Flush() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.pending {
+		return nil
+	}
+	g.pending = false
+	return g.zw.Flush()
+}
+
+// flushEvery flushes once per interval until ctx is cancelled, so each
+// batch of followed lines is readable within about one poll.
+func (g *gzipWriter) flushEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.Flush()
+		}
+	}
+}
+
+// Close writes the gzip footer. Without it the output is truncated as far
+// as gunzip is concerned, so it must run however wail exits.
+func (g *gzipWriter) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.zw.Close()
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe to write and read from different goroutines.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return bytes.Clone(b.buf.Bytes())
+}
+
+func TestGzipWriter_FlushEvery(t *testing.T) {
+	var out syncBuffer
+	gz := newGzipWriter(&out)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go gz.flushEvery(ctx, 10*time.Millisecond)
+
+	want := "live line\n"
+	gz.Write([]byte(want))
+
+	// The line becomes readable without closing the stream
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if zr, err := gzip.NewReader(bytes.NewReader(out.Bytes())); err == nil {
+			got := make([]byte, len(want))
+			if _, err := io.ReadFull(zr, got); err == nil {
+				if string(got) != want {
+					t.Fatalf("decompressed %q, want %q", got, want)
+				}
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("line not flushed before the stream was closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	if err := gz.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error: %v", err)
+	}
+	all, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("reading closed stream: %v", err)
+	}
+	if string(all) != want {
+		t.Errorf("decompressed %q, want %q", all, want)
+	}
+}
+
+func TestCLI_GzipOutput(t *testing.T) {
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "a.log")
+	file2 := filepath.Join(dir, "b.log")
+	os.WriteFile(file1, []byte("a1\na2\n"), 0644)
+	os.WriteFile(file2, []byte("b1\n"), 0644)
+
+	var out bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--gzip-output", "--totals", file1, file2})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error: %v", err)
+	}
+
+	zr, err := gzip.NewReader(&out)
+	if err != nil {
+		t.Fatalf("output is not gzip: %v", err)
+	}
+	got, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("decompressing output: %v", err)
+	}
+
+	// Headers and the footer are compressed along with the content
+	want := "==> " + file1 + " <==\na1\na2\n\n==> " + file2 + " <==\nb1\n\n==> total <==\n3 lines, 9 bytes\n"
+	if string(got) != want {
+		t.Errorf("decompressed output = %q, want %q", got, want)
+	}
+}
+
+func TestCLI_LineBuffered(t *testing.T) {
+	// Each write through the gzip stream is readable at once, without
+	// waiting for the poll-interval flush or the end of the stream
+	var out syncBuffer
+	gz := newGzipWriter(&out)
+	w := &flushingWriter{w: gz, flush: gz.Flush}
+	w.Write([]byte("live line\n"))
+	zr, err := gzip.NewReader(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error: %v", err)
+	}
+	got := make([]byte, len("live line\n"))
+	if _, err := io.ReadFull(zr, got); err != nil || string(got) != "live line\n" {
+		t.Errorf("decompressed %q (error %v) before the stream was closed, want %q", got, err, "live line\n")
+	}
+
+	cmd := newTestCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--line-buffered", "--lines-output-buffer", "batch", os.DevNull})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "cannot combine --line-buffered") {
+		t.Errorf("Execute() error = %v, want cannot combine", err)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jmurray2011/wail/internal/pager"
+	"github.com/spf13/cobra"
+)
+
+// runInteractive pages through the single file in args on the terminal,
+// checking it for growth every interval. It refuses redirected input or
+// output, since the pager needs key presses and a screen to draw on.
+func runInteractive(ctx context.Context, cmd *cobra.Command, args []string, interval time.Duration) error {
+	if len(args) != 1 || args[0] == "-" {
+		return fmt.Errorf("--interactive needs exactly one file")
+	}
+	out, ok := cmd.OutOrStdout().(*os.File)
+	if !ok || !isTerminal(out) || !isTerminal(os.Stdin) {
+		return fmt.Errorf("--interactive needs a terminal; use -f to follow into a pipe or file")
+	}
+	if info, err := os.Stat(args[0]); err == nil && info.IsDir() {
+		return fmt.Errorf("%s: Is a directory", args[0])
+	}
+	return pager.Run(ctx, pager.Config{Path: args[0], PollInterval: interval}, os.Stdin, out)
+}
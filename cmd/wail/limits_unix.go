@@ -0,0 +1,14 @@
+//go:build !windows
+
+package main
+
+import "golang.org/x/sys/unix"
+
+// openFileLimit returns the soft limit on open file descriptors.
+func openFileLimit() (int, bool) {
+	var rl unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_NOFILE, &rl); err != nil || rl.Cur == unix.RLIM_INFINITY {
+		return 0, false
+	}
+	return int(rl.Cur), true
+}
@@ -0,0 +1,7 @@
+//go:build windows
+
+package main
+
+// openFileLimit reports no limit on Windows, where handles aren't capped per
+// process the way Unix file descriptors are.
+func openFileLimit() (int, bool) { return 0, false }
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jmurray2011/wail/internal/tail"
+)
+
+// merger orders the lines of several followed files by the timestamp each
+// starts with, for --merge-sorted. Files are read independently, so a line
+// may arrive after a later-stamped one from another file; each timestamped
+// line is held for up to window after it arrives, and those held are written
+// in timestamp order, so lines arriving within window of each other come out
+// sorted. A line without a timestamp follows the lines held from its own
+// file, as the continuation of a multi-line entry would, or is written at
+// once if none are held.
+type merger struct {
+	layout string
+	window time.Duration
+	lines  chan mergedLine
+	done   chan struct{}
+
+	mu  sync.Mutex
+	err error // the first write error, returned to every source
+}
+
+// mergedLine is a line read from src, delimiter included, and the timestamp
+// it starts with if stamped.
+type mergedLine struct {
+	src     *mergeSource
+	text    []byte
+	stamp   time.Time
+	stamped bool
+}
+
+// heldLine is a timestamped line the merger is holding, followed by any
+// lines without timestamps that came after it from the same file.
+type heldLine struct {
+	src     *mergeSource
+	stamp   time.Time
+	arrived time.Time
+	lines   [][]byte
+}
+
+// newMerger starts a merger parsing timestamps with layout and holding lines
+// for window. Close stops it.
+func newMerger(layout string, window time.Duration) *merger {
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	m := &merger{
+		layout: layout,
+		window: window,
+		lines:  make(chan mergedLine, 64),
+		done:   make(chan struct{}),
+	}
+	go m.run()
+	return m
+}
+
+// source returns a writer for one file's output, whose lines end in delim,
+// that hands each complete line to the merger to be written to w.
+func (m *merger) source(w io.Writer, delim byte) *mergeSource {
+	return &mergeSource{m: m, w: w, delim: delim}
+}
+
+// Close writes out every line still held, in order, once all sources have
+// been closed. Tailing has stopped by then, so a failure to write them has
+// no one to stop and is dropped, as when output goes away at exit.
+func (m *merger) Close() {
+	close(m.lines)
+	<-m.done
+}
+
+func (m *merger) failed() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.err
+}
+
+func (m *merger) run() {
+	defer close(m.done)
+	var held []*heldLine
+	last := make(map[*mergeSource]*heldLine) // the latest line held from each file
+
+	for {
+		var wait <-chan time.Time
+		if len(held) > 0 {
+			oldest := held[0].arrived
+			for _, h := range held[1:] {
+				if h.arrived.Before(oldest) {
+					oldest = h.arrived
+				}
+			}
+			wait = time.After(time.Until(oldest.Add(m.window)))
+		}
+
+		select {
+		case line, ok := <-m.lines:
+			if !ok {
+				m.release(held, len(held), last)
+				return
+			}
+			if !line.stamped {
+				if h := last[line.src]; h != nil {
+					h.lines = append(h.lines, line.text)
+				} else {
+					m.write(line.src, line.text)
+				}
+				continue
+			}
+			h := &heldLine{src: line.src, stamp: line.stamp, arrived: time.Now(), lines: [][]byte{line.text}}
+			i, _ := slices.BinarySearchFunc(held, h, func(a, b *heldLine) int {
+				if a.stamp.After(b.stamp) {
+					return 1
+				}
+				return -1 // Equal stamps stay in arrival order
+			})
+			held = slices.Insert(held, i, h)
+			last[line.src] = h
+
+		case <-wait:
+			// Everything up to the last line held for the whole window goes,
+			// lines stamped earlier than it included
+			now := time.Now()
+			n := 0
+			for i, h := range held {
+				if !now.Before(h.arrived.Add(m.window)) {
+					n = i + 1
+				}
+			}
+			held = m.release(held, n, last)
+		}
+	}
+}
+
+// release writes the first n held lines and returns the rest.
+func (m *merger) release(held []*heldLine, n int, last map[*mergeSource]*heldLine) []*heldLine {
+	for _, h := range held[:n] {
+		for _, text := range h.lines {
+			m.write(h.src, text)
+		}
+		if last[h.src] == h {
+			delete(last, h.src)
+		}
+	}
+	return held[n:]
+}
+
+// write writes text to src's writer, recording the first error.
+func (m *merger) write(src *mergeSource, text []byte) {
+	if _, err := src.w.Write(text); err != nil {
+		m.mu.Lock()
+		if m.err == nil {
+			m.err = err
+		}
+		m.mu.Unlock()
+	}
+}
+
+// mergeSource splits one file's output into lines for a merger.
+type mergeSource struct {
+	m       *merger
+	w       io.Writer
+	delim   byte
+	partial []byte // output after the last delimiter
+}
+
+// Write fails once the merger has failed to write a line, so tailing stops
+// as it would writing directly.
+func (s *mergeSource) Write(p []byte) (int, error) {
+	if err := s.m.failed(); err != nil {
+		return 0, err
+	}
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, s.delim)
+		if i < 0 {
+			s.partial = append(s.partial, p...)
+			break
+		}
+		s.send(append(s.partial, p[:i+1]...))
+		s.partial = nil
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// Close hands over output left without a delimiter at the end.
+func (s *mergeSource) Close() error {
+	if len(s.partial) > 0 {
+		s.send(s.partial)
+		s.partial = nil
+	}
+	return nil
+}
+
+func (s *mergeSource) send(text []byte) {
+	content := bytes.TrimSuffix(bytes.TrimSuffix(text, []byte{s.delim}), []byte{'\r'})
+	stamp, ok := tail.LineTime(string(content), s.m.layout, time.Now())
+	s.m.lines <- mergedLine{src: s, text: text, stamp: stamp, stamped: ok}
+}
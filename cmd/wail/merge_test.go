@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmurray2011/wail/internal/tail"
+)
+
+func TestMerger_OrdersByTimestamp(t *testing.T) {
+	var out syncBuffer
+	m := newMerger("", time.Hour)
+	a := m.source(&out, '\n')
+	b := m.source(&out, '\n')
+
+	// b's earlier lines arrive after a's; a's stack trace stays with its line
+	a.Write([]byte("2024-01-01T10:00:02Z a2\n\tat frame\n2024-01-01T10:00:04Z a4\n"))
+	b.Write([]byte("2024-01-01T10:00:01Z b1\n2024-01-01T10:00:03Z "))
+	b.Write([]byte("b3\n2024-01-01T10:00:04Z b4"))
+	a.Close()
+	b.Close()
+	m.Close()
+
+	want := "2024-01-01T10:00:01Z b1\n" +
+		"2024-01-01T10:00:02Z a2\n\tat frame\n" +
+		"2024-01-01T10:00:03Z b3\n" +
+		"2024-01-01T10:00:04Z a4\n" +
+		"2024-01-01T10:00:04Z b4"
+	if got := string(out.Bytes()); got != want {
+		t.Errorf("merged output = %q, want %q", got, want)
+	}
+}
+
+// waitForOutput waits for out to hold exactly want.
+func waitForOutput(t *testing.T, out *syncBuffer, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for string(out.Bytes()) != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("output = %q, want %q", out.Bytes(), want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMerger_UnstampedLineNotHeld(t *testing.T) {
+	var out syncBuffer
+	m := newMerger("", time.Hour)
+	a := m.source(&out, '\n')
+	b := m.source(&out, '\n')
+
+	// A line without a timestamp, from a file with nothing held, goes at once
+	a.Write([]byte("2024-01-01T10:00:02Z a2\n"))
+	b.Write([]byte("no timestamp\n"))
+	waitForOutput(t, &out, "no timestamp\n")
+
+	a.Close()
+	b.Close()
+	m.Close()
+	waitForOutput(t, &out, "no timestamp\n2024-01-01T10:00:02Z a2\n")
+}
+
+func TestMerger_ReleasesAfterWindow(t *testing.T) {
+	var out syncBuffer
+	m := newMerger("", 50*time.Millisecond)
+	a := m.source(&out, '\n')
+	defer m.Close()
+	defer a.Close()
+
+	a.Write([]byte("2024-01-01T10:00:05Z a5\n"))
+	waitForOutput(t, &out, "2024-01-01T10:00:05Z a5\n")
+}
+
+func TestRunMultiFileFollow_MergeSorted(t *testing.T) {
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "a.log")
+	file2 := filepath.Join(dir, "b.log")
+	os.WriteFile(file1, []byte("2024-01-01T10:00:01Z a1\n2024-01-01T10:00:03Z a3\n"), 0644)
+	os.WriteFile(file2, []byte("2024-01-01T10:00:02Z b2\n2024-01-01T10:00:04Z b4\n"), 0644)
+
+	var out syncBuffer
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan bool, 1)
+	go func() {
+		done <- runMultiFileFollow(ctx, []string{file1, file2}, tail.TailerConfig{
+			Lines:           10,
+			PollInterval:    10 * time.Millisecond,
+			OutputDelimiter: '\n',
+			Stderr:          &bytes.Buffer{},
+		}, nil, &out, fileLabels{prefix: true, separator: ": "}, newMerger("", 500*time.Millisecond))
+	}()
+
+	want := file1 + ": 2024-01-01T10:00:01Z a1\n" +
+		file2 + ": 2024-01-01T10:00:02Z b2\n" +
+		file1 + ": 2024-01-01T10:00:03Z a3\n" +
+		file2 + ": 2024-01-01T10:00:04Z b4\n"
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.HasPrefix(string(out.Bytes()), want) {
+		if time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if failed := <-done; failed {
+		t.Error("runMultiFileFollow() reported a failure")
+	}
+	if got := string(out.Bytes()); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestCLI_MergeSortedNeedsFollow(t *testing.T) {
+	cmd := newTestCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--merge-sorted", os.DevNull, os.DevNull})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--merge-sorted needs -f") {
+		t.Errorf("Execute() error = %v, want --merge-sorted needs -f", err)
+	}
+}
+
+func TestCLI_MergeWindowOnlyCheckedWhenMerging(t *testing.T) {
+	t.Setenv("WAIL_MERGE_WINDOW", "0")
+
+	// A configured window of 0 doesn't matter to a run that doesn't merge
+	cmd := newTestCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{os.DevNull})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("Execute() error = %v", err)
+	}
+
+	cmd = newTestCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"-f", "--merge-sorted", os.DevNull, os.DevNull})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "invalid merge-window value") {
+		t.Errorf("Execute() error = %v, want invalid merge-window value", err)
+	}
+}
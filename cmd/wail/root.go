@@ -1,17 +1,28 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/jmurray2011/wail/internal/filesystem"
+	"github.com/jmurray2011/wail/internal/metrics"
 	"github.com/jmurray2011/wail/internal/tail"
+	"github.com/jmurray2011/wail/internal/trigger"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -23,58 +34,268 @@ var (
 	date    = "unknown"
 )
 
+// errFileFailed is returned by runTail when any input could not be read. The
+// per-file errors have already been printed, so it only sets the exit status.
+var errFileFailed = errors.New("one or more files could not be read")
+
+// newTailer creates the Tailer for each file. Tests replace it to see the
+// configs the flags produce.
+var newTailer = tail.NewTailer
+
 var rootCmd = &cobra.Command{
-	Use:     "wail [file...]",
-	Short:   "A Windows-native tail implementation",
-	Long:    `wail is a Windows-native tail implementation that handles
+	Use:   "wail [file...]",
+	Short: "A Windows-native tail implementation",
+	Long: `wail is a Windows-native tail implementation that handles
 file locking, CRLF line endings, and log rotation gracefully.`,
-	Version: version,
-	Args:    cobra.ArbitraryArgs,
-	RunE:    runTail,
+	Args:              cobra.ArbitraryArgs,
+	PersistentPreRunE: loadConfig,
+	RunE:              runTail,
 }
 
 func init() {
-	rootCmd.Flags().StringP("lines", "n", "10", "number of lines to output (use +N to start from line N)")
-	rootCmd.Flags().StringP("bytes", "c", "", "output the last NUM bytes (use +N to start from byte N)")
+	// Defined here rather than with cobra's Version so it can take =full
+	rootCmd.Flags().String("version", "", "print the version and exit; --version=full adds the commit, build date, Go version and platform")
+	rootCmd.Flags().Lookup("version").NoOptDefVal = "short"
+	rootCmd.RegisterFlagCompletionFunc("version", cobra.FixedCompletions([]string{"short", "full"}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.Flags().StringP("lines", "n", "10", "number of lines to output (use +N to start from line N, ~N for all but the last N)")
+	rootCmd.Flags().StringP("bytes", "c", "", "output the last NUM bytes (use +N to start from byte N, M:N for bytes M through N)")
+	rootCmd.Flags().Int64("start-offset", 0, "start output at exactly byte offset N (0-indexed), e.g. from a saved checkpoint")
+	rootCmd.Flags().Bool("clamp-offset", false, "with --start-offset, start at the end of a file shorter than the offset instead of failing")
+	rootCmd.Flags().Int("head-tail", 0, "output the first N and last N lines, with a marker for the lines omitted between")
 	rootCmd.Flags().StringP("follow", "f", "", "follow the file; optionally =name or =descriptor")
 	rootCmd.Flags().Lookup("follow").NoOptDefVal = "descriptor" // -f or --follow without value defaults to descriptor
+	rootCmd.RegisterFlagCompletionFunc("follow", cobra.FixedCompletions([]string{"name", "descriptor"}, cobra.ShellCompDirectiveNoFileComp))
 	rootCmd.Flags().BoolP("follow-name", "F", false, "like -f, but follow by name and retry")
-	rootCmd.Flags().Float64P("sleep-interval", "s", 0.1, "with -f, sleep for approximately N seconds between iterations")
-	rootCmd.Flags().Int("pid", 0, "with -f, terminate after process ID dies")
+	rootCmd.Flags().Bool("follow-stdin", false, "output the last lines of what standard input delivers in the first -s interval, then each line as it arrives, instead of waiting for the input to end")
+	rootCmd.Flags().StringP("sleep-interval", "s", "0.1", "with -f, sleep for approximately N seconds, or a duration such as 50ms, between iterations")
+	rootCmd.Flags().Bool("poll", false, "with -f, only poll for changes, without file system events (use on SMB/NFS shares)")
+	rootCmd.Flags().Float64("poll-jitter", 0, "with -f, vary each sleep interval randomly by up to this fraction (e.g. 0.1 for ±10%)")
+	rootCmd.Flags().String("pid", "", "with -f, terminate after process ID dies; a comma-separated list waits for all of them")
+	rootCmd.Flags().Bool("pid-any", false, "with several --pid values, terminate when any of them dies")
+	rootCmd.Flags().String("pid-poll-interval", "", "with --pid, check whether the process is alive every N seconds, or a duration such as 50ms (default: the -s interval)")
 	rootCmd.Flags().BoolP("quiet", "q", false, "never output headers giving file names")
 	rootCmd.Flags().BoolP("verbose", "v", false, "always output headers giving file names")
+	rootCmd.Flags().Bool("no-header-spacing", false, "don't print a blank line before each file name header")
 	rootCmd.Flags().Bool("retry", false, "keep trying to open a file if it is inaccessible")
+	rootCmd.Flags().Duration("retry-timeout", 0, "with --retry, give up if the file hasn't appeared within this duration (0 waits forever)")
+	rootCmd.Flags().Int("retry-attempts", 0, "with --retry, give up after this many failed attempts to open the file (0 tries forever)")
+	rootCmd.Flags().Int("max-files", 1024, "refuse to follow more than this many files at once (0 for no limit)")
 	rootCmd.Flags().BoolP("zero-terminated", "z", false, "line delimiter is NUL, not newline")
+	rootCmd.Flags().String("files-from", "", "also tail the paths listed in `FILE`, one per line (NUL-separated with -z), or read the list from stdin if FILE is -")
+	rootCmd.Flags().String("output-delimiter", "", "end each line of output with this character instead of the input delimiter (escapes \\n, \\t, \\r and \\0 are allowed)")
+	rootCmd.Flags().BoolP("binary", "b", false, "split lines on LF only, keeping CR bytes as content")
+	rootCmd.Flags().Bool("no-crlf-normalize", false, "keep CRLF line endings as they are in the file, rather than printing LF (same as -b)")
+	rootCmd.Flags().String("newline", "", "how lines end in output: lf (the default), crlf, or keep (as in the file, same as -b)")
+	rootCmd.RegisterFlagCompletionFunc("newline", cobra.FixedCompletions([]string{"lf", "crlf", "keep"}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.Flags().String("binary-files", "", "what to do with files containing NUL bytes: text (output them), skip, or warn (skip with a message); default warn on a terminal, text otherwise")
+	rootCmd.RegisterFlagCompletionFunc("binary-files", cobra.FixedCompletions([]string{"text", "skip", "warn"}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.Flags().Bool("text", false, "output files containing NUL bytes like any other (same as --binary-files=text)")
+	rootCmd.Flags().String("max-line-width", "", "cut lines longer than N characters, ending them with an ellipsis (auto: the terminal's width, or 80)")
+	rootCmd.Flags().Lookup("max-line-width").NoOptDefVal = "auto"
 	rootCmd.Flags().Int("max-unchanged-stats", 0, "with --follow=name, reopen after N iterations with no change")
+	rootCmd.Flags().Bool("reopen-on-max-unchanged", false, "with -f and --max-unchanged-stats, switch to a replacement file at the same name after N iterations with no change")
+	rootCmd.Flags().Bool("follow-symlink-target", false, "with -f, switch to the new target as soon as a symlink on the path, to the file or a directory, is repointed")
+	rootCmd.Flags().Bool("warn-rotation", false, "with -f, warn when the file is renamed away or replaced, while still following the file that is open")
+	rootCmd.Flags().Bool("keep-open", false, "with -F, hold each file open between reads instead of reopening it when it changes")
+	rootCmd.Flags().Duration("wait-for-content", 0, "when following, hold back new lines containing NUL bytes, as a file extended before it is written reads, for up to this long (5s if given without a value)")
+	rootCmd.Flags().Lookup("wait-for-content").NoOptDefVal = "5s"
+	rootCmd.Flags().Bool("reapply-window-on-rotation", false, "with -F, start a rotated-in file with the -n or -c window instead of from its first line")
+	rootCmd.Flags().String("drain-rotated", "", "with -F, on rotation find the old file among the files matching this `GLOB` (e.g. 'app.log.*') and output its last lines before moving on")
+	rootCmd.Flags().Duration("drain-timeout", time.Second, "with --drain-rotated, keep reading the rotated file while it grows for up to this long")
+	rootCmd.Flags().Bool("dry-run", false, "print the files that would be tailed and how, after expanding --files-from and skipping directories, then exit without reading them")
+	rootCmd.Flags().Bool("merge-sorted", false, "with -f and several files, output lines in the order of the timestamps they start with (see --time-layout), rather than as they arrive")
+	rootCmd.Flags().Duration("merge-window", 500*time.Millisecond, "with --merge-sorted, hold each line this long for lines from other files stamped earlier")
+	rootCmd.Flags().String("max-memory", "", "stream the last lines in two passes instead of holding them when they take more than `SIZE` bytes (e.g. 64M)")
+	rootCmd.Flags().Int("reopen-after-errors", 5, "with -f, reopen the file by name after N consecutive read errors (0 disables)")
+	rootCmd.Flags().Bool("totals", false, "with multiple files, print a footer with total lines and bytes output")
+	rootCmd.Flags().Bool("count", false, "print how many lines (or, with -c, bytes) would be output instead of the lines; with -f, when following stops")
+	rootCmd.Flags().String("max-rate", "", "with -f, limit live output to N lines/sec, or to SIZE bytes/sec with a size suffix (e.g. 64K)")
+	rootCmd.Flags().String("on-overflow", "delay", "with --max-rate, delay output or drop excess lines")
+	rootCmd.RegisterFlagCompletionFunc("on-overflow", cobra.FixedCompletions([]string{"delay", "drop"}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.Flags().String("lines-output-buffer", "batch", "when to write output: line (every line at once), batch (each poll's lines together) or a duration such as 200ms (on a timer)")
+	rootCmd.Flags().Bool("line-buffered", false, "write and flush every line as soon as it is read, through --gzip-output and to --output-file too, for interactive pipelines")
+	rootCmd.RegisterFlagCompletionFunc("lines-output-buffer", cobra.FixedCompletions([]string{"line", "batch"}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.Flags().Bool("uniq", false, "suppress consecutive duplicate lines")
+	rootCmd.Flags().Bool("uniq-count", false, "like --uniq, and report how many times a suppressed line repeated")
+	rootCmd.Flags().String("grep", "", "output only lines matching this regular expression")
+	rootCmd.Flags().BoolP("ignore-case", "i", false, "match --grep without regard to case")
+	rootCmd.Flags().String("since", "", "output only lines whose leading timestamp is at or after this time (RFC 3339, a date, or a duration ago such as 90m)")
+	rootCmd.Flags().String("until-time", "", "output only lines whose leading timestamp is at or before this time; when following, stop at the first new line past it")
+	rootCmd.Flags().String("time-layout", "", "Go time layout of the timestamp starting each line, for --since, --until-time and --merge-sorted (default RFC 3339)")
+	rootCmd.Flags().String("on-unparseable", "emit", "with --since or --until-time, what to do with lines not starting with a timestamp: emit or drop")
+	rootCmd.RegisterFlagCompletionFunc("on-unparseable", cobra.FixedCompletions([]string{"emit", "drop"}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.Flags().IntP("before", "B", 0, "with --grep, also output N lines before each match")
+	rootCmd.Flags().IntP("after", "A", 0, "with --grep, also output N lines after each match")
+	rootCmd.Flags().IntP("context", "C", 0, "with --grep, also output N lines before and after each match")
+	rootCmd.Flags().String("exec", "", "with -f, run this command for each new line (matching --grep), replacing {line}, {file} and {time}")
+	rootCmd.Flags().String("webhook", "", "with -f, POST each new line (matching --grep) to this URL as JSON")
+	rootCmd.Flags().Bool("debug", false, "trace file opens, reads, truncation and rotation to stderr")
+	rootCmd.Flags().StringSlice("ssh-key", nil, "for sftp:// paths, a private key file to authenticate with, after any in ssh-agent (repeatable; default ~/.ssh/id_*)")
+	rootCmd.Flags().String("ssh-known-hosts", "", "for sftp:// paths, the known hosts file to check servers against (default ~/.ssh/known_hosts)")
+	rootCmd.Flags().String("metrics-addr", "", "serve per-file counters in Prometheus text format at http://ADDR/metrics (off by default)")
+	rootCmd.Flags().Bool("ignore-directories", false, "skip directory arguments without reporting them")
+	rootCmd.Flags().Bool("quiet-if-empty", false, "with -v, still omit the header for a file that produces no output")
+	rootCmd.Flags().String("color", "auto", "color file names in headers and prefixes: auto, always or never (auto honors NO_COLOR)")
+	rootCmd.Flags().Lookup("color").NoOptDefVal = "always"
+	rootCmd.RegisterFlagCompletionFunc("color", cobra.FixedCompletions([]string{"auto", "always", "never"}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.Flags().Bool("with-filename", false, "prefix every line with its file name instead of printing headers")
+	rootCmd.Flags().Bool("header-realpath", false, "name files in headers and prefixes by their absolute path, with symlinks resolved")
+	rootCmd.Flags().Bool("headers-to-stderr", false, "print file name headers to stderr, so stdout carries only the files' content")
+	rootCmd.Flags().BoolP("null-headers", "0", false, "print each file name header as the bare name ending in a NUL byte, for xargs -0, instead of \"==> name <==\"")
+	rootCmd.Flags().String("filename-separator", ": ", "with --with-filename, text between the file name and the line")
+	rootCmd.Flags().String("mark-live", "", "with -f, print a separator line between the initial lines and live output")
+	rootCmd.Flags().Lookup("mark-live").NoOptDefVal = "--- following ---"
+	rootCmd.Flags().Bool("gzip-output", false, "compress all output, headers included, with gzip")
+	rootCmd.Flags().String("output-encoding", "", "write output in this charset instead of as read, e.g. windows-1252 or utf-16le (not with -c)")
+	rootCmd.RegisterFlagCompletionFunc("output-encoding", cobra.FixedCompletions(outputEncodingNames, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.Flags().String("output-file", "", "also write output to `PATH`, as tee does")
+	rootCmd.Flags().Bool("append", false, "with --output-file, append to the file instead of overwriting it")
+	rootCmd.Flags().Bool("interactive", false, "page through a single file on the terminal, like less (G follows it)")
 
 	viper.BindPFlag("lines", rootCmd.Flags().Lookup("lines"))
 	viper.BindPFlag("bytes", rootCmd.Flags().Lookup("bytes"))
+	viper.BindPFlag("start-offset", rootCmd.Flags().Lookup("start-offset"))
+	viper.BindPFlag("clamp-offset", rootCmd.Flags().Lookup("clamp-offset"))
+	viper.BindPFlag("head-tail", rootCmd.Flags().Lookup("head-tail"))
 	viper.BindPFlag("follow", rootCmd.Flags().Lookup("follow"))
 	viper.BindPFlag("follow-name", rootCmd.Flags().Lookup("follow-name"))
+	viper.BindPFlag("follow-stdin", rootCmd.Flags().Lookup("follow-stdin"))
 	viper.BindPFlag("sleep-interval", rootCmd.Flags().Lookup("sleep-interval"))
+	viper.BindPFlag("poll", rootCmd.Flags().Lookup("poll"))
+	viper.BindPFlag("poll-jitter", rootCmd.Flags().Lookup("poll-jitter"))
 	viper.BindPFlag("pid", rootCmd.Flags().Lookup("pid"))
+	viper.BindPFlag("pid-any", rootCmd.Flags().Lookup("pid-any"))
+	viper.BindPFlag("pid-poll-interval", rootCmd.Flags().Lookup("pid-poll-interval"))
 	viper.BindPFlag("quiet", rootCmd.Flags().Lookup("quiet"))
 	viper.BindPFlag("verbose", rootCmd.Flags().Lookup("verbose"))
+	viper.BindPFlag("no-header-spacing", rootCmd.Flags().Lookup("no-header-spacing"))
 	viper.BindPFlag("retry", rootCmd.Flags().Lookup("retry"))
+	viper.BindPFlag("retry-timeout", rootCmd.Flags().Lookup("retry-timeout"))
+	viper.BindPFlag("retry-attempts", rootCmd.Flags().Lookup("retry-attempts"))
+	viper.BindPFlag("max-files", rootCmd.Flags().Lookup("max-files"))
 	viper.BindPFlag("zero-terminated", rootCmd.Flags().Lookup("zero-terminated"))
+	viper.BindPFlag("files-from", rootCmd.Flags().Lookup("files-from"))
+	viper.BindPFlag("output-delimiter", rootCmd.Flags().Lookup("output-delimiter"))
+	viper.BindPFlag("binary", rootCmd.Flags().Lookup("binary"))
+	viper.BindPFlag("no-crlf-normalize", rootCmd.Flags().Lookup("no-crlf-normalize"))
+	viper.BindPFlag("newline", rootCmd.Flags().Lookup("newline"))
+	viper.BindPFlag("binary-files", rootCmd.Flags().Lookup("binary-files"))
+	viper.BindPFlag("text", rootCmd.Flags().Lookup("text"))
+	viper.BindPFlag("max-line-width", rootCmd.Flags().Lookup("max-line-width"))
 	viper.BindPFlag("max-unchanged-stats", rootCmd.Flags().Lookup("max-unchanged-stats"))
+	viper.BindPFlag("reopen-on-max-unchanged", rootCmd.Flags().Lookup("reopen-on-max-unchanged"))
+	viper.BindPFlag("follow-symlink-target", rootCmd.Flags().Lookup("follow-symlink-target"))
+	viper.BindPFlag("warn-rotation", rootCmd.Flags().Lookup("warn-rotation"))
+	viper.BindPFlag("keep-open", rootCmd.Flags().Lookup("keep-open"))
+	viper.BindPFlag("wait-for-content", rootCmd.Flags().Lookup("wait-for-content"))
+	viper.BindPFlag("reapply-window-on-rotation", rootCmd.Flags().Lookup("reapply-window-on-rotation"))
+	viper.BindPFlag("drain-rotated", rootCmd.Flags().Lookup("drain-rotated"))
+	viper.BindPFlag("drain-timeout", rootCmd.Flags().Lookup("drain-timeout"))
+	viper.BindPFlag("max-memory", rootCmd.Flags().Lookup("max-memory"))
+	viper.BindPFlag("dry-run", rootCmd.Flags().Lookup("dry-run"))
+	viper.BindPFlag("merge-sorted", rootCmd.Flags().Lookup("merge-sorted"))
+	viper.BindPFlag("merge-window", rootCmd.Flags().Lookup("merge-window"))
+	viper.BindPFlag("reopen-after-errors", rootCmd.Flags().Lookup("reopen-after-errors"))
+	viper.BindPFlag("totals", rootCmd.Flags().Lookup("totals"))
+	viper.BindPFlag("count", rootCmd.Flags().Lookup("count"))
+	viper.BindPFlag("max-rate", rootCmd.Flags().Lookup("max-rate"))
+	viper.BindPFlag("on-overflow", rootCmd.Flags().Lookup("on-overflow"))
+	viper.BindPFlag("lines-output-buffer", rootCmd.Flags().Lookup("lines-output-buffer"))
+	viper.BindPFlag("line-buffered", rootCmd.Flags().Lookup("line-buffered"))
+	viper.BindPFlag("uniq", rootCmd.Flags().Lookup("uniq"))
+	viper.BindPFlag("uniq-count", rootCmd.Flags().Lookup("uniq-count"))
+	viper.BindPFlag("grep", rootCmd.Flags().Lookup("grep"))
+	viper.BindPFlag("ignore-case", rootCmd.Flags().Lookup("ignore-case"))
+	viper.BindPFlag("since", rootCmd.Flags().Lookup("since"))
+	viper.BindPFlag("until-time", rootCmd.Flags().Lookup("until-time"))
+	viper.BindPFlag("time-layout", rootCmd.Flags().Lookup("time-layout"))
+	viper.BindPFlag("on-unparseable", rootCmd.Flags().Lookup("on-unparseable"))
+	viper.BindPFlag("before", rootCmd.Flags().Lookup("before"))
+	viper.BindPFlag("after", rootCmd.Flags().Lookup("after"))
+	viper.BindPFlag("context", rootCmd.Flags().Lookup("context"))
+	viper.BindPFlag("exec", rootCmd.Flags().Lookup("exec"))
+	viper.BindPFlag("webhook", rootCmd.Flags().Lookup("webhook"))
+	viper.BindPFlag("debug", rootCmd.Flags().Lookup("debug"))
+	viper.BindPFlag("ssh-key", rootCmd.Flags().Lookup("ssh-key"))
+	viper.BindPFlag("ssh-known-hosts", rootCmd.Flags().Lookup("ssh-known-hosts"))
+	viper.BindPFlag("metrics-addr", rootCmd.Flags().Lookup("metrics-addr"))
+	viper.BindPFlag("gzip-output", rootCmd.Flags().Lookup("gzip-output"))
+	viper.BindPFlag("output-encoding", rootCmd.Flags().Lookup("output-encoding"))
+	viper.BindPFlag("output-file", rootCmd.Flags().Lookup("output-file"))
+	viper.BindPFlag("append", rootCmd.Flags().Lookup("append"))
+	viper.BindPFlag("interactive", rootCmd.Flags().Lookup("interactive"))
+	viper.BindPFlag("ignore-directories", rootCmd.Flags().Lookup("ignore-directories"))
+	viper.BindPFlag("quiet-if-empty", rootCmd.Flags().Lookup("quiet-if-empty"))
+	viper.BindPFlag("color", rootCmd.Flags().Lookup("color"))
+	viper.BindPFlag("with-filename", rootCmd.Flags().Lookup("with-filename"))
+	viper.BindPFlag("header-realpath", rootCmd.Flags().Lookup("header-realpath"))
+	viper.BindPFlag("headers-to-stderr", rootCmd.Flags().Lookup("headers-to-stderr"))
+	viper.BindPFlag("null-headers", rootCmd.Flags().Lookup("null-headers"))
+	viper.BindPFlag("filename-separator", rootCmd.Flags().Lookup("filename-separator"))
+	viper.BindPFlag("mark-live", rootCmd.Flags().Lookup("mark-live"))
 }
 
 func Execute() error {
 	return rootCmd.Execute()
 }
 
-// parseNumArg parses a number argument that may have a + prefix and/or suffix.
+// loadConfig layers defaults from WAIL_* environment variables and config files
+// underneath the command-line flags. Precedence is flags > env > config file >
+// built-in defaults. Config files are optional and hold global defaults only:
+// ~/.config/wail/config.yaml is read first, then ./.wail.yaml overrides it.
+func loadConfig(cmd *cobra.Command, args []string) error {
+	if cmd.Flags().Changed("version") {
+		return nil // A broken config file shouldn't stop --version
+	}
+	viper.SetEnvPrefix("WAIL")
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_")) // sleep-interval -> WAIL_SLEEP_INTERVAL
+	viper.AutomaticEnv()
+
+	var paths []string
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".config", "wail", "config.yaml"))
+	}
+	paths = append(paths, ".wail.yaml")
+
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			continue // Absent config files are not an error
+		}
+		viper.SetConfigFile(path)
+		if err := viper.MergeInConfig(); err != nil {
+			return fmt.Errorf("reading config %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// numAnchor describes which end of the input a parsed count is measured from.
+type numAnchor int
+
+const (
+	anchorEnd        numAnchor = iota // N or -N: the last N
+	anchorStart                       // +N: starting at N
+	anchorAllButLast                  // ~N: everything except the last N
+)
+
+// parseNumArg parses a number argument that may have a +, - or ~ prefix and/or suffix.
 // Supports suffixes: b (512), K (1024), KB (1000), M, MB, G, GB, etc.
-// Returns the absolute value and whether it starts from beginning.
-func parseNumArg(s string) (int64, bool, error) {
+// Returns the absolute value and which end of the input it is anchored to.
+// As in GNU tail, blanks around the number are allowed, and a prefix or
+// suffix with no digits ("+", "-", "K") is not. The empty string is 0.
+func parseNumArg(s string) (int64, numAnchor, error) {
 	if s == "" {
-		return 0, false, nil
+		return 0, anchorEnd, nil
 	}
+	arg := s
+	s = strings.TrimSpace(s)
 
-	fromStart := false
+	anchor := anchorEnd
 	if strings.HasPrefix(s, "+") {
-		fromStart = true
+		anchor = anchorStart
+		s = s[1:]
+	} else if strings.HasPrefix(s, "~") {
+		anchor = anchorAllButLast
 		s = s[1:]
 	} else if strings.HasPrefix(s, "-") {
 		s = s[1:]
@@ -106,18 +327,157 @@ func parseNumArg(s string) (int64, bool, error) {
 		}
 	}
 
+	// Only digits are left in a valid number. None at all, as in "+" or
+	// "K", is said plainly rather than left to ParseInt, which would also
+	// take another sign, as in "--5" or "+-5"
+	if s == "" {
+		return 0, anchorEnd, fmt.Errorf("missing number in %q", arg)
+	}
+	if strings.ContainsAny(s[:1], "+-") {
+		return 0, anchorEnd, fmt.Errorf("invalid number: %q", arg)
+	}
 	n, err := strconv.ParseInt(s, 10, 64)
+	if errors.Is(err, strconv.ErrRange) || n > math.MaxInt64/multiplier {
+		return 0, anchorEnd, fmt.Errorf("number too large: %q", arg)
+	}
 	if err != nil {
-		return 0, false, fmt.Errorf("invalid number: %s", s)
+		return 0, anchorEnd, fmt.Errorf("invalid number: %q", arg)
 	}
 
-	return n * multiplier, fromStart, nil
+	return n * multiplier, anchor, nil
+}
+
+// humanBytes formats n bytes for people: in bytes below 1 KiB, and otherwise
+// to one decimal place in the largest binary unit it reaches, matching the
+// K, M and G suffixes -c takes.
+func humanBytes(n int64) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d bytes", n)
+	}
+	units := []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	size := float64(n) / 1024
+	unit := 0
+	for size >= 1024 && unit < len(units)-1 {
+		size /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%.1f %s", size, units[unit])
+}
+
+// parseByteRange parses a -c M:N range, bytes M through N counted from 1, into
+// M and N. Either may have a size suffix, as for -c N, but not a sign.
+func parseByteRange(s string) (start, end int64, err error) {
+	first, last, _ := strings.Cut(s, ":")
+	for _, part := range []string{first, last} {
+		if part = strings.TrimSpace(part); part == "" || strings.ContainsAny(part[:1], "+-~") {
+			return 0, 0, fmt.Errorf("invalid byte range %q (use M:N)", s)
+		}
+	}
+	if start, _, err = parseNumArg(first); err != nil {
+		return 0, 0, err
+	}
+	if end, _, err = parseNumArg(last); err != nil {
+		return 0, 0, err
+	}
+	if start > end {
+		return 0, 0, fmt.Errorf("byte range %q starts after it ends", s)
+	}
+	return start, end, nil
+}
+
+// parseRate parses a --max-rate value: a plain number is lines per second,
+// and a size with a suffix (as accepted by -c) is bytes per second. A trailing
+// "/s" is allowed. An empty string means no limit.
+func parseRate(s string) (rate float64, bytes bool, err error) {
+	s = strings.TrimSuffix(s, "/s")
+	if s == "" {
+		return 0, false, nil
+	}
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		if n <= 0 {
+			return 0, false, fmt.Errorf("rate must be positive: %s", s)
+		}
+		return n, false, nil
+	}
+	if strings.ContainsAny(s[:1], "+-~") {
+		return 0, false, fmt.Errorf("invalid rate: %s", s)
+	}
+	n, _, err := parseNumArg(s)
+	if err != nil {
+		return 0, false, err
+	}
+	if n <= 0 {
+		return 0, false, fmt.Errorf("rate must be positive: %s", s)
+	}
+	return float64(n), true, nil
+}
+
+// minInterval is the shortest -s interval accepted; anything shorter would
+// have following spin rather than poll.
+const minInterval = time.Millisecond
+
+// parseInterval parses a -s value: a Go duration such as "50ms" or "2.5ms",
+// or, as before durations were accepted, a number of seconds such as "0.05".
+func parseInterval(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		secs, ferr := strconv.ParseFloat(s, 64)
+		if ferr != nil || math.IsNaN(secs) || math.IsInf(secs, 0) || secs > math.MaxInt64/float64(time.Second) {
+			return 0, fmt.Errorf("%q is neither a number of seconds nor a duration", s)
+		}
+		d = time.Duration(secs * float64(time.Second))
+	}
+	if d < minInterval {
+		return 0, fmt.Errorf("%s is shorter than the minimum of %v", s, minInterval)
+	}
+	return d, nil
+}
+
+// parsePIDList parses a comma-separated list of process IDs for --pid.
+// An empty string yields no PIDs.
+func parsePIDList(s string) ([]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var pids []int
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		pid, err := strconv.Atoi(field)
+		if err != nil || pid <= 0 {
+			return nil, fmt.Errorf("invalid process ID: %q", field)
+		}
+		pids = append(pids, pid)
+	}
+	return pids, nil
 }
 
 func runTail(cmd *cobra.Command, args []string) error {
+	if cmd.Flags().Changed("version") {
+		mode, _ := cmd.Flags().GetString("version")
+		return printVersion(cmd.OutOrStdout(), mode)
+	}
+
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
+	restoreConsole := setupConsole(cmd.OutOrStdout())
+	defer restoreConsole()
+
+	// Paths listed in --files-from are tailed after those on the command line
+	filesFrom := viper.GetString("files-from")
+	if filesFrom != "" {
+		listed, err := readFilesFrom(filesFrom, os.Stdin, viper.GetBool("zero-terminated"))
+		if err != nil {
+			return err
+		}
+		if len(args) == 0 && len(listed) == 0 {
+			return fmt.Errorf("no files listed in %s", filesFrom)
+		}
+		args = append(args, listed...)
+	}
+
 	// If no files specified, check if stdin is piped
 	if len(args) == 0 {
 		stat, err := os.Stdin.Stat()
@@ -134,24 +494,100 @@ func runTail(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Any path may be given its own count, as app.log:n=20, in place of -n or -c
+	args, specs, err := splitFileSpecs(args)
+	if err != nil {
+		return err
+	}
+
+	// /dev/stdin is standard input by another name, and gets the same
+	// handling (and header) as "-", including on Windows where it doesn't exist
+	for i, path := range args {
+		if path == "/dev/stdin" {
+			args[i] = "-"
+			if spec, ok := specs[path]; ok {
+				specs["-"] = spec
+			}
+		}
+	}
+	if filesFrom == "-" && slices.Contains(args, "-") {
+		return fmt.Errorf("standard input can't be both the --files-from list and a file to tail")
+	}
+
+	// A count on the command line overrides a default of the other kind from
+	// config or env, so -n beats a configured bytes value and -c a lines one
+	linesFlag, bytesFlag := cmd.Flags().Changed("lines"), cmd.Flags().Changed("bytes")
+
 	// Parse lines argument (supports +N syntax)
 	linesStr := viper.GetString("lines")
-	lines, linesFromStart, err := parseNumArg(linesStr)
+	if bytesFlag && !linesFlag {
+		linesStr = cmd.Flags().Lookup("lines").DefValue
+	}
+	lines, linesAnchor, err := parseNumArg(linesStr)
 	if err != nil {
 		return fmt.Errorf("invalid lines value: %w", err)
 	}
 
-	// Parse bytes argument (supports +N syntax)
+	// Parse bytes argument (supports +N syntax, and M:N for a range)
 	bytesStr := viper.GetString("bytes")
-	bytes, bytesFromStart, err := parseNumArg(bytesStr)
+	if linesFlag && !bytesFlag {
+		bytesStr = ""
+	}
+	var bytes, bytesThrough int64
+	var bytesAnchor numAnchor
+	if strings.Contains(bytesStr, ":") {
+		bytes, bytesThrough, err = parseByteRange(bytesStr)
+		bytesAnchor = anchorStart
+	} else {
+		bytes, bytesAnchor, err = parseNumArg(bytesStr)
+	}
 	if err != nil {
 		return fmt.Errorf("invalid bytes value: %w", err)
 	}
+	if bytesAnchor == anchorAllButLast {
+		return fmt.Errorf("invalid bytes value: ~N is only supported with -n")
+	}
+	// -c 0 selects byte mode like any other count, and outputs nothing
+	byteMode := bytesStr != ""
+
+	// Like GNU tail, refuse to guess which of -n and -c was meant. Only the
+	// command line counts: a default from config or env is overridden
+	if linesFlag && bytesFlag {
+		return fmt.Errorf("cannot combine -n and -c")
+	}
+
+	// A start offset is an exact position, replacing what -n or -c would pick
+	startOffsetSet := cmd.Flags().Changed("start-offset")
+	startOffset := viper.GetInt64("start-offset")
+	if startOffsetSet {
+		if linesFlag || bytesFlag {
+			return fmt.Errorf("cannot combine --start-offset with -n or -c")
+		}
+		if startOffset < 0 {
+			return fmt.Errorf("invalid start-offset value: %d", startOffset)
+		}
+	}
+
+	// Head and tail together is its own selection, like --start-offset
+	headTail := viper.GetInt("head-tail")
+	if cmd.Flags().Changed("head-tail") {
+		if linesFlag || bytesFlag || startOffsetSet {
+			return fmt.Errorf("cannot combine --head-tail with -n, -c or --start-offset")
+		}
+		if headTail <= 0 {
+			return fmt.Errorf("invalid head-tail value: %d", headTail)
+		}
+	}
+
+	// Lines was given when -n or a configured lines value chose it, rather
+	// than the default, and no -c, --start-offset or --head-tail replaced it
+	linesSet := (linesFlag || viper.IsSet("lines")) && !byteMode && !startOffsetSet && headTail == 0
 
 	// Determine fromStart based on which mode we're in
-	fromStart := linesFromStart
-	if bytes > 0 {
-		fromStart = bytesFromStart
+	fromStart := linesAnchor == anchorStart
+	allButLast := linesAnchor == anchorAllButLast && !byteMode
+	if byteMode {
+		fromStart = bytesAnchor == anchorStart
 	}
 
 	// Parse --follow flag: can be empty, "descriptor", or "name"
@@ -174,90 +610,894 @@ func runTail(cmd *cobra.Command, args []string) error {
 		followName = true
 		follow = true
 	}
-	sleepInterval := time.Duration(viper.GetFloat64("sleep-interval") * float64(time.Second))
-	pid := viper.GetInt("pid")
+	if bytesThrough > 0 && follow {
+		return fmt.Errorf("cannot follow a byte range (-c M:N)")
+	}
+	sleepInterval, err := parseInterval(viper.GetString("sleep-interval"))
+	if err != nil {
+		return fmt.Errorf("invalid sleep-interval value: %w", err)
+	}
+
+	// Interactive mode is a pager, separate from tailing; only -s applies
+	if viper.GetBool("interactive") {
+		return runInteractive(ctx, cmd, args, sleepInterval)
+	}
+
+	pollJitter := viper.GetFloat64("poll-jitter")
+	if pollJitter < 0 || pollJitter >= 1 {
+		return fmt.Errorf("invalid poll-jitter value: %v (use a fraction from 0 up to 1)", pollJitter)
+	}
+	pids, err := parsePIDList(viper.GetString("pid"))
+	if err != nil {
+		return fmt.Errorf("invalid pid value: %w", err)
+	}
+	pidAny := viper.GetBool("pid-any")
+	var pidPollInterval time.Duration
+	if s := viper.GetString("pid-poll-interval"); s != "" {
+		if pidPollInterval, err = parseInterval(s); err != nil {
+			return fmt.Errorf("invalid pid-poll-interval value: %w", err)
+		}
+	}
 	quiet := viper.GetBool("quiet")
 	verbose := viper.GetBool("verbose")
 	retry := viper.GetBool("retry")
+	retryTimeout := viper.GetDuration("retry-timeout")
+	retryAttempts := viper.GetInt("retry-attempts")
+	if retryAttempts < 0 {
+		return fmt.Errorf("invalid retry-attempts value: %d", retryAttempts)
+	}
 	zeroTerminated := viper.GetBool("zero-terminated")
+
+	// Output lines end with the input delimiter unless told otherwise, e.g.
+	// -z input printed one record per line for reading
+	outputDelim := lineDelim(zeroTerminated)
+	outputDelimSet := cmd.Flags().Changed("output-delimiter")
+	if outputDelimSet {
+		if outputDelim, err = parseDelimiter(viper.GetString("output-delimiter")); err != nil {
+			return err
+		}
+	}
+
+	// By default a CR before each LF is dropped, so CRLF files print like
+	// any other; -b and --no-crlf-normalize both keep the bytes as they are
+	lineEnding, err := parseNewline(viper.GetString("newline"), viper.GetBool("binary") || viper.GetBool("no-crlf-normalize"))
+	if err != nil {
+		return err
+	}
 	maxUnchangedStats := viper.GetInt("max-unchanged-stats")
+	reopenOnMaxUnchanged := viper.GetBool("reopen-on-max-unchanged")
+	reopenAfterErrors := viper.GetInt("reopen-after-errors")
+	totals := viper.GetBool("totals")
+	ignoreDirs := viper.GetBool("ignore-directories")
+	quietIfEmpty := viper.GetBool("quiet-if-empty")
+	withFilename := viper.GetBool("with-filename")
+	filenameSep := viper.GetString("filename-separator")
+	liveMarker := viper.GetString("mark-live")
+	maxRate, rateBytes, err := parseRate(viper.GetString("max-rate"))
+	if err != nil {
+		return fmt.Errorf("invalid max-rate value: %w", err)
+	}
+	var dropOverflow bool
+	switch overflow := viper.GetString("on-overflow"); overflow {
+	case "delay":
+	case "drop":
+		dropOverflow = true
+	default:
+		return fmt.Errorf("invalid on-overflow mode: %s (use 'delay' or 'drop')", overflow)
+	}
+	flushMode, flushEvery, err := parseOutputBuffer(viper.GetString("lines-output-buffer"))
+	if err != nil {
+		return err
+	}
+	// --line-buffered is line output with nothing downstream holding it back
+	lineBuffered := viper.GetBool("line-buffered")
+	if lineBuffered {
+		if cmd.Flags().Changed("lines-output-buffer") && flushMode != tail.FlushLine {
+			return fmt.Errorf("cannot combine --line-buffered with --lines-output-buffer %s", viper.GetString("lines-output-buffer"))
+		}
+		flushMode = tail.FlushLine
+	}
+	outputEncoding, err := parseOutputEncoding(viper.GetString("output-encoding"))
+	if err != nil {
+		return err
+	}
+	sftpConfig := filesystem.SFTPConfig{
+		KeyFiles:       viper.GetStringSlice("ssh-key"),
+		KnownHostsFile: viper.GetString("ssh-known-hosts"),
+	}
 	output := cmd.OutOrStdout()
 	multiFile := len(args) > 1
 
+	// Following keeps every file open at once, so a glob matching thousands
+	// is refused up front rather than failing partway with EMFILE
+	if follow && multiFile {
+		if err := checkFileCount(len(args), viper.GetInt("max-files"), cmd.ErrOrStderr()); err != nil {
+			return err
+		}
+	}
+
+	// Everything bound for output goes through gzip, which also buffers it.
+	// Close writes the footer, so it is deferred to run on every return,
+	// including after Ctrl-C cancels ctx. While following, the stream is
+	// flushed each poll so live lines can be read before wail exits.
+	dryRun := viper.GetBool("dry-run")
+	if viper.GetBool("gzip-output") && !dryRun {
+		gz := newGzipWriter(output)
+		defer gz.Close()
+		if follow {
+			go gz.flushEvery(ctx, sleepInterval)
+		}
+		output = gz
+		if lineBuffered {
+			output = &flushingWriter{w: gz, flush: gz.Flush}
+		}
+	}
+
+	// NO_COLOR counts when set at all, even to an empty value
+	_, noColor := os.LookupEnv("NO_COLOR")
+	color, err := resolveColor(viper.GetString("color"), noColor, isTerminal(output))
+	if err != nil {
+		return err
+	}
+	if color {
+		restoreColor := enableColor(output)
+		defer restoreColor()
+	}
+
+	// Like grep, binary files are held back only from a terminal by default
+	binaryFiles, err := parseBinaryFiles(viper.GetString("binary-files"), viper.GetBool("text"), isTerminal(output))
+	if err != nil {
+		return err
+	}
+
+	var lineWidth func() int
+	if value := viper.GetString("max-line-width"); value != "" {
+		if lineWidth, err = maxLineWidth(ctx, value, output); err != nil {
+			return err
+		}
+	}
+
 	// -F is equivalent to --follow=name --retry
 	if followName {
 		follow = true
 		retry = true
 	}
 
+	// All-but-last-N needs the end of the input, which never arrives when following
+	if allButLast && follow {
+		return fmt.Errorf("cannot follow with -n ~N (all but the last N lines)")
+	}
+	if headTail > 0 && follow {
+		return fmt.Errorf("cannot follow with --head-tail")
+	}
+
+	// Only following by name notices the file being renamed away
+	drainRotated := viper.GetString("drain-rotated")
+	if drainRotated != "" {
+		if !followName {
+			return fmt.Errorf("--drain-rotated needs -F")
+		}
+		if _, err := filepath.Match(drainRotated, ""); err != nil {
+			return fmt.Errorf("invalid drain-rotated pattern: %w", err)
+		}
+	}
+	// -F already moves to the new file; there is nothing to warn about
+	warnRotation := viper.GetBool("warn-rotation")
+	if warnRotation && (!follow || followName) {
+		return fmt.Errorf("--warn-rotation needs -f (-F follows the new file instead)")
+	}
+	if viper.GetDuration("drain-timeout") < 0 {
+		return fmt.Errorf("invalid drain-timeout value: %v", viper.GetDuration("drain-timeout"))
+	}
+	var maxMemory int64
+	if s := strings.TrimSpace(viper.GetString("max-memory")); s != "" {
+		if strings.ContainsAny(s[:1], "+-~") {
+			return fmt.Errorf("invalid max-memory value: %s", s)
+		}
+		if maxMemory, _, err = parseNumArg(s); err != nil {
+			return fmt.Errorf("invalid max-memory value: %w", err)
+		}
+	}
+	// Only lines arriving from several files at once need putting in order
+	mergeSorted := viper.GetBool("merge-sorted")
+	mergeWindow := viper.GetDuration("merge-window")
+	if mergeSorted {
+		if !follow {
+			return fmt.Errorf("--merge-sorted needs -f or -F")
+		}
+		if byteMode {
+			return fmt.Errorf("cannot combine --merge-sorted with -c")
+		}
+		if mergeWindow <= 0 {
+			return fmt.Errorf("invalid merge-window value: %v", mergeWindow)
+		}
+	}
+	followStdin := viper.GetBool("follow-stdin")
+	if followStdin && (byteMode || allButLast || headTail > 0) {
+		return fmt.Errorf("cannot combine --follow-stdin with -c, -n ~N or --head-tail")
+	}
+
+	// Byte-mode output isn't split into lines, so there is nothing to match
+	var filter *regexp.Regexp
+	if pattern := viper.GetString("grep"); pattern != "" {
+		if byteMode {
+			return fmt.Errorf("cannot combine --grep with -c")
+		}
+		if viper.GetBool("ignore-case") {
+			pattern = "(?i)" + pattern
+		}
+		if filter, err = regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid grep pattern: %w", err)
+		}
+	}
+
+	// Timestamps are read from the start of lines, which byte mode doesn't have
+	timeLayout := viper.GetString("time-layout")
+	since, err := parseTimeBound("since", viper.GetString("since"), timeLayout, time.Now())
+	if err != nil {
+		return err
+	}
+	until, err := parseTimeBound("until-time", viper.GetString("until-time"), timeLayout, time.Now())
+	if err != nil {
+		return err
+	}
+	if (!since.IsZero() || !until.IsZero()) && byteMode {
+		return fmt.Errorf("cannot combine --since or --until-time with -c")
+	}
+	var dropUnparseable bool
+	switch viper.GetString("on-unparseable") {
+	case "emit":
+	case "drop":
+		dropUnparseable = true
+	default:
+		return fmt.Errorf("invalid on-unparseable value: %s (use 'emit' or 'drop')", viper.GetString("on-unparseable"))
+	}
+
+	// -C sets both sides of the context; -B and -A override it, as in grep
+	contextBefore, contextAfter := viper.GetInt("context"), viper.GetInt("context")
+	if cmd.Flags().Changed("before") {
+		contextBefore = viper.GetInt("before")
+	}
+	if cmd.Flags().Changed("after") {
+		contextAfter = viper.GetInt("after")
+	}
+	if contextBefore < 0 || contextAfter < 0 {
+		return fmt.Errorf("invalid context value: lines of context can't be negative")
+	}
+	if (contextBefore > 0 || contextAfter > 0) && filter == nil {
+		return fmt.Errorf("--before, --after and --context need --grep")
+	}
+
+	// --count reports how many lines would be output, so nothing that isn't
+	// one of the input's lines may be added to them
+	countOnly := viper.GetBool("count")
+	if countOnly {
+		if headTail > 0 || contextBefore > 0 || contextAfter > 0 || viper.GetBool("uniq-count") {
+			return fmt.Errorf("cannot combine --count with --head-tail, --uniq-count or context lines")
+		}
+		liveMarker = ""
+	}
+
+	// Triggers act on live lines only, so a restart doesn't replay old alerts
+	var actions []trigger.Action
+	if command := viper.GetString("exec"); command != "" {
+		action, err := trigger.NewExec(command)
+		if err != nil {
+			return fmt.Errorf("invalid exec command: %w", err)
+		}
+		actions = append(actions, action)
+	}
+	if webhook := viper.GetString("webhook"); webhook != "" {
+		if u, err := url.Parse(webhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook URL: %s", webhook)
+		}
+		actions = append(actions, trigger.NewWebhook(webhook))
+	}
+	var onLive func(tail.Line)
+	if len(actions) > 0 {
+		if !follow {
+			return fmt.Errorf("--exec and --webhook need -f or -F")
+		}
+		runner := trigger.NewRunner(ctx, actions, cmd.ErrOrStderr())
+		defer runner.Close()
+		onLive = func(line tail.Line) {
+			runner.Fire(trigger.Event{Line: line.Text, File: line.File, Time: time.Now()})
+		}
+	}
+
 	// Determine if we should show headers
 	// Default: show for multiple files only
 	// -v/--verbose: always show
 	// -q/--quiet: never show (overrides -v)
-	showHeaders := (multiFile || verbose) && !quiet
+	// --with-filename replaces headers with a prefix on every line
+	showHeaders := (multiFile || verbose) && !quiet && !withFilename
+	labels := fileLabels{
+		headers:   showHeaders,
+		prefix:    withFilename,
+		separator: filenameSep,
+		color:     color,
+		compact:   viper.GetBool("no-header-spacing"),
+		realpath:  viper.GetBool("header-realpath"),
+		null:      viper.GetBool("null-headers"),
+	}
+	// A file's own count is held to the same limits as -n or -c
+	for _, spec := range specs {
+		if spec.anchor == anchorAllButLast && follow {
+			return fmt.Errorf("cannot follow with n=~N (all but the last N lines)")
+		}
+		if spec.bytes && (filter != nil || !since.IsZero() || !until.IsZero() || mergeSorted) {
+			return fmt.Errorf("cannot combine c=N with --grep, --since, --until-time or --merge-sorted")
+		}
+		if spec.bytes && outputEncoding != nil {
+			return fmt.Errorf("cannot combine c=N with --output-encoding")
+		}
+	}
+
+	// A NUL-terminated name is all a -0 header is; the totals footer has no
+	// name to give, and --with-filename prints no headers
+	if labels.null && (totals || withFilename) {
+		return fmt.Errorf("cannot combine -0 with --totals or --with-filename")
+	}
+	// -v headers say how many bytes -c resolved to, so 5K and 5KB can be told apart
+	if verbose && byteMode && !fromStart && bytes > 0 {
+		labels.note = " (last " + humanBytes(bytes) + ")"
+	}
+	if viper.GetBool("headers-to-stderr") {
+		labels.headerOut = cmd.ErrOrStderr()
+	}
+
+	// Directories (e.g. from a shell glob) can't be tailed; skip them, like GNU tail
+	args, skipped := skipDirectories(cmd.ErrOrStderr(), args, ignoreDirs)
+	failed := skipped && !ignoreDirs
+
+	// Everything has been checked and resolved; --dry-run stops short of
+	// opening anything, the output file and metrics server included
+	if dryRun {
+		var mode string
+		switch {
+		case headTail > 0:
+			mode = fmt.Sprintf("first and last %d lines", headTail)
+		case startOffsetSet:
+			mode = fmt.Sprintf("from byte offset %d", startOffset)
+		case bytesThrough > 0:
+			mode = fmt.Sprintf("bytes %d through %d", bytes, bytesThrough)
+		case byteMode && fromStart:
+			mode = fmt.Sprintf("from byte %d", bytes)
+		case byteMode:
+			mode = fmt.Sprintf("last %d bytes", bytes)
+		case fromStart:
+			mode = fmt.Sprintf("from line %d", lines)
+		case allButLast:
+			mode = fmt.Sprintf("all but the last %d lines", lines)
+		default:
+			mode = fmt.Sprintf("last %d lines", lines)
+		}
+		followMode := "no"
+		if followName {
+			followMode = fmt.Sprintf("by name, polling every %v", sleepInterval)
+		} else if follow {
+			followMode = fmt.Sprintf("by descriptor, polling every %v", sleepInterval)
+		}
+		printDryRun(cmd.OutOrStdout(), args, mode, followMode, labels)
+		return exitStatus(cmd, failed)
+	}
+
+	// The metrics server runs for as long as tailing does
+	var registry *metrics.Registry
+	if addr := viper.GetString("metrics-addr"); addr != "" {
+		registry = metrics.NewRegistry()
+		stop, err := metrics.Serve(ctx, addr, registry)
+		if err != nil {
+			return fmt.Errorf("starting metrics server: %w", err)
+		}
+		defer stop()
+	}
+
+	// --output-file gets a copy of what goes to stdout, but after gzip, so
+	// it is always plain text. It is opened only once the flags have all been
+	// checked, so a bad command line doesn't truncate it.
+	if path := viper.GetString("output-file"); path != "" {
+		tee, err := openFileTee(path, viper.GetBool("append"), cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+		defer tee.Close()
+		if follow {
+			go tee.flushEvery(ctx, sleepInterval)
+		}
+		output = io.MultiWriter(output, tee)
+		if lineBuffered {
+			output = &flushingWriter{w: output, flush: func() error { tee.Flush(); return nil }}
+		}
+	}
+
+	// Lines are transcoded on their way to stdout and --output-file alike.
+	// Byte mode is byte-exact, so -c output is left as it is
+	if outputEncoding != nil && !byteMode {
+		enc := newEncodingWriter(output, outputEncoding)
+		defer enc.Close()
+		output = enc
+	}
+
+	// Settings shared by every input; Path is filled in per file
+	base := tail.TailerConfig{
+		Lines:                int(lines),
+		LinesSet:             linesSet,
+		Bytes:                bytes,
+		BytesSet:             byteMode,
+		BytesThrough:         bytesThrough,
+		FromStart:            fromStart,
+		AllButLast:           allButLast,
+		HeadTail:             headTail,
+		StartOffset:          startOffset,
+		StartOffsetSet:       startOffsetSet,
+		ClampStartOffset:     viper.GetBool("clamp-offset"),
+		Follow:               follow,
+		FollowName:           followName,
+		FollowStream:         followStdin,
+		Retry:                retry,
+		RetryTimeout:         retryTimeout,
+		RetryAttempts:        retryAttempts,
+		PIDs:                 pids,
+		AnyPIDDies:           pidAny,
+		PIDPollInterval:      pidPollInterval,
+		PollInterval:         sleepInterval,
+		PollJitter:           pollJitter,
+		ForcePoll:            viper.GetBool("poll"),
+		ZeroTerminated:       zeroTerminated,
+		OutputDelimiter:      outputDelim,
+		OutputDelimiterSet:   outputDelimSet,
+		OutputFlush:          flushMode,
+		FlushEvery:           flushEvery,
+		SFTP:                 sftpConfig,
+		LineEnding:           lineEnding,
+		BinaryFiles:          binaryFiles,
+		MaxLineWidth:         lineWidth,
+		MaxUnchangedStats:    maxUnchangedStats,
+		ReopenOnMaxUnchanged: reopenOnMaxUnchanged,
+		FollowSymlinkTarget:  viper.GetBool("follow-symlink-target"),
+		WarnRotation:         warnRotation,
+		KeepOpen:             viper.GetBool("keep-open"),
+		WaitForContent:       viper.GetDuration("wait-for-content"),
+		ReapplyWindow:        viper.GetBool("reapply-window-on-rotation"),
+		DrainRotated:         drainRotated,
+		DrainTimeout:         viper.GetDuration("drain-timeout"),
+		MaxMemory:            maxMemory,
+		ReopenAfterErrors:    reopenAfterErrors,
+		LiveMarker:           liveMarker,
+		MaxRate:              maxRate,
+		RateBytes:            rateBytes,
+		DropOverflow:         dropOverflow,
+		Uniq:                 viper.GetBool("uniq") || viper.GetBool("uniq-count"),
+		UniqCount:            viper.GetBool("uniq-count"),
+		Filter:               filter,
+		ContextBefore:        contextBefore,
+		ContextAfter:         contextAfter,
+		Since:                since,
+		Until:                until,
+		TimeLayout:           timeLayout,
+		DropUnparseable:      dropUnparseable,
+		OnLive:               onLive,
+		Debug:                viper.GetBool("debug"),
+		Metrics:              registry,
+		Stderr:               cmd.ErrOrStderr(),
+	}
+
+	if countOnly {
+		if runCount(ctx, cmd, args, base, specs, output, labels, totals) {
+			failed = true
+		}
+		return exitStatus(cmd, failed)
+	}
 
 	// For follow mode with multiple files, run concurrently
 	if follow && multiFile {
-		return runMultiFileFollow(ctx, args, int(lines), bytes, fromStart, sleepInterval, pid, output, showHeaders, retry, followName, zeroTerminated, maxUnchangedStats)
+		var merge *merger
+		if mergeSorted {
+			merge = newMerger(timeLayout, mergeWindow)
+		}
+		if runMultiFileFollow(ctx, args, base, specs, output, labels, merge) {
+			failed = true
+		}
+		return exitStatus(cmd, failed)
 	}
 
+	// Count what each input emits (headers excluded) for the --totals footer
+	delim := outputDelim
+	counter := &countingWriter{w: output, delim: delim}
+
 	// Sequential processing for non-follow or single file
-	for i, path := range args {
-		// Handle stdin ("-")
-		if path == "-" {
-			if showHeaders {
-				if i > 0 {
-					fmt.Fprintln(output)
-				}
-				fmt.Fprintf(output, "==> standard input <==\n")
-			}
+	headerPrinted := false
+	for _, path := range args {
+		name := labels.name(path)
 
-			config := tail.TailerConfig{
-				Lines:          int(lines),
-				Bytes:          bytes,
-				FromStart:      fromStart,
-				ZeroTerminated: zeroTerminated,
+		// Headers are printed lazily, with the first output, so an empty or
+		// unreadable file gets none; -v asks for every header regardless
+		var w io.Writer = counter
+		if withFilename {
+			w = &linePrefixWriter{out: output, w: counter, prefix: labels.linePrefix(name), delim: delim, mu: &sync.Mutex{}}
+		} else if showHeaders {
+			hw := &headerWriter{out: labels.headerDest(output), w: counter, name: name, note: labels.note, printed: &headerPrinted, color: color, compact: labels.compact, null: labels.null}
+			if verbose && !quietIfEmpty {
+				hw.writeHeader()
 			}
-			tailer := tail.NewTailer(config)
-			if err := tailer.TailReader(ctx, os.Stdin, output); err != nil {
+			w = hw
+		}
+
+		// Handle stdin ("-")
+		config := specs.config(base, path)
+		if path == "-" {
+			tailer := newTailer(config)
+			if err := tailer.TailReader(ctx, os.Stdin, w); err != nil {
 				fmt.Fprintf(cmd.ErrOrStderr(), "wail: standard input: %v\n", err)
+				failed = true
 			}
 			continue
 		}
 
-		if showHeaders {
-			if i > 0 {
-				fmt.Fprintln(output)
-			}
-			fmt.Fprintf(output, "==> %s <==\n", path)
+		tailer := newTailer(config)
+		if err := tailer.Tail(ctx, w); err != nil {
+			reportError(cmd.ErrOrStderr(), path, err)
+			failed = true
 		}
+	}
+
+	// The footer follows the same visibility rules as the per-file headers
+	if totals && showHeaders && !follow {
+		fmt.Fprintf(labels.headerDest(output), "%s==> total <==\n", labels.headerGap())
+		fmt.Fprintf(output, "%d lines, %d bytes\n", counter.lines, counter.bytes)
+	}
+
+	return exitStatus(cmd, failed)
+}
 
-		config := tail.TailerConfig{
-			Path:              path,
-			Lines:             int(lines),
-			Bytes:             bytes,
-			FromStart:         fromStart,
-			Follow:            follow,
-			FollowName:        followName,
-			Retry:             retry,
-			PID:               pid,
-			PollInterval:      sleepInterval,
-			ZeroTerminated:    zeroTerminated,
-			MaxUnchangedStats: maxUnchangedStats,
+// headerWriter writes content to w, printing the "==> name <==" header to out
+// first if it hasn't been already. printed is shared across inputs so that a
+// blank line separates each header from the previous file's output, unless
+// compact or null.
+type headerWriter struct {
+	out     io.Writer
+	w       io.Writer
+	name    string
+	note    string // printed after the header, as fileLabels.note
+	printed *bool
+	done    bool
+	color   bool
+	compact bool
+	null    bool // the header is name and a NUL (-0)
+}
+
+// writeHeader prints the header now, unless it has been printed already.
+func (hw *headerWriter) writeHeader() {
+	if hw.done {
+		return
+	}
+	if hw.null {
+		fmt.Fprintf(hw.out, "%s\x00", hw.name)
+	} else {
+		if *hw.printed && !hw.compact {
+			fmt.Fprintln(hw.out)
 		}
+		fmt.Fprintf(hw.out, "==> %s <==%s\n", colorName(hw.name, hw.color), hw.note)
+	}
+	hw.done = true
+	*hw.printed = true
+}
 
-		tailer := tail.NewTailer(config)
-		if err := tailer.Tail(ctx, output); err != nil {
-			fmt.Fprintf(cmd.ErrOrStderr(), "wail: %s: %v\n", path, err)
+func (hw *headerWriter) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		hw.writeHeader()
+	}
+	return hw.w.Write(p)
+}
+
+// flushingWriter calls flush after every write to w, for --line-buffered, so
+// a writer that buffers passes each line on as soon as it is written.
+type flushingWriter struct {
+	w     io.Writer
+	flush func() error
+}
+
+func (fw *flushingWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, fw.flush()
+}
+
+// exitStatus returns errFileFailed if any input failed, so wail exits 1 like
+// GNU tail. The errors were reported as they happened, so cobra is told not
+// to print this one again or show usage.
+func exitStatus(cmd *cobra.Command, failed bool) error {
+	if !failed {
+		return nil
+	}
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	return errFileFailed
+}
+
+// printDryRun writes the --dry-run report: each file that would be tailed,
+// in order, then what would be output from each and how it is labelled.
+func printDryRun(w io.Writer, files []string, mode, follow string, labels fileLabels) {
+	fmt.Fprintf(w, "files (%d):\n", len(files))
+	for _, path := range files {
+		if path == "-" {
+			fmt.Fprintln(w, "  - (standard input)")
+			continue
 		}
+		fmt.Fprintf(w, "  %s\n", path)
 	}
+	labelling := "none"
+	switch {
+	case labels.prefix:
+		labelling = fmt.Sprintf("file name prefix (%q)", labels.separator)
+	case labels.headers && labels.null:
+		labelling = "NUL-terminated headers"
+	case labels.headers:
+		labelling = "headers"
+	}
+	fmt.Fprintf(w, "mode: %s\nfollow: %s\nlabels: %s\n", mode, follow, labelling)
+}
 
+// skipDirectories returns paths without the directories among them, reporting
+// each one skipped to w unless silent is set. skipped reports whether any were.
+func skipDirectories(w io.Writer, paths []string, silent bool) (files []string, skipped bool) {
+	for _, path := range paths {
+		if path != "-" {
+			if info, err := os.Stat(path); err == nil && info.IsDir() {
+				if !silent {
+					fmt.Fprintf(w, "wail: %s: Is a directory\n", path)
+				}
+				skipped = true
+				continue
+			}
+		}
+		files = append(files, path)
+	}
+	return files, skipped
+}
+
+// countingWriter passes writes through to w while counting bytes and
+// line delimiters, so totals reflect exactly what was emitted.
+type countingWriter struct {
+	w     io.Writer
+	delim byte
+	lines int64
+	bytes int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.bytes += int64(n)
+	cw.lines += int64(bytes.Count(p[:n], []byte{cw.delim}))
+	return n, err
+}
+
+// reportError prints a per-file error to w, followed by a hint when the
+// failure is one users commonly hit on Windows (locked or protected files).
+func reportError(w io.Writer, path string, err error) {
+	fmt.Fprintf(w, "wail: %s: %v\n", path, err)
+	if hint := errorHint(err); hint != "" {
+		fmt.Fprintf(w, "wail: %s: hint: %s\n", path, hint)
+	}
+}
+
+// errorHint returns a short explanation for well-known open failures,
+// or "" if there is nothing useful to add.
+func errorHint(err error) string {
+	switch {
+	case errors.Is(err, filesystem.ErrSharingViolation):
+		return "file is locked; wail already opens with shared mode — the owner may have opened it exclusively"
+	case errors.Is(err, filesystem.ErrAccessDenied):
+		return "access denied; check that your account has read permission on the file"
+	}
+	return ""
+}
+
+// parseDelimiter parses an --output-delimiter value: a single byte, or one
+// of the escapes \n, \t, \r and \0.
+func parseDelimiter(s string) (byte, error) {
+	switch s {
+	case `\n`:
+		return '\n', nil
+	case `\t`:
+		return '\t', nil
+	case `\r`:
+		return '\r', nil
+	case `\0`:
+		return '\x00', nil
+	}
+	if len(s) != 1 {
+		return 0, fmt.Errorf("invalid output-delimiter value: %q (use a single character, or \\n, \\t, \\r or \\0)", s)
+	}
+	return s[0], nil
+}
+
+// fdHeadroom is how many descriptors are set aside, beyond one per file, for
+// stdio, the file watcher and the like when checking the open file limit.
+const fdHeadroom = 16
+
+// checkFileCount returns an error if n files are more than --max-files
+// allows, and warns on stderr if following them all may run into the open
+// file limit. A max of 0 is no limit.
+func checkFileCount(n, max int, stderr io.Writer) error {
+	if max < 0 {
+		return fmt.Errorf("invalid max-files value: %d", max)
+	}
+	if max > 0 && n > max {
+		return fmt.Errorf("too many files: %d, limit %d; raise with --max-files", n, max)
+	}
+	if limit, ok := openFileLimit(); ok && n+fdHeadroom > limit {
+		fmt.Fprintf(stderr, "wail: following %d files may exceed the open file limit of %d; raise it with ulimit -n\n", n, limit)
+	}
 	return nil
 }
 
-func runMultiFileFollow(ctx context.Context, paths []string, lines int, bytes int64, fromStart bool, sleepInterval time.Duration, pid int, output io.Writer, showHeaders bool, retry bool, followName bool, zeroTerminated bool, maxUnchangedStats int) error {
+// parseNewline parses a --newline value. keep is what -b and
+// --no-crlf-normalize ask for, so giving them with anything else is an error;
+// with none of them, lines end in LF.
+func parseNewline(s string, keep bool) (tail.LineEnding, error) {
+	switch s {
+	case "":
+		if keep {
+			return tail.LineEndingKeep, nil
+		}
+		return tail.LineEndingLF, nil
+	case "keep":
+		return tail.LineEndingKeep, nil
+	case "lf", "crlf":
+		if keep {
+			return 0, fmt.Errorf("cannot combine --newline=%s with -b or --no-crlf-normalize", s)
+		}
+		if s == "crlf" {
+			return tail.LineEndingCRLF, nil
+		}
+		return tail.LineEndingLF, nil
+	}
+	return 0, fmt.Errorf("invalid newline value: %s (use 'lf', 'crlf' or 'keep')", s)
+}
+
+// parseBinaryFiles parses a --binary-files value. --text overrides it, and
+// with neither, binary files are skipped with a warning on a terminal and
+// output otherwise.
+func parseBinaryFiles(s string, text, terminal bool) (tail.BinaryFiles, error) {
+	if text {
+		return tail.BinaryText, nil
+	}
+	switch s {
+	case "":
+		if terminal {
+			return tail.BinaryWarn, nil
+		}
+		return tail.BinaryText, nil
+	case "text":
+		return tail.BinaryText, nil
+	case "skip":
+		return tail.BinarySkip, nil
+	case "warn":
+		return tail.BinaryWarn, nil
+	}
+	return 0, fmt.Errorf("invalid binary-files value: %s (use 'text', 'skip' or 'warn')", s)
+}
+
+// timeBoundLayouts are the forms --since and --until-time accept besides
+// --time-layout and a duration. Those without a zone are local time.
+var timeBoundLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// parseTimeBound parses the value of the time flag name: a time in the
+// --time-layout or one of timeBoundLayouts, or a duration (such as 90m) to
+// go back from now. The empty string is the zero time, leaving that end of
+// the window open.
+func parseTimeBound(name, s, layout string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	layouts := timeBoundLayouts
+	if layout != "" {
+		layouts = append([]string{layout}, layouts...)
+	}
+	for _, l := range layouts {
+		if t, err := time.ParseInLocation(l, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid %s value: %s (use an RFC 3339 time, a date, or a duration ago such as 90m)", name, s)
+}
+
+// parseOutputBuffer parses a --lines-output-buffer value: "line", "batch",
+// or a positive duration to write output out on a timer.
+func parseOutputBuffer(s string) (tail.FlushMode, time.Duration, error) {
+	switch s {
+	case "line":
+		return tail.FlushLine, 0, nil
+	case "batch":
+		return tail.FlushBatch, 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, 0, fmt.Errorf("invalid lines-output-buffer value: %s (use 'line', 'batch' or a duration such as 200ms)", s)
+	}
+	return tail.FlushTimed, d, nil
+}
+
+// lineDelim returns the byte that ends each line of input, and of output
+// unless --output-delimiter says otherwise.
+func lineDelim(zeroTerminated bool) byte {
+	if zeroTerminated {
+		return '\x00'
+	}
+	return '\n'
+}
+
+// fileLabels says how output from each input is attributed to its file.
+type fileLabels struct {
+	headers   bool // "==> name <==" headers
+	prefix    bool // name and separator before every line (--with-filename)
+	separator string
+	color     bool      // color the names
+	compact   bool      // no blank line before headers (--no-header-spacing)
+	realpath  bool      // name files by their resolved absolute path (--header-realpath)
+	headerOut io.Writer // where headers go instead of output (--headers-to-stderr); nil for output
+	note      string    // after the name in each header, e.g. " (last 5.0 KiB)"
+	null      bool      // headers are the bare name and a NUL, with no arrows, gap, color or note (-0)
+}
+
+// name returns how the input at path is named in headers and prefixes. With
+// realpath, that is its absolute path with symlinks resolved, or path as
+// given if that can't be worked out, as for a missing file or an sftp:// URL.
+// Only the name changes: the file is still opened by path.
+func (l fileLabels) name(path string) string {
+	if path == "-" {
+		return "standard input"
+	}
+	if !l.realpath {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return path
+	}
+	return resolved
+}
+
+// headerDest returns where headers go when content goes to output.
+func (l fileLabels) headerDest(output io.Writer) io.Writer {
+	if l.headerOut != nil {
+		return l.headerOut
+	}
+	return output
+}
+
+// headerGap returns what separates a header from output before it.
+func (l fileLabels) headerGap() string {
+	if l.compact {
+		return ""
+	}
+	return "\n"
+}
+
+// linePrefix returns the prefix for each line from name.
+func (l fileLabels) linePrefix(name string) string {
+	return colorName(name, l.color) + l.separator
+}
+
+// runMultiFileFollow follows every path concurrently until ctx is cancelled,
+// with the count each was given in specs, labelling output as labels says,
+// and with merge set, ordering lines across files by their timestamps.
+// Failures are reported to base.Stderr; it returns true if any file failed.
+func runMultiFileFollow(ctx context.Context, paths []string, base tail.TailerConfig, specs fileSpecs, output io.Writer, labels fileLabels, merge *merger) bool {
 	var wg sync.WaitGroup
 	var mu sync.Mutex
+	var failed atomic.Bool
 	lastPrinted := "" // shared state to track which file header was last printed
 
 	for _, path := range paths {
@@ -266,45 +1506,67 @@ func runMultiFileFollow(ctx context.Context, paths []string, lines int, bytes in
 			defer wg.Done()
 
 			var w io.Writer = output
-			if showHeaders {
+			if labels.prefix {
+				w = &linePrefixWriter{
+					out:    output,
+					w:      output,
+					prefix: labels.linePrefix(labels.name(p)),
+					delim:  base.OutputDelimiter,
+					mu:     &mu,
+				}
+			} else if labels.headers {
 				w = &prefixWriter{
+					out:         labels.headerDest(output),
 					w:           output,
-					prefix:      p,
+					prefix:      labels.name(p),
+					note:        labels.note,
 					mu:          &mu,
 					lastPrinted: &lastPrinted,
+					color:       labels.color,
+					gap:         labels.headerGap(),
+					null:        labels.null,
 				}
 			}
-
-			config := tail.TailerConfig{
-				Path:              p,
-				Lines:             lines,
-				Bytes:             bytes,
-				FromStart:         fromStart,
-				Follow:            true,
-				FollowName:        followName,
-				Retry:             retry,
-				PID:               pid,
-				PollInterval:      sleepInterval,
-				ZeroTerminated:    zeroTerminated,
-				MaxUnchangedStats: maxUnchangedStats,
+			// Lines are labelled as they leave the merger, so a header
+			// still marks each change of file
+			if merge != nil {
+				src := merge.source(w, base.OutputDelimiter)
+				defer src.Close()
+				w = src
 			}
 
-			tailer := tail.NewTailer(config)
-			tailer.Tail(ctx, w)
+			config := specs.config(base, p)
+			config.Follow = true
+
+			tailer := newTailer(config)
+			if err := tailer.Tail(ctx, w); err != nil {
+				reportError(base.Stderr, p, err)
+				failed.Store(true)
+			}
 		}(path)
 	}
 
 	wg.Wait()
-	return nil
+	if merge != nil {
+		merge.Close()
+	}
+	return failed.Load()
 }
 
-// prefixWriter wraps a writer and prefixes each write with a filename header.
-// Headers are only printed when the source changes (like GNU tail).
+// prefixWriter writes content to w, printing a filename header to out first.
+// Headers are only printed when the source changes (like GNU tail). out is
+// normally w; when it isn't, mu still orders the two, so each header comes
+// just before the content it names.
 type prefixWriter struct {
+	out         io.Writer
 	w           io.Writer
 	prefix      string
+	note        string // printed after the header, as fileLabels.note
 	mu          *sync.Mutex
 	lastPrinted *string // shared pointer to track which file header was last printed
+	color       bool
+	gap         string // printed before each header
+	null        bool   // the header is prefix and a NUL (-0), with no gap
 }
 
 func (pw *prefixWriter) Write(p []byte) (n int, err error) {
@@ -313,8 +1575,51 @@ func (pw *prefixWriter) Write(p []byte) (n int, err error) {
 
 	// Only print header if source changed or this is the first write
 	if *pw.lastPrinted != pw.prefix {
-		fmt.Fprintf(pw.w, "\n==> %s <==\n", pw.prefix)
+		if pw.null {
+			fmt.Fprintf(pw.out, "%s\x00", pw.prefix)
+		} else {
+			fmt.Fprintf(pw.out, "%s==> %s <==%s\n", pw.gap, colorName(pw.prefix, pw.color), pw.note)
+		}
 		*pw.lastPrinted = pw.prefix
 	}
 	return pw.w.Write(p)
 }
+
+// linePrefixWriter writes content to w with prefix written to out at the
+// start of every line, so merged output from several files stays attributable
+// line by line. A single Write may carry several lines, or only part of one;
+// the prefix goes wherever a line begins. mu serialises writers sharing out.
+type linePrefixWriter struct {
+	out     io.Writer
+	w       io.Writer
+	prefix  string
+	delim   byte
+	mu      *sync.Mutex
+	midLine bool // the last write ended without a delimiter
+}
+
+func (lw *linePrefixWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	written := 0
+	for len(p) > 0 {
+		if !lw.midLine {
+			if _, err := io.WriteString(lw.out, lw.prefix); err != nil {
+				return written, err
+			}
+		}
+		end := len(p)
+		if i := bytes.IndexByte(p, lw.delim); i >= 0 {
+			end = i + 1
+		}
+		n, err := lw.w.Write(p[:end])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		lw.midLine = p[end-1] != lw.delim
+		p = p[end:]
+	}
+	return written, nil
+}
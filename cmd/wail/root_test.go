@@ -2,11 +2,19 @@ package main
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/jmurray2011/wail/internal/filesystem"
+	"github.com/jmurray2011/wail/internal/tail"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -42,14 +50,35 @@ func TestParseNumArg(t *testing.T) {
 		// With + prefix
 		{"+5K", 5 * 1024, true, false},
 
+		// With ~ prefix (all but last N)
+		{"~3", 3, false, false},
+
+		// Blanks around the number, as GNU tail allows
+		{" 5", 5, false, false},
+		{"+5 ", 5, true, false},
+		{"\t5K\n", 5 * 1024, false, false},
+
+		// A suffix after + in byte mode's larger units
+		{"+2MB", 2 * 1000 * 1000, true, false},
+
 		// Invalid
 		{"abc", 0, false, true},
 		{"5X", 0, false, true},
+		{"+", 0, false, true},
+		{"-", 0, false, true},
+		{"~", 0, false, true},
+		{" ", 0, false, true},
+		{"K", 0, false, true},
+		{"--5", 0, false, true},
+		{"+-5", 0, false, true},
+		{"5 K", 0, false, true},
+		{"99999999999999999999", 0, false, true},
+		{"9000000000G", 0, false, true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			num, fromStart, err := parseNumArg(tt.input)
+			num, anchor, err := parseNumArg(tt.input)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("parseNumArg(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
 				return
@@ -58,7 +87,7 @@ func TestParseNumArg(t *testing.T) {
 				if num != tt.wantNum {
 					t.Errorf("parseNumArg(%q) num = %d, want %d", tt.input, num, tt.wantNum)
 				}
-				if fromStart != tt.wantStart {
+				if fromStart := anchor == anchorStart; fromStart != tt.wantStart {
 					t.Errorf("parseNumArg(%q) fromStart = %v, want %v", tt.input, fromStart, tt.wantStart)
 				}
 			}
@@ -66,41 +95,352 @@ func TestParseNumArg(t *testing.T) {
 	}
 }
 
+func TestParseNumArg_MissingNumber(t *testing.T) {
+	// An empty argument is 0, but one with only a sign or suffix is a mistake
+	if n, _, err := parseNumArg(""); n != 0 || err != nil {
+		t.Errorf("parseNumArg(\"\") = %d, %v, want 0 and no error", n, err)
+	}
+	for _, input := range []string{"+", "-", "~", "K", "+K", "b"} {
+		_, _, err := parseNumArg(input)
+		if want := fmt.Sprintf("missing number in %q", input); err == nil || err.Error() != want {
+			t.Errorf("parseNumArg(%q) error = %v, want %q", input, err, want)
+		}
+	}
+}
+
+func TestCLI_InvalidCountNamesFlag(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"-n", "+"}, `invalid lines value: missing number in "+"`},
+		{[]string{"-c", "-"}, `invalid bytes value: missing number in "-"`},
+		{[]string{"-c", "+K"}, `invalid bytes value: missing number in "+K"`},
+		{[]string{"-c", "9000000000G"}, `invalid bytes value: number too large: "9000000000G"`},
+	}
+
+	for _, tt := range tests {
+		cmd := newTestCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append(tt.args, os.DevNull))
+		err := cmd.Execute()
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Execute(%q) error = %v, want one containing %q", tt.args, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantRate  float64
+		wantBytes bool
+		wantErr   bool
+	}{
+		{"", 0, false, false},
+		{"100", 100, false, false},
+		{"0.5", 0.5, false, false},
+		{"100/s", 100, false, false},
+		{"64K", 64 * 1024, true, false},
+		{"1MB/s", 1000 * 1000, true, false},
+		{"0", 0, false, true},
+		{"-5", 0, false, true},
+		{"+5K", 0, false, true},
+		{"fast", 0, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			rate, bytes, err := parseRate(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRate(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if rate != tt.wantRate || bytes != tt.wantBytes {
+				t.Errorf("parseRate(%q) = %v, %v; want %v, %v", tt.input, rate, bytes, tt.wantRate, tt.wantBytes)
+			}
+		})
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"50ms", 50 * time.Millisecond, false},
+		{"0.05", 50 * time.Millisecond, false},
+		{"1s", time.Second, false},
+		{"1", time.Second, false},
+		{"0.1", 100 * time.Millisecond, false},
+		{"500us", 0, true},
+		{"1ms", time.Millisecond, false},
+		{"1.5ms", 1500 * time.Microsecond, false},
+		{" 2s ", 2 * time.Second, false},
+		{"0", 0, true},
+		{"0.0001", 0, true},
+		{"-1", 0, true},
+		{"-50ms", 0, true},
+		{"NaN", 0, true},
+		{"1e300", 0, true},
+		{"soon", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseInterval(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseInterval(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseInterval(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLI_PIDPollInterval(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr string
+	}{
+		{"200ms", ""},
+		{"0.2", ""},
+		{"500us", "invalid pid-poll-interval value"},
+		{"0", "invalid pid-poll-interval value"},
+		{"soon", "invalid pid-poll-interval value"},
+	}
+
+	for _, tt := range tests {
+		cmd := newTestCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"--pid-poll-interval", tt.value, os.DevNull})
+		err := cmd.Execute()
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("--pid-poll-interval %s: error = %v", tt.value, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("--pid-poll-interval %s: error = %v, want one containing %q", tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParsePIDList(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []int
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"123", []int{123}, false},
+		{"123,456", []int{123, 456}, false},
+		{"123, 456", []int{123, 456}, false},
+		{"abc", nil, true},
+		{"123,", nil, true},
+		{"0", nil, true},
+		{"-5", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parsePIDList(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parsePIDList(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+				return
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parsePIDList(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
 // newTestCmd creates a fresh command instance for testing (avoids global state issues)
 func newTestCmd() *cobra.Command {
 	// Reset viper for each test
 	viper.Reset()
 
 	cmd := &cobra.Command{
-		Use:  "wail [file...]",
-		Args: cobra.ArbitraryArgs,
-		RunE: runTail,
+		Use:               "wail [file...]",
+		Args:              cobra.ArbitraryArgs,
+		PersistentPreRunE: loadConfig,
+		RunE:              runTail,
 	}
 	cmd.Flags().StringP("lines", "n", "10", "")
 	cmd.Flags().StringP("bytes", "c", "", "")
+	cmd.Flags().Int64("start-offset", 0, "")
+	cmd.Flags().Bool("clamp-offset", false, "")
+	cmd.Flags().Int("head-tail", 0, "")
 	cmd.Flags().StringP("follow", "f", "", "")
 	cmd.Flags().Lookup("follow").NoOptDefVal = "descriptor"
 	cmd.Flags().BoolP("follow-name", "F", false, "")
-	cmd.Flags().Float64P("sleep-interval", "s", 0.1, "")
-	cmd.Flags().Int("pid", 0, "")
+	cmd.Flags().Bool("follow-stdin", false, "")
+	cmd.Flags().StringP("sleep-interval", "s", "0.1", "")
+	cmd.Flags().Bool("poll", false, "")
+	cmd.Flags().Float64("poll-jitter", 0, "")
+	cmd.Flags().String("pid", "", "")
+	cmd.Flags().Bool("pid-any", false, "")
+	cmd.Flags().String("pid-poll-interval", "", "")
 	cmd.Flags().BoolP("quiet", "q", false, "")
 	cmd.Flags().BoolP("verbose", "v", false, "")
+	cmd.Flags().Bool("no-header-spacing", false, "")
 	cmd.Flags().Bool("retry", false, "")
+	cmd.Flags().Duration("retry-timeout", 0, "")
+	cmd.Flags().Int("retry-attempts", 0, "")
+	cmd.Flags().Int("max-files", 1024, "")
 	cmd.Flags().BoolP("zero-terminated", "z", false, "")
+	cmd.Flags().String("files-from", "", "")
+	cmd.Flags().String("output-delimiter", "", "")
+	cmd.Flags().String("lines-output-buffer", "batch", "")
+	cmd.Flags().BoolP("binary", "b", false, "")
+	cmd.Flags().String("newline", "", "")
+	cmd.Flags().Bool("no-crlf-normalize", false, "")
+	cmd.Flags().String("binary-files", "", "")
+	cmd.Flags().Bool("text", false, "")
+	cmd.Flags().String("max-line-width", "", "")
+	cmd.Flags().Lookup("max-line-width").NoOptDefVal = "auto"
 	cmd.Flags().Int("max-unchanged-stats", 0, "")
+	cmd.Flags().Bool("reopen-on-max-unchanged", false, "")
+	cmd.Flags().Bool("follow-symlink-target", false, "")
+	cmd.Flags().Bool("warn-rotation", false, "")
+	cmd.Flags().Bool("keep-open", false, "")
+	cmd.Flags().Duration("wait-for-content", 0, "")
+	cmd.Flags().Lookup("wait-for-content").NoOptDefVal = "5s"
+	cmd.Flags().Bool("reapply-window-on-rotation", false, "")
+	cmd.Flags().String("drain-rotated", "", "")
+	cmd.Flags().Duration("drain-timeout", time.Second, "")
+	cmd.Flags().String("max-memory", "", "")
+	cmd.Flags().Bool("dry-run", false, "")
+	cmd.Flags().Bool("line-buffered", false, "")
+	cmd.Flags().Bool("merge-sorted", false, "")
+	cmd.Flags().Duration("merge-window", 500*time.Millisecond, "")
+	cmd.Flags().BoolP("null-headers", "0", false, "")
+	cmd.Flags().Int("reopen-after-errors", 5, "")
+	cmd.Flags().Bool("totals", false, "")
+	cmd.Flags().Bool("count", false, "")
+	cmd.Flags().String("max-rate", "", "")
+	cmd.Flags().String("on-overflow", "delay", "")
+	cmd.Flags().Bool("uniq", false, "")
+	cmd.Flags().Bool("uniq-count", false, "")
+	cmd.Flags().String("grep", "", "")
+	cmd.Flags().BoolP("ignore-case", "i", false, "")
+	cmd.Flags().String("since", "", "")
+	cmd.Flags().String("until-time", "", "")
+	cmd.Flags().String("time-layout", "", "")
+	cmd.Flags().String("on-unparseable", "emit", "")
+	cmd.Flags().IntP("before", "B", 0, "")
+	cmd.Flags().IntP("after", "A", 0, "")
+	cmd.Flags().IntP("context", "C", 0, "")
+	cmd.Flags().String("exec", "", "")
+	cmd.Flags().String("webhook", "", "")
+	cmd.Flags().Bool("debug", false, "")
+	cmd.Flags().StringSlice("ssh-key", nil, "")
+	cmd.Flags().String("ssh-known-hosts", "", "")
+	cmd.Flags().String("metrics-addr", "", "")
+	cmd.Flags().Bool("gzip-output", false, "")
+	cmd.Flags().String("output-encoding", "", "")
+	cmd.Flags().String("output-file", "", "")
+	cmd.Flags().Bool("append", false, "")
+	cmd.Flags().Bool("interactive", false, "")
+	cmd.Flags().Bool("ignore-directories", false, "")
+	cmd.Flags().Bool("quiet-if-empty", false, "")
+	cmd.Flags().String("color", "auto", "")
+	cmd.Flags().Lookup("color").NoOptDefVal = "always"
+	cmd.Flags().Bool("with-filename", false, "")
+	cmd.Flags().Bool("header-realpath", false, "")
+	cmd.Flags().Bool("headers-to-stderr", false, "")
+	cmd.Flags().String("filename-separator", ": ", "")
+	cmd.Flags().String("mark-live", "", "")
+	cmd.Flags().Lookup("mark-live").NoOptDefVal = "--- following ---"
 
 	// Bind viper to flags
 	viper.BindPFlag("lines", cmd.Flags().Lookup("lines"))
 	viper.BindPFlag("bytes", cmd.Flags().Lookup("bytes"))
+	viper.BindPFlag("start-offset", cmd.Flags().Lookup("start-offset"))
+	viper.BindPFlag("clamp-offset", cmd.Flags().Lookup("clamp-offset"))
+	viper.BindPFlag("head-tail", cmd.Flags().Lookup("head-tail"))
 	viper.BindPFlag("follow", cmd.Flags().Lookup("follow"))
 	viper.BindPFlag("follow-name", cmd.Flags().Lookup("follow-name"))
+	viper.BindPFlag("follow-stdin", cmd.Flags().Lookup("follow-stdin"))
 	viper.BindPFlag("sleep-interval", cmd.Flags().Lookup("sleep-interval"))
+	viper.BindPFlag("poll", cmd.Flags().Lookup("poll"))
+	viper.BindPFlag("poll-jitter", cmd.Flags().Lookup("poll-jitter"))
 	viper.BindPFlag("pid", cmd.Flags().Lookup("pid"))
+	viper.BindPFlag("pid-any", cmd.Flags().Lookup("pid-any"))
+	viper.BindPFlag("pid-poll-interval", cmd.Flags().Lookup("pid-poll-interval"))
 	viper.BindPFlag("quiet", cmd.Flags().Lookup("quiet"))
 	viper.BindPFlag("verbose", cmd.Flags().Lookup("verbose"))
+	viper.BindPFlag("no-header-spacing", cmd.Flags().Lookup("no-header-spacing"))
 	viper.BindPFlag("retry", cmd.Flags().Lookup("retry"))
+	viper.BindPFlag("retry-timeout", cmd.Flags().Lookup("retry-timeout"))
+	viper.BindPFlag("retry-attempts", cmd.Flags().Lookup("retry-attempts"))
+	viper.BindPFlag("max-files", cmd.Flags().Lookup("max-files"))
 	viper.BindPFlag("zero-terminated", cmd.Flags().Lookup("zero-terminated"))
+	viper.BindPFlag("files-from", cmd.Flags().Lookup("files-from"))
+	viper.BindPFlag("output-delimiter", cmd.Flags().Lookup("output-delimiter"))
+	viper.BindPFlag("lines-output-buffer", cmd.Flags().Lookup("lines-output-buffer"))
+	viper.BindPFlag("binary", cmd.Flags().Lookup("binary"))
+	viper.BindPFlag("newline", cmd.Flags().Lookup("newline"))
+	viper.BindPFlag("no-crlf-normalize", cmd.Flags().Lookup("no-crlf-normalize"))
+	viper.BindPFlag("binary-files", cmd.Flags().Lookup("binary-files"))
+	viper.BindPFlag("text", cmd.Flags().Lookup("text"))
+	viper.BindPFlag("max-line-width", cmd.Flags().Lookup("max-line-width"))
 	viper.BindPFlag("max-unchanged-stats", cmd.Flags().Lookup("max-unchanged-stats"))
+	viper.BindPFlag("reopen-on-max-unchanged", cmd.Flags().Lookup("reopen-on-max-unchanged"))
+	viper.BindPFlag("follow-symlink-target", cmd.Flags().Lookup("follow-symlink-target"))
+	viper.BindPFlag("warn-rotation", cmd.Flags().Lookup("warn-rotation"))
+	viper.BindPFlag("keep-open", cmd.Flags().Lookup("keep-open"))
+	viper.BindPFlag("wait-for-content", cmd.Flags().Lookup("wait-for-content"))
+	viper.BindPFlag("reapply-window-on-rotation", cmd.Flags().Lookup("reapply-window-on-rotation"))
+	viper.BindPFlag("drain-rotated", cmd.Flags().Lookup("drain-rotated"))
+	viper.BindPFlag("drain-timeout", cmd.Flags().Lookup("drain-timeout"))
+	viper.BindPFlag("max-memory", cmd.Flags().Lookup("max-memory"))
+	viper.BindPFlag("dry-run", cmd.Flags().Lookup("dry-run"))
+	viper.BindPFlag("line-buffered", cmd.Flags().Lookup("line-buffered"))
+	viper.BindPFlag("merge-sorted", cmd.Flags().Lookup("merge-sorted"))
+	viper.BindPFlag("merge-window", cmd.Flags().Lookup("merge-window"))
+	viper.BindPFlag("null-headers", cmd.Flags().Lookup("null-headers"))
+	viper.BindPFlag("reopen-after-errors", cmd.Flags().Lookup("reopen-after-errors"))
+	viper.BindPFlag("totals", cmd.Flags().Lookup("totals"))
+	viper.BindPFlag("count", cmd.Flags().Lookup("count"))
+	viper.BindPFlag("max-rate", cmd.Flags().Lookup("max-rate"))
+	viper.BindPFlag("on-overflow", cmd.Flags().Lookup("on-overflow"))
+	viper.BindPFlag("uniq", cmd.Flags().Lookup("uniq"))
+	viper.BindPFlag("uniq-count", cmd.Flags().Lookup("uniq-count"))
+	viper.BindPFlag("grep", cmd.Flags().Lookup("grep"))
+	viper.BindPFlag("ignore-case", cmd.Flags().Lookup("ignore-case"))
+	viper.BindPFlag("since", cmd.Flags().Lookup("since"))
+	viper.BindPFlag("until-time", cmd.Flags().Lookup("until-time"))
+	viper.BindPFlag("time-layout", cmd.Flags().Lookup("time-layout"))
+	viper.BindPFlag("on-unparseable", cmd.Flags().Lookup("on-unparseable"))
+	viper.BindPFlag("before", cmd.Flags().Lookup("before"))
+	viper.BindPFlag("after", cmd.Flags().Lookup("after"))
+	viper.BindPFlag("context", cmd.Flags().Lookup("context"))
+	viper.BindPFlag("exec", cmd.Flags().Lookup("exec"))
+	viper.BindPFlag("webhook", cmd.Flags().Lookup("webhook"))
+	viper.BindPFlag("debug", cmd.Flags().Lookup("debug"))
+	viper.BindPFlag("ssh-key", cmd.Flags().Lookup("ssh-key"))
+	viper.BindPFlag("ssh-known-hosts", cmd.Flags().Lookup("ssh-known-hosts"))
+	viper.BindPFlag("metrics-addr", cmd.Flags().Lookup("metrics-addr"))
+	viper.BindPFlag("gzip-output", cmd.Flags().Lookup("gzip-output"))
+	viper.BindPFlag("output-encoding", cmd.Flags().Lookup("output-encoding"))
+	viper.BindPFlag("output-file", cmd.Flags().Lookup("output-file"))
+	viper.BindPFlag("append", cmd.Flags().Lookup("append"))
+	viper.BindPFlag("interactive", cmd.Flags().Lookup("interactive"))
+	viper.BindPFlag("ignore-directories", cmd.Flags().Lookup("ignore-directories"))
+	viper.BindPFlag("quiet-if-empty", cmd.Flags().Lookup("quiet-if-empty"))
+	viper.BindPFlag("color", cmd.Flags().Lookup("color"))
+	viper.BindPFlag("with-filename", cmd.Flags().Lookup("with-filename"))
+	viper.BindPFlag("header-realpath", cmd.Flags().Lookup("header-realpath"))
+	viper.BindPFlag("headers-to-stderr", cmd.Flags().Lookup("headers-to-stderr"))
+	viper.BindPFlag("filename-separator", cmd.Flags().Lookup("filename-separator"))
+	viper.BindPFlag("mark-live", cmd.Flags().Lookup("mark-live"))
 
 	return cmd
 }
@@ -130,6 +470,143 @@ func TestCLI_ReadFile(t *testing.T) {
 	}
 }
 
+func TestCLI_ConfigPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte("line1\nline2\nline3\nline4\nline5\n"), 0644)
+
+	// Isolate from any real user config, then provide a local one
+	t.Setenv("HOME", dir)
+	t.Setenv("USERPROFILE", dir)
+	t.Chdir(dir)
+	os.WriteFile(filepath.Join(dir, ".wail.yaml"), []byte("lines: 1\n"), 0644)
+
+	tests := []struct {
+		name string
+		env  string
+		args []string
+		want string
+	}{
+		{"config file", "", []string{testFile}, "line5\n"},
+		{"env overrides config", "2", []string{testFile}, "line4\nline5\n"},
+		{"flag overrides env", "2", []string{"-n", "3", testFile}, "line3\nline4\nline5\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv("WAIL_LINES", tt.env)
+			}
+
+			var out bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetArgs(tt.args)
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if got := out.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLI_FlagOverridesOtherCountKind(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte("line1\nline2\nline3\nline4\nline5\n"), 0644)
+
+	tests := []struct {
+		name string
+		env  string
+		val  string
+		args []string
+		want string
+	}{
+		{"-n beats configured bytes", "WAIL_BYTES", "3", []string{"-n", "2", testFile}, "line4\nline5\n"},
+		{"-c beats configured lines", "WAIL_LINES", "~1", []string{"-c", "3", testFile}, "e5\n"},
+		{"configured bytes alone", "WAIL_BYTES", "3", []string{testFile}, "e5\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.env, tt.val)
+
+			var out bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetArgs(tt.args)
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLI_ConfigsPassValidation(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte("line1\nline2\nline3\nline4\nline5\n"), 0644)
+
+	var configs []tail.TailerConfig
+	defer func(orig func(tail.TailerConfig) tail.Tailer) { newTailer = orig }(newTailer)
+	newTailer = func(config tail.TailerConfig) tail.Tailer {
+		configs = append(configs, config)
+		return tail.NewTailer(config)
+	}
+
+	tests := []struct {
+		name         string
+		env          string
+		args         []string
+		wantLinesSet bool
+	}{
+		{"default count", "", []string{testFile}, false},
+		{"-n", "", []string{"-n", "2", testFile}, true},
+		{"-n 0", "", []string{"-n", "0", testFile}, true},
+		{"configured lines", "3", []string{testFile}, true},
+		{"-c", "", []string{"-c", "3", testFile}, false},
+		{"-c with configured lines", "3", []string{"-c", "3", testFile}, false},
+		{"--head-tail", "", []string{"--head-tail", "1", testFile}, false},
+		{"--start-offset", "", []string{"--start-offset", "2", testFile}, false},
+		{"-c range", "", []string{"-c", "2:4", testFile}, false},
+		{"per-file count", "", []string{"-c", "3", testFile + ":n=2"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv("WAIL_LINES", tt.env)
+			}
+			configs = nil
+			cmd := newTestCmd()
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(tt.args)
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if len(configs) != 1 {
+				t.Fatalf("%d tailers created, want 1", len(configs))
+			}
+			if _, err := tail.NewTailerWithValidation(configs[0]); err != nil {
+				t.Errorf("config the flags built is invalid: %v", err)
+			}
+			if got := configs[0].LinesSet; got != tt.wantLinesSet {
+				t.Errorf("LinesSet = %v, want %v", got, tt.wantLinesSet)
+			}
+		})
+	}
+}
+
 func TestCLI_ReadStdinExplicit(t *testing.T) {
 	// Test reading from stdin using explicit "-" argument
 	input := "line1\nline2\nline3\nline4\nline5\n"
@@ -198,6 +675,56 @@ func TestCLI_ReadStdinPiped(t *testing.T) {
 	}
 }
 
+func TestCLI_FollowStdin(t *testing.T) {
+	oldStdin := os.Stdin
+	defer func() { os.Stdin = oldStdin }()
+	r, w, _ := os.Pipe()
+	os.Stdin = r
+
+	var out syncBuffer
+	cmd := newTestCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--follow-stdin", "-n", "2", "-s", "0.05"})
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Execute()
+	}()
+
+	// Each line after the first interval shows up while stdin is still open
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for string(out.Bytes()) != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("output = %q, want %q", out.Bytes(), want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	w.WriteString("line1\nline2\nline3\n")
+	waitFor("line2\nline3\n")
+	w.WriteString("line4\n")
+	waitFor("line2\nline3\nline4\n")
+
+	w.Close()
+	if err := <-done; err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+}
+
+func TestCLI_FollowStdinRefusesWholeInputModes(t *testing.T) {
+	for _, args := range [][]string{{"-c", "10"}, {"-n", "~2"}, {"--head-tail", "2"}} {
+		cmd := newTestCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append([]string{"--follow-stdin", "-"}, args...))
+		err := cmd.Execute()
+		if err == nil || !strings.Contains(err.Error(), "cannot combine --follow-stdin") {
+			t.Errorf("%v: Execute() error = %v, want a combination error", args, err)
+		}
+	}
+}
+
 func TestCLI_MultipleFiles(t *testing.T) {
 	dir := t.TempDir()
 	file1 := filepath.Join(dir, "file1.txt")
@@ -230,93 +757,786 @@ func TestCLI_MultipleFiles(t *testing.T) {
 	}
 }
 
-func TestCLI_BytesMode(t *testing.T) {
+func TestCLI_NoHeaderSpacing(t *testing.T) {
 	dir := t.TempDir()
-	testFile := filepath.Join(dir, "test.txt")
-	os.WriteFile(testFile, []byte("0123456789"), 0644)
-
-	var out bytes.Buffer
-	cmd := newTestCmd()
-	cmd.SetOut(&out)
-	cmd.SetArgs([]string{"-c", "5", testFile})
+	var files []string
+	for _, name := range []string{"a", "b", "c"} {
+		file := filepath.Join(dir, name+".txt")
+		os.WriteFile(file, []byte(name+"1\n"), 0644)
+		files = append(files, file)
+	}
+	compact := "==> " + files[0] + " <==\na1\n==> " + files[1] + " <==\nb1\n==> " + files[2] + " <==\nc1\n"
+	spaced := "==> " + files[0] + " <==\na1\n\n==> " + files[1] + " <==\nb1\n\n==> " + files[2] + " <==\nc1\n"
 
-	if err := cmd.Execute(); err != nil {
-		t.Fatalf("Execute() error = %v", err)
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"spaced by default", files, spaced},
+		{"back to back", append([]string{"--no-header-spacing"}, files...), compact},
+		{"totals footer too", append([]string{"--no-header-spacing", "--totals"}, files...), compact + "==> total <==\n3 lines, 9 bytes\n"},
 	}
 
-	got := out.String()
-	if got != "56789" {
-		t.Errorf("got %q, want %q", got, "56789")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetArgs(tt.args)
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
 	}
 }
 
-func TestCLI_FromStart(t *testing.T) {
-	dir := t.TempDir()
-	testFile := filepath.Join(dir, "test.txt")
-	os.WriteFile(testFile, []byte("line1\nline2\nline3\nline4\nline5\n"), 0644)
-
+func TestPrefixWriter_Compact(t *testing.T) {
+	// With no gap, as for --no-header-spacing, headers follow output directly
 	var out bytes.Buffer
-	cmd := newTestCmd()
-	cmd.SetOut(&out)
-	cmd.SetArgs([]string{"-n", "+3", testFile}) // Start from line 3
+	var mu sync.Mutex
+	last := ""
+	a := &prefixWriter{out: &out, w: &out, prefix: "a.log", mu: &mu, lastPrinted: &last}
+	b := &prefixWriter{out: &out, w: &out, prefix: "b.log", mu: &mu, lastPrinted: &last}
+	a.Write([]byte("a1\n"))
+	b.Write([]byte("b1\n"))
+	a.Write([]byte("a2\n"))
+
+	want := "==> a.log <==\na1\n==> b.log <==\nb1\n==> a.log <==\na2\n"
+	if got := out.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
 
-	if err := cmd.Execute(); err != nil {
-		t.Fatalf("Execute() error = %v", err)
+func TestHumanBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 bytes"},
+		{1023, "1023 bytes"},
+		{1024, "1.0 KiB"},
+		{5000, "4.9 KiB"},
+		{5120, "5.0 KiB"},
+		{3 * 1024 * 1024, "3.0 MiB"},
+		{1536 * 1024 * 1024, "1.5 GiB"},
 	}
 
-	got := out.String()
-	want := "line3\nline4\nline5\n"
-	if got != want {
-		t.Errorf("got %q, want %q", got, want)
+	for _, tt := range tests {
+		if got := humanBytes(tt.n); got != tt.want {
+			t.Errorf("humanBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
 	}
 }
 
-func TestCLI_QuietMode(t *testing.T) {
-	dir := t.TempDir()
-	file1 := filepath.Join(dir, "file1.txt")
-	file2 := filepath.Join(dir, "file2.txt")
-	os.WriteFile(file1, []byte("a1\n"), 0644)
-	os.WriteFile(file2, []byte("b1\n"), 0644)
-
-	var out bytes.Buffer
-	cmd := newTestCmd()
-	cmd.SetOut(&out)
-	cmd.SetArgs([]string{"-q", file1, file2})
+func TestCLI_VerboseByteCount(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "app.log")
+	os.WriteFile(testFile, []byte("hello\n"), 0644)
 
-	if err := cmd.Execute(); err != nil {
-		t.Fatalf("Execute() error = %v", err)
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-c", "5K", "-v"}, "==> " + testFile + " <== (last 5.0 KiB)\nhello\n"},
+		{[]string{"-c", "5KB", "-v"}, "==> " + testFile + " <== (last 4.9 KiB)\nhello\n"},
+		{[]string{"-c", "+1", "-v"}, "==> " + testFile + " <==\nhello\n"},
+		{[]string{"-n", "5", "-v"}, "==> " + testFile + " <==\nhello\n"},
 	}
 
-	got := out.String()
-	// Should NOT have headers with -q
-	if strings.Contains(got, "==>") {
-		t.Errorf("should not have headers with -q, got: %q", got)
+	for _, tt := range tests {
+		var out bytes.Buffer
+		cmd := newTestCmd()
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append(tt.args, testFile))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute(%q) error = %v", tt.args, err)
+		}
+		if got := out.String(); got != tt.want {
+			t.Errorf("Execute(%q) output = %q, want %q", tt.args, got, tt.want)
+		}
 	}
 }
 
-func TestCLI_VerboseMode(t *testing.T) {
+func TestCLI_HeadersToStderr(t *testing.T) {
 	dir := t.TempDir()
-	testFile := filepath.Join(dir, "test.txt")
-	os.WriteFile(testFile, []byte("line1\n"), 0644)
-
-	var out bytes.Buffer
-	cmd := newTestCmd()
-	cmd.SetOut(&out)
-	cmd.SetArgs([]string{"-v", testFile})
+	file1 := filepath.Join(dir, "a.txt")
+	file2 := filepath.Join(dir, "b.txt")
+	os.WriteFile(file1, []byte("a1\n"), 0644)
+	os.WriteFile(file2, []byte("b1\nb2\n"), 0644)
 
-	if err := cmd.Execute(); err != nil {
-		t.Fatalf("Execute() error = %v", err)
+	tests := []struct {
+		name       string
+		args       []string
+		wantOut    string
+		wantStderr string
+	}{
+		{"headers", nil, "a1\nb1\nb2\n", "==> " + file1 + " <==\n\n==> " + file2 + " <==\n"},
+		{"totals footer", []string{"--totals"}, "a1\nb1\nb2\n3 lines, 9 bytes\n", "==> " + file1 + " <==\n\n==> " + file2 + " <==\n\n==> total <==\n"},
+		{"counts", []string{"--count"}, "1\n2\n", "==> " + file1 + " <==\n\n==> " + file2 + " <==\n"},
 	}
 
-	got := out.String()
-	// Should have header even for single file with -v
-	if !strings.Contains(got, "==> "+testFile+" <==") {
-		t.Errorf("should have header with -v, got: %q", got)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, stderr bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs(append(append([]string{"--headers-to-stderr"}, tt.args...), file1, file2))
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("stdout = %q, want %q", got, tt.wantOut)
+			}
+			if got := stderr.String(); got != tt.wantStderr {
+				t.Errorf("stderr = %q, want %q", got, tt.wantStderr)
+			}
+		})
 	}
 }
 
-func TestCLI_FromStartStdin(t *testing.T) {
-	// Test +N syntax with stdin (start from line N)
+func TestCLI_NullHeaders(t *testing.T) {
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "a.txt")
+	file2 := filepath.Join(dir, "b.txt")
+	os.WriteFile(file1, []byte("a1\n"), 0644)
+	os.WriteFile(file2, []byte("b1\nb2\n"), 0644)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"two files", []string{"-0", file1, file2}, file1 + "\x00a1\n" + file2 + "\x00b1\nb2\n"},
+		{"verbose single file", []string{"--null-headers", "-v", file1}, file1 + "\x00a1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(tt.args)
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+			if strings.Contains(out.String(), "==>") {
+				t.Errorf("output = %q, want no decorative header", out.String())
+			}
+		})
+	}
+
+	cmd := newTestCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"-0", "--totals", file1, file2})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "cannot combine -0") {
+		t.Errorf("-0 --totals: error = %v, want cannot combine", err)
+	}
+}
+
+func TestPrefixWriter_NullHeaders(t *testing.T) {
+	// Following several files, -0 headers carry no gap or arrows either
+	var out bytes.Buffer
+	var mu sync.Mutex
+	last := ""
+	a := &prefixWriter{out: &out, w: &out, prefix: "a.log", mu: &mu, lastPrinted: &last, gap: "", null: true}
+	b := &prefixWriter{out: &out, w: &out, prefix: "b.log", mu: &mu, lastPrinted: &last, gap: "", null: true}
+	a.Write([]byte("a1\n"))
+	b.Write([]byte("b1\n"))
+	a.Write([]byte("a2\n"))
+
+	want := "a.log\x00a1\nb.log\x00b1\na.log\x00a2\n"
+	if got := out.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestPrefixWriter_SeparateHeaderStream(t *testing.T) {
+	// As for --headers-to-stderr when following, headers go to their own
+	// stream, still only when the source changes
+	var out, headers bytes.Buffer
+	var mu sync.Mutex
+	last := ""
+	a := &prefixWriter{out: &headers, w: &out, prefix: "a.log", mu: &mu, lastPrinted: &last, gap: "\n"}
+	b := &prefixWriter{out: &headers, w: &out, prefix: "b.log", mu: &mu, lastPrinted: &last, gap: "\n"}
+	a.Write([]byte("a1\n"))
+	a.Write([]byte("a2\n"))
+	b.Write([]byte("b1\n"))
+
+	if got, want := out.String(), "a1\na2\nb1\n"; got != want {
+		t.Errorf("content = %q, want %q", got, want)
+	}
+	if got, want := headers.String(), "\n==> a.log <==\n\n==> b.log <==\n"; got != want {
+		t.Errorf("headers = %q, want %q", got, want)
+	}
+}
+
+func TestCLI_HeaderRealpath(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "real"), 0755)
+	target := filepath.Join(dir, "real", "app.log")
+	os.WriteFile(target, []byte("line1\n"), 0644)
+	if err := os.Symlink(target, filepath.Join(dir, "link.log")); err != nil {
+		t.Skipf("can't create symlinks here: %v", err)
+	}
+	resolved, err := filepath.EvalSymlinks(target)
+	if err != nil {
+		t.Fatalf("EvalSymlinks() error: %v", err)
+	}
+	t.Chdir(dir)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"as given", []string{"real/app.log", "link.log"}, "==> real/app.log <==\nline1\n\n==> link.log <==\nline1\n"},
+		{"headers", []string{"--header-realpath", "real/app.log", "link.log"}, "==> " + resolved + " <==\nline1\n\n==> " + resolved + " <==\nline1\n"},
+		{"prefixes", []string{"--header-realpath", "--with-filename", "link.log"}, resolved + ": line1\n"},
+		{"missing file shown as given", []string{"--header-realpath", "-v", "gone.log"}, "==> gone.log <==\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(tt.args)
+
+			cmd.Execute() // gone.log fails, which is beside the point
+			if got := out.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLI_WithFilename(t *testing.T) {
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "file1.txt")
+	file2 := filepath.Join(dir, "file2.txt")
+	os.WriteFile(file1, []byte("a1\na2\n"), 0644)
+	os.WriteFile(file2, []byte("b1\n"), 0644)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"default separator", []string{"--with-filename"}, file1 + ": a1\n" + file1 + ": a2\n" + file2 + ": b1\n"},
+		{"custom separator", []string{"--with-filename", "--filename-separator", "|"}, file1 + "|a1\n" + file1 + "|a2\n" + file2 + "|b1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetArgs(append(tt.args, file1, file2))
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveColor(t *testing.T) {
+	tests := []struct {
+		mode     string
+		noColor  bool
+		terminal bool
+		want     bool
+	}{
+		{"auto", false, true, true},
+		{"auto", false, false, false},
+		{"auto", true, true, false},
+		{"always", true, false, true},
+		{"never", false, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/noColor=%v/terminal=%v", tt.mode, tt.noColor, tt.terminal), func(t *testing.T) {
+			got, err := resolveColor(tt.mode, tt.noColor, tt.terminal)
+			if err != nil {
+				t.Fatalf("resolveColor() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveColor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := resolveColor("sometimes", false, true); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+}
+
+func TestCLI_NoColor(t *testing.T) {
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "file1.txt")
+	file2 := filepath.Join(dir, "file2.txt")
+	os.WriteFile(file1, []byte("a1\n"), 0644)
+	os.WriteFile(file2, []byte("b1\n"), 0644)
+	t.Setenv("NO_COLOR", "1")
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantColor bool
+	}{
+		{"auto", []string{"--color=auto"}, false},
+		{"prefixes", []string{"--color=auto", "--with-filename"}, false},
+		{"always overrides", []string{"--color=always"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetArgs(append(tt.args, file1, file2))
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := strings.Contains(out.String(), "\x1b["); got != tt.wantColor {
+				t.Errorf("escapes in output = %v, want %v: %q", got, tt.wantColor, out.String())
+			}
+		})
+	}
+}
+
+func TestLinePrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	lw := &linePrefixWriter{out: &out, w: &out, prefix: "app.log: ", delim: '\n', mu: &sync.Mutex{}}
+
+	// Several lines in one write, then a line split across two
+	lw.Write([]byte("one\ntwo\nthr"))
+	lw.Write([]byte("ee\nfour\n"))
+
+	want := "app.log: one\napp.log: two\napp.log: three\napp.log: four\n"
+	if got := out.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCLI_Totals(t *testing.T) {
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "file1.txt")
+	file2 := filepath.Join(dir, "file2.txt")
+	os.WriteFile(file1, []byte("a1\na2\na3\n"), 0644)
+	os.WriteFile(file2, []byte("b1\nb2\n"), 0644)
+
+	var out bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--totals", file1, file2})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	got := out.String()
+	// 3 + 2 lines, 9 + 6 bytes; headers are not counted
+	if !strings.HasSuffix(got, "\n==> total <==\n5 lines, 15 bytes\n") {
+		t.Errorf("missing or wrong totals footer, got: %q", got)
+	}
+}
+
+func TestCLI_Count(t *testing.T) {
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "file1.txt")
+	file2 := filepath.Join(dir, "file2.txt")
+	os.WriteFile(file1, []byte("error 1\ninfo\nerror 2\n"), 0644)
+	os.WriteFile(file2, []byte("info\nerror 3\n"), 0644)
+
+	tests := []struct {
+		name  string
+		flags []string
+		want  string
+	}{
+		{"lines", []string{"-n", "+1", file1}, "3\n"},
+		{"window", []string{"-n", "2", file1}, "2\n"},
+		{"bytes", []string{"-c", "5", file1}, "5\n"},
+		{"grep", []string{"-n", "+1", "--grep", "error", file1}, "2\n"},
+		{"grep no match", []string{"-n", "+1", "--grep", "fatal", file1}, "0\n"},
+		{"headers and totals", []string{"-n", "+1", "--grep", "error", "--totals", file1, file2},
+			"==> " + file1 + " <==\n2\n\n==> " + file2 + " <==\n1\n\n==> total <==\n3\n"},
+		{"quiet", []string{"-q", "-n", "+1", "--totals", file1, file2}, "3\n2\n"},
+		{"with-filename", []string{"-n", "+1", "--with-filename", file1}, file1 + ": 3\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetArgs(append([]string{"--count"}, tt.flags...))
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLI_CountRefusesContext(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "test.log")
+	os.WriteFile(file, []byte("a\n"), 0644)
+
+	cmd := newTestCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--count", "--grep", "a", "-C", "1", file})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "cannot combine --count") {
+		t.Errorf("Execute() error = %v, want it to refuse --count with context lines", err)
+	}
+}
+
+func TestCLI_QuietIfEmpty(t *testing.T) {
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "file1.txt")
+	empty := filepath.Join(dir, "empty.txt")
+	file2 := filepath.Join(dir, "file2.txt")
+	os.WriteFile(file1, []byte("a1\n"), 0644)
+	os.WriteFile(empty, nil, 0644)
+	os.WriteFile(file2, []byte("b1\n"), 0644)
+
+	tests := []struct {
+		name  string
+		flags []string
+		want  string
+	}{
+		{"default", nil, "==> " + file1 + " <==\na1\n\n==> " + file2 + " <==\nb1\n"},
+		{"verbose", []string{"-v"}, "==> " + file1 + " <==\na1\n\n==> " + empty + " <==\n\n==> " + file2 + " <==\nb1\n"},
+		{"verbose quiet-if-empty", []string{"-v", "--quiet-if-empty"}, "==> " + file1 + " <==\na1\n\n==> " + file2 + " <==\nb1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetArgs(append(tt.flags, file1, empty, file2))
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLI_DirectoryArgument(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   []string
+		wantErr string
+		wantRun error
+	}{
+		{"reported", nil, ": Is a directory\n", errFileFailed},
+		{"ignored", []string{"--ignore-directories"}, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			subdir := filepath.Join(dir, "logs")
+			os.Mkdir(subdir, 0755)
+			file := filepath.Join(dir, "app.log")
+			os.WriteFile(file, []byte("hello\n"), 0644)
+
+			var out, errOut bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetErr(&errOut)
+			cmd.SetArgs(append(tt.flags, subdir, file))
+
+			if err := cmd.Execute(); !errors.Is(err, tt.wantRun) {
+				t.Fatalf("Execute() error = %v, want %v", err, tt.wantRun)
+			}
+
+			if !strings.Contains(out.String(), "hello\n") {
+				t.Errorf("expected output from the remaining file, got %q", out.String())
+			}
+			if tt.wantErr == "" {
+				if errOut.Len() != 0 {
+					t.Errorf("expected no stderr, got %q", errOut.String())
+				}
+			} else if got := errOut.String(); got != "wail: "+subdir+tt.wantErr {
+				t.Errorf("stderr = %q, want %q", got, "wail: "+subdir+tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCLI_ZeroLines(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte("a\nb\nc\n"), 0644)
+
+	var out bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"-n", "0", testFile})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no output for -n 0, got %q", out.String())
+	}
+}
+
+func TestCLI_ZeroBytes(t *testing.T) {
+	// More lines than the default 10, so falling back to line mode shows
+	var content strings.Builder
+	for i := 1; i <= 12; i++ {
+		fmt.Fprintf(&content, "line%d\n", i)
+	}
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte(content.String()), 0644)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"last 0 bytes", []string{"-c", "0", testFile}, ""},
+		{"from byte 0", []string{"-c", "+0", testFile}, content.String()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetArgs(tt.args)
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLI_BytesMode(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte("0123456789"), 0644)
+
+	var out bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"-c", "5", testFile})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	got := out.String()
+	if got != "56789" {
+		t.Errorf("got %q, want %q", got, "56789")
+	}
+}
+
+func TestCLI_FromStart(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte("line1\nline2\nline3\nline4\nline5\n"), 0644)
+
+	var out bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"-n", "+3", testFile}) // Start from line 3
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	got := out.String()
+	want := "line3\nline4\nline5\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCLI_AllButLast(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte("line1\nline2\nline3\nline4\nline5\n"), 0644)
+
+	var out bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"-n", "~2", testFile}) // Drop the last 2 lines
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	got := out.String()
+	want := "line1\nline2\nline3\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCLI_LinesAndBytes(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte("line1\nline2\n"), 0644)
+
+	var out bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"-n", "5", "-c", "10", testFile})
+
+	err := cmd.Execute()
+	if err == nil {
+		t.Fatal("expected error combining -n with -c")
+	}
+	if !strings.Contains(err.Error(), "cannot combine -n and -c") {
+		t.Errorf("expected 'cannot combine' error, got: %v", err)
+	}
+}
+
+func TestCLI_StartOffset(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte("line1\nline2\n"), 0644)
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{"offset", []string{"--start-offset", "6"}, "line2\n", ""},
+		{"with -n", []string{"--start-offset", "6", "-n", "1"}, "", "cannot combine --start-offset"},
+		{"past the end", []string{"--start-offset", "50"}, "", "past the end"},
+		{"past the end, clamped", []string{"--start-offset", "50", "--clamp-offset"}, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetErr(&errOut)
+			cmd.SetArgs(append(tt.args, testFile))
+
+			// Per-file failures are reported on stderr
+			err := cmd.Execute()
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("expected error containing %q", tt.wantErr)
+				}
+				if msg := err.Error() + errOut.String(); !strings.Contains(msg, tt.wantErr) {
+					t.Errorf("error %q does not contain %q", msg, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLI_AllButLastWithFollow(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte("line1\n"), 0644)
+
+	var out bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"-n", "~2", "-f", testFile})
+
+	err := cmd.Execute()
+	if err == nil {
+		t.Fatal("expected error combining -n ~N with -f")
+	}
+	if !strings.Contains(err.Error(), "cannot follow") {
+		t.Errorf("expected 'cannot follow' error, got: %v", err)
+	}
+}
+
+func TestCLI_QuietMode(t *testing.T) {
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "file1.txt")
+	file2 := filepath.Join(dir, "file2.txt")
+	os.WriteFile(file1, []byte("a1\n"), 0644)
+	os.WriteFile(file2, []byte("b1\n"), 0644)
+
+	var out bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"-q", file1, file2})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	got := out.String()
+	// Should NOT have headers with -q
+	if strings.Contains(got, "==>") {
+		t.Errorf("should not have headers with -q, got: %q", got)
+	}
+}
+
+func TestCLI_VerboseMode(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte("line1\n"), 0644)
+
+	var out bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"-v", testFile})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	got := out.String()
+	// Should have header even for single file with -v
+	if !strings.Contains(got, "==> "+testFile+" <==") {
+		t.Errorf("should have header with -v, got: %q", got)
+	}
+}
+
+func TestCLI_FromStartStdin(t *testing.T) {
+	// Test +N syntax with stdin (start from line N)
 	input := "line1\nline2\nline3\nline4\nline5\n"
 
 	oldStdin := os.Stdin
@@ -353,13 +1573,55 @@ func TestCLI_NonExistentFile(t *testing.T) {
 	cmd.SetErr(&errOut)
 	cmd.SetArgs([]string{"/nonexistent/file.txt"})
 
-	// Should not return error (matches tail behavior - prints error to stderr but continues)
-	cmd.Execute()
+	// Prints the error to stderr and exits 1, like tail
+	if err := cmd.Execute(); !errors.Is(err, errFileFailed) {
+		t.Errorf("Execute() error = %v, want errFileFailed", err)
+	}
 
-	// Should have error message in stderr
-	if !strings.Contains(errOut.String(), "wail:") {
+	// Should have error message in stderr, reported once
+	if !strings.HasPrefix(errOut.String(), "wail:") {
 		t.Errorf("expected error in stderr, got: %q", errOut.String())
 	}
+	if strings.Contains(errOut.String(), "Error:") || strings.Contains(errOut.String(), "Usage:") {
+		t.Errorf("cobra should not report the failure again, got: %q", errOut.String())
+	}
+}
+
+func TestCLI_NoHeaderForUnreadableFile(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing.log")
+	file := filepath.Join(dir, "app.log")
+	os.WriteFile(file, []byte("hello\n"), 0644)
+
+	var out, errOut bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetArgs([]string{missing, file})
+	cmd.Execute()
+
+	if got, want := out.String(), "==> "+file+" <==\nhello\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCLI_FailedFileStillOutputsOthers(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.log")
+	os.WriteFile(file, []byte("hello\n"), 0644)
+
+	var out, errOut bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetArgs([]string{filepath.Join(dir, "missing.log"), file})
+
+	if err := cmd.Execute(); !errors.Is(err, errFileFailed) {
+		t.Errorf("Execute() error = %v, want errFileFailed", err)
+	}
+	if !strings.Contains(out.String(), "hello\n") {
+		t.Errorf("expected output from the readable file, got: %q", out.String())
+	}
 }
 
 func TestCLI_BytesFromStart(t *testing.T) {
@@ -465,48 +1727,291 @@ func TestCLI_SizeSuffixes(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.arg, func(t *testing.T) {
+		t.Run(tt.arg, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetArgs([]string{"-c", tt.arg, testFile})
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			got := len(out.String())
+			if got > tt.want {
+				t.Errorf("got %d bytes, want at most %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLI_ZeroTerminated(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+
+	// Create file with NUL-delimited content
+	content := "line1\x00line2\x00line3\x00"
+	os.WriteFile(testFile, []byte(content), 0644)
+
+	var out bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"-z", "-n", "2", testFile})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	got := out.String()
+	// Should output last 2 NUL-delimited lines with NUL terminators
+	if !strings.Contains(got, "line2\x00") {
+		t.Errorf("expected 'line2' with NUL in output, got %q", got)
+	}
+	if !strings.Contains(got, "line3\x00") {
+		t.Errorf("expected 'line3' with NUL in output, got %q", got)
+	}
+}
+
+func TestCLI_OutputDelimiter(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte("line1\x00line2\x00line3\x00"), 0644)
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{"escape", []string{"-z", "--output-delimiter", `\n`}, "line2\nline3\n", ""},
+		{"literal", []string{"-z", "--output-delimiter", ","}, "line2,line3,", ""},
+		{"NUL escape", []string{"-z", "--output-delimiter", `\0`}, "line2\x00line3\x00", ""},
+		{"too long", []string{"-z", "--output-delimiter", "ab"}, "", "invalid output-delimiter value"},
+		{"empty", []string{"-z", "--output-delimiter", ""}, "", "invalid output-delimiter value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(append(tt.args, "-n", "2", testFile))
+
+			err := cmd.Execute()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Execute() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBinaryFiles(t *testing.T) {
+	tests := []struct {
+		value    string
+		text     bool
+		terminal bool
+		want     tail.BinaryFiles
+		wantErr  bool
+	}{
+		{"", false, true, tail.BinaryWarn, false},
+		{"", false, false, tail.BinaryText, false},
+		{"skip", false, true, tail.BinarySkip, false},
+		{"warn", false, false, tail.BinaryWarn, false},
+		{"text", false, true, tail.BinaryText, false},
+		{"warn", true, true, tail.BinaryText, false},
+		{"hide", false, false, 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseBinaryFiles(tt.value, tt.text, tt.terminal)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseBinaryFiles(%q, %v, %v) error = %v, wantErr %v", tt.value, tt.text, tt.terminal, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseBinaryFiles(%q, %v, %v) = %v, want %v", tt.value, tt.text, tt.terminal, got, tt.want)
+		}
+	}
+}
+
+func TestParseNewline(t *testing.T) {
+	tests := []struct {
+		value   string
+		keep    bool
+		want    tail.LineEnding
+		wantErr bool
+	}{
+		{"", false, tail.LineEndingLF, false},
+		{"", true, tail.LineEndingKeep, false},
+		{"lf", false, tail.LineEndingLF, false},
+		{"crlf", false, tail.LineEndingCRLF, false},
+		{"keep", true, tail.LineEndingKeep, false},
+		{"crlf", true, 0, true},
+		{"cr", false, 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseNewline(tt.value, tt.keep)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseNewline(%q, %v) error = %v, wantErr %v", tt.value, tt.keep, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseNewline(%q, %v) = %v, want %v", tt.value, tt.keep, got, tt.want)
+		}
+	}
+}
+
+func TestCLI_Newline(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "mixed.log")
+	os.WriteFile(testFile, []byte("a\r\nb\nc"), 0644)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "a\nb\nc\n"},
+		{[]string{"--newline", "crlf"}, "a\r\nb\r\nc\r\n"},
+		{[]string{"--newline", "keep"}, "a\r\nb\nc"},
+		{[]string{"-b"}, "a\r\nb\nc"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		cmd := newTestCmd()
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append(tt.args, testFile))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute(%q) error = %v", tt.args, err)
+		}
+		if got := out.String(); got != tt.want {
+			t.Errorf("Execute(%q) output = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestCLI_BinaryFiles(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "core.bin")
+	os.WriteFile(testFile, []byte("ELF\x00\x00\x01\n"), 0644)
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantOut string
+		wantErr string
+	}{
+		{"piped output defaults to text", nil, "ELF\x00\x00\x01\n", ""},
+		{"skip", []string{"--binary-files", "skip"}, "", ""},
+		{"warn", []string{"--binary-files", "warn"}, "", "wail: " + testFile + ": binary file; use --text to force\n"},
+		{"text overrides", []string{"--binary-files", "warn", "--text"}, "ELF\x00\x00\x01\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetErr(&errOut)
+			cmd.SetArgs(append(tt.args, testFile))
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("output = %q, want %q", got, tt.wantOut)
+			}
+			if got := errOut.String(); got != tt.wantErr {
+				t.Errorf("stderr = %q, want %q", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCLI_LinesOutputBuffer(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte("line1\nline2\n"), 0644)
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"line", "line", false},
+		{"batch", "batch", false},
+		{"interval", "200ms", false},
+		{"unknown mode", "block", true},
+		{"zero interval", "0s", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
 			var out bytes.Buffer
 			cmd := newTestCmd()
 			cmd.SetOut(&out)
-			cmd.SetArgs([]string{"-c", tt.arg, testFile})
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs([]string{"--lines-output-buffer", tt.value, testFile})
 
-			if err := cmd.Execute(); err != nil {
+			err := cmd.Execute()
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "invalid lines-output-buffer value") {
+					t.Fatalf("Execute() error = %v, want an invalid value error", err)
+				}
+				return
+			}
+			if err != nil {
 				t.Fatalf("Execute() error = %v", err)
 			}
-
-			got := len(out.String())
-			if got > tt.want {
-				t.Errorf("got %d bytes, want at most %d", got, tt.want)
+			// Whatever the mode, everything is written out by exit
+			if got := out.String(); got != "line1\nline2\n" {
+				t.Errorf("output = %q, want both lines", got)
 			}
 		})
 	}
 }
 
-func TestCLI_ZeroTerminated(t *testing.T) {
+func TestCLI_NoCRLFNormalize(t *testing.T) {
 	dir := t.TempDir()
-	testFile := filepath.Join(dir, "test.txt")
-
-	// Create file with NUL-delimited content
-	content := "line1\x00line2\x00line3\x00"
+	testFile := filepath.Join(dir, "crlf.log")
+	content := "first\r\nsecond\r\nthird\r\n"
 	os.WriteFile(testFile, []byte(content), 0644)
 
-	var out bytes.Buffer
-	cmd := newTestCmd()
-	cmd.SetOut(&out)
-	cmd.SetArgs([]string{"-z", "-n", "2", testFile})
-
-	if err := cmd.Execute(); err != nil {
-		t.Fatalf("Execute() error = %v", err)
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"normalized by default", nil, "first\nsecond\nthird\n"},
+		{"kept with the flag", []string{"--no-crlf-normalize"}, content},
+		{"kept with -b", []string{"-b"}, content},
 	}
 
-	got := out.String()
-	// Should output last 2 NUL-delimited lines with NUL terminators
-	if !strings.Contains(got, "line2\x00") {
-		t.Errorf("expected 'line2' with NUL in output, got %q", got)
-	}
-	if !strings.Contains(got, "line3\x00") {
-		t.Errorf("expected 'line3' with NUL in output, got %q", got)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetArgs(append(tt.args, testFile))
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
 	}
 }
 
@@ -589,3 +2094,491 @@ func TestCLI_NoFilesNoStdin(t *testing.T) {
 		t.Errorf("expected 'no files specified' error, got: %v", err)
 	}
 }
+
+func TestReportError_Hints(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantHint string
+	}{
+		{"sharing violation", fmt.Errorf("opening file: %w", filesystem.ErrSharingViolation), "file is locked"},
+		{"access denied", fmt.Errorf("opening file: %w", filesystem.ErrAccessDenied), "access denied"},
+		{"other error", errors.New("boom"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errOut bytes.Buffer
+			reportError(&errOut, "app.log", tt.err)
+
+			got := errOut.String()
+			if !strings.HasPrefix(got, "wail: app.log: ") {
+				t.Errorf("expected error line for app.log, got %q", got)
+			}
+			hasHint := strings.Contains(got, "hint:")
+			if tt.wantHint == "" && hasHint {
+				t.Errorf("expected no hint, got %q", got)
+			}
+			if tt.wantHint != "" && !strings.Contains(got, "hint: "+tt.wantHint) {
+				t.Errorf("expected hint %q, got %q", tt.wantHint, got)
+			}
+		})
+	}
+}
+
+func TestCLI_MetricsAddrInvalid(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte("line1\n"), 0644)
+
+	var out bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"--metrics-addr", "not an address", testFile})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "starting metrics server") {
+		t.Errorf("Execute() error = %v, want a metrics server error", err)
+	}
+}
+
+func TestCLI_RetryAttempts(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "never.log")
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"gives up", []string{"--retry", "--retry-attempts", "3", "-s", "0.01", missing}, "did not appear after 3 attempts"},
+		{"negative", []string{"--retry", "--retry-attempts", "-1", missing}, "invalid retry-attempts value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetErr(&out)
+			cmd.SetArgs(tt.args)
+
+			// Per-file failures are reported on stderr
+			err := cmd.Execute()
+			if err == nil || !strings.Contains(err.Error()+out.String(), tt.wantErr) {
+				t.Errorf("Execute() error = %v, output %q, want one containing %q", err, out.String(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCLI_InteractiveRefused(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte("line1\n"), 0644)
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"not a terminal", []string{"--interactive", testFile}, "needs a terminal"},
+		{"several files", []string{"--interactive", testFile, testFile}, "exactly one file"},
+		{"standard input", []string{"--interactive", "-"}, "exactly one file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetErr(&out)
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Execute() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCLI_GrepAndTriggers(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte("info: up\nERROR: disk\n"), 0644)
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{"grep", []string{"--grep", "^ERROR"}, "ERROR: disk\n", ""},
+		{"grep is case-sensitive", []string{"--grep", "error"}, "", ""},
+		{"ignore case", []string{"--grep", "error", "-i"}, "ERROR: disk\n", ""},
+		{"context", []string{"--grep", "ERROR", "-C", "1"}, "info: up\nERROR: disk\n", ""},
+		{"-B overrides -C", []string{"--grep", "ERROR", "-C", "1", "-B", "0"}, "ERROR: disk\n", ""},
+		{"context without grep", []string{"-C", "1"}, "", "need --grep"},
+		{"invalid pattern", []string{"--grep", "("}, "", "invalid grep pattern"},
+		{"grep with -c", []string{"--grep", "x", "-c", "5"}, "", "cannot combine --grep with -c"},
+		{"exec without follow", []string{"--exec", "notify {line}"}, "", "need -f or -F"},
+		{"bad exec command", []string{"-f", "--exec", `notify "{line}`}, "", "invalid exec command"},
+		{"bad webhook", []string{"-f", "--webhook", "ftp://example.com"}, "", "invalid webhook URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetErr(&errOut)
+			cmd.SetArgs(append(tt.args, testFile))
+
+			err := cmd.Execute()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Execute() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLI_TimeWindow(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "app.log")
+	os.WriteFile(testFile, []byte("2024-05-01T09:00:00Z starting\n2024-05-01T10:00:00Z ready\n  retrying\n2024-05-01T11:00:00Z stopping\n"), 0644)
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{"since", []string{"--since", "2024-05-01T10:00:00Z"}, "2024-05-01T10:00:00Z ready\n  retrying\n2024-05-01T11:00:00Z stopping\n", ""},
+		{"since dropping unparseable lines", []string{"--since", "2024-05-01T10:00:00Z", "--on-unparseable", "drop"}, "2024-05-01T10:00:00Z ready\n2024-05-01T11:00:00Z stopping\n", ""},
+		{"until", []string{"--until-time", "2024-05-01T10:30:00Z"}, "2024-05-01T09:00:00Z starting\n2024-05-01T10:00:00Z ready\n  retrying\n", ""},
+		{"since a date in the future", []string{"--since", "2999-01-01"}, "  retrying\n", ""},
+		{"invalid since", []string{"--since", "yesterday"}, "", "invalid since value"},
+		{"invalid on-unparseable", []string{"--since", "1h", "--on-unparseable", "keep"}, "", "invalid on-unparseable value"},
+		{"with -c", []string{"--since", "1h", "-c", "5"}, "", "cannot combine --since or --until-time with -c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(append(tt.args, testFile))
+
+			err := cmd.Execute()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Execute() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		layout string
+		want   time.Time
+	}{
+		{"", "", time.Time{}},
+		{"2024-05-01T10:00:00+02:00", "", time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)},
+		{"2024-05-01", "", time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)},
+		{"2024-05-01 10:30", "", time.Date(2024, 5, 1, 10, 30, 0, 0, time.Local)},
+		{"90m", "", time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)},
+		{"01/05/2024", "02/01/2006", time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTimeBound("since", tt.value, tt.layout, now)
+		if err != nil {
+			t.Errorf("parseTimeBound(%q) error: %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseTimeBound(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestCheckFileCount(t *testing.T) {
+	tests := []struct {
+		n, max  int
+		wantErr string
+	}{
+		{3, 3, ""},
+		{4, 3, "too many files: 4, limit 3; raise with --max-files"},
+		{5000, 0, ""},
+		{1, -1, "invalid max-files value"},
+	}
+
+	for _, tt := range tests {
+		err := checkFileCount(tt.n, tt.max, io.Discard)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("checkFileCount(%d, %d) error = %v", tt.n, tt.max, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("checkFileCount(%d, %d) error = %v, want %q", tt.n, tt.max, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCLI_MaxFiles(t *testing.T) {
+	dir := t.TempDir()
+	var args []string
+	for _, name := range []string{"a.log", "b.log", "c.log"} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte("line\n"), 0644)
+		args = append(args, path)
+	}
+
+	// Refused before any file is followed, so this doesn't block
+	cmd := newTestCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs(append([]string{"-f", "--max-files", "2"}, args...))
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "too many files: 3, limit 2") {
+		t.Errorf("Execute() error = %v, want a too many files error", err)
+	}
+}
+
+func TestCLI_DrainRotatedNeedsFollowName(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"--drain-rotated", "app.log.*"}, "--drain-rotated needs -F"},
+		{[]string{"-f", "--drain-rotated", "app.log.*"}, "--drain-rotated needs -F"},
+		{[]string{"-F", "--drain-rotated", "app.log.["}, "invalid drain-rotated pattern"},
+	}
+
+	for _, tt := range tests {
+		cmd := newTestCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append(tt.args, os.DevNull))
+		err := cmd.Execute()
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Execute(%q) error = %v, want one containing %q", tt.args, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCLI_WarnRotationNeedsFollow(t *testing.T) {
+	for _, args := range [][]string{{"--warn-rotation"}, {"-F", "--warn-rotation"}} {
+		cmd := newTestCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append(args, os.DevNull))
+		err := cmd.Execute()
+		if err == nil || !strings.Contains(err.Error(), "--warn-rotation needs -f") {
+			t.Errorf("Execute(%q) error = %v, want --warn-rotation needs -f", args, err)
+		}
+	}
+}
+
+func TestCLI_MaxMemory(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	var content strings.Builder
+	for i := 1; i <= 10; i++ {
+		fmt.Fprintf(&content, "%d %s\n", i, strings.Repeat("x", 10*1024))
+	}
+	os.WriteFile(testFile, []byte(content.String()), 0644)
+
+	var stdout bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"-n", "2", "--max-memory", "1K", testFile})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	want := "9 " + strings.Repeat("x", 10*1024) + "\n10 " + strings.Repeat("x", 10*1024) + "\n"
+	if stdout.String() != want {
+		t.Errorf("output = %.40q..., want the last 2 lines", stdout.String())
+	}
+
+	for _, arg := range []string{"-1K", "+1K", "lots"} {
+		cmd := newTestCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"--max-memory", arg, testFile})
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "invalid max-memory value") {
+			t.Errorf("--max-memory %s: error = %v, want invalid max-memory value", arg, err)
+		}
+	}
+}
+
+func TestCLI_DryRun(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.log", "b.log", "c.txt"} {
+		os.WriteFile(filepath.Join(dir, name), []byte("line\n"), 0644)
+	}
+	os.Mkdir(filepath.Join(dir, "old.log"), 0755)
+	manifest := filepath.Join(dir, "files.txt")
+	os.WriteFile(manifest, []byte(filepath.Join(dir, "*.log")+"\n"), 0644)
+	outFile := filepath.Join(dir, "copy.txt")
+
+	var stdout bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--dry-run", "--ignore-directories", "-F", "-n", "5", "--output-file", outFile, "--files-from", manifest})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// The glob is expanded, and the directory it matches skipped
+	want := "files (2):\n" +
+		"  " + filepath.Join(dir, "a.log") + "\n" +
+		"  " + filepath.Join(dir, "b.log") + "\n" +
+		"mode: last 5 lines\n" +
+		"follow: by name, polling every 100ms\n" +
+		"labels: headers\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	if _, err := os.Stat(outFile); !os.IsNotExist(err) {
+		t.Errorf("--output-file was created by a dry run (stat error = %v)", err)
+	}
+}
+
+func TestCLI_ByteRange(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte("0123456789"), 0644)
+
+	tests := []struct {
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{[]string{"-c", "3:7"}, "23456", ""},
+		{[]string{"-c", "8:100"}, "789", ""},
+		{[]string{"-c", "1:1K"}, "0123456789", ""},
+		{[]string{"-c", "7:3"}, "", "starts after it ends"},
+		{[]string{"-c", "3:"}, "", "invalid byte range"},
+		{[]string{"-c", ":7"}, "", "invalid byte range"},
+		{[]string{"-c", "+3:7"}, "", "invalid byte range"},
+		{[]string{"-c", "3:7:9"}, "", "invalid bytes value"},
+		{[]string{"-f", "-c", "3:7"}, "", "cannot follow a byte range"},
+	}
+
+	for _, tt := range tests {
+		var stdout bytes.Buffer
+		cmd := newTestCmd()
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append(tt.args, testFile))
+		err := cmd.Execute()
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%v: error = %v, want one containing %q", tt.args, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: error = %v", tt.args, err)
+			continue
+		}
+		if got := stdout.String(); got != tt.want {
+			t.Errorf("%v: output = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestCLI_HeadTail(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte("1\n2\n3\n4\n5\n"), 0644)
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{"head and tail", []string{"--head-tail", "2"}, "1\n2\n... (1 line omitted) ...\n4\n5\n", ""},
+		{"with -c", []string{"--head-tail", "2", "-c", "3"}, "", "cannot combine --head-tail"},
+		{"with -f", []string{"--head-tail", "2", "-f"}, "", "cannot follow with --head-tail"},
+		{"zero", []string{"--head-tail", "0"}, "", "invalid head-tail value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetErr(&errOut)
+			cmd.SetArgs(append(tt.args, testFile))
+
+			err := cmd.Execute()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Execute() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLI_DevStdin(t *testing.T) {
+	// /dev/stdin is read as "-", so it works (and is named) the same
+	// everywhere, Windows included
+	oldStdin := os.Stdin
+	defer func() { os.Stdin = oldStdin }()
+
+	r, w, _ := os.Pipe()
+	os.Stdin = r
+	go func() {
+		w.WriteString("line1\nline2\n")
+		w.Close()
+	}()
+
+	var out bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"-n", "1", "-v", "/dev/stdin"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got, want := out.String(), "==> standard input <==\nline2\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// fileTee copies output to the --output-file. Writes are buffered, and
+// serialised since writers following several files share it. A failed write
+// is reported to stderr once and the file is given up on, so the stream to
+// stdout carries on regardless.
+type fileTee struct {
+	mu     sync.Mutex
+	f      *os.File
+	w      *bufio.Writer
+	stderr io.Writer
+	failed bool
+}
+
+// openFileTee creates or truncates path, or with appendTo, appends to it.
+func openFileTee(path string, appendTo bool, stderr io.Writer) (*fileTee, error) {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if appendTo {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening output file: %w", err)
+	}
+	return &fileTee{f: f, w: bufio.NewWriter(f), stderr: stderr}, nil
+}
+
+// Write never fails, so an io.MultiWriter with stdout keeps going when the
+// file can't be written.
+func (t *fileTee) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.failed {
+		if _, err := t.w.Write(p); err != nil {
+			t.fail(err)
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes out what is buffered.
+func (t *fileTee) Flush() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.failed {
+		if err := t.w.Flush(); err != nil {
+			t.fail(err)
+		}
+	}
+}
+
+// fail reports err and stops writing to the file. t.mu must be held.
+func (t *fileTee) fail(err error) {
+	t.failed = true
+	fmt.Fprintf(t.stderr, "wail: %s: %v (no longer copying output to it)\n", t.f.Name(), err)
+}
+
+// flushEvery flushes once per interval until ctx is cancelled, so the file
+// keeps up with followed lines.
+func (t *fileTee) flushEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.Flush()
+		}
+	}
+}
+
+// Close flushes what is left and closes the file.
+func (t *fileTee) Close() error {
+	t.Flush()
+	return t.f.Close()
+}
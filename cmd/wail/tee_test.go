@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileTee_WriteFailure(t *testing.T) {
+	var stderr bytes.Buffer
+	tee, err := openFileTee(filepath.Join(t.TempDir(), "out.log"), false, &stderr)
+	if err != nil {
+		t.Fatalf("openFileTee() error: %v", err)
+	}
+	tee.f.Close() // Every write to the file fails from here on
+
+	var stdout bytes.Buffer
+	w := io.MultiWriter(&stdout, tee)
+	for _, line := range []string{"one\n", "two\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error: %v", err)
+		}
+		tee.Flush()
+	}
+
+	if got := stdout.String(); got != "one\ntwo\n" {
+		t.Errorf("stdout = %q, want both lines", got)
+	}
+	if got := strings.Count(stderr.String(), "no longer copying output"); got != 1 {
+		t.Errorf("stderr = %q, want the failure reported once", stderr.String())
+	}
+}
+
+func TestCLI_OutputFile(t *testing.T) {
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "a.log")
+	file2 := filepath.Join(dir, "b.log")
+	os.WriteFile(file1, []byte("a1\na2\n"), 0644)
+	os.WriteFile(file2, []byte("b1\n"), 0644)
+
+	tests := []struct {
+		name   string
+		append bool
+		prefix string // What the output file keeps of what it held before
+	}{
+		{"overwrite", false, ""},
+		{"append", true, "earlier\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outFile := filepath.Join(t.TempDir(), "capture.log")
+			os.WriteFile(outFile, []byte("earlier\n"), 0644)
+
+			args := []string{"--output-file", outFile, file1, file2}
+			if tt.append {
+				args = append([]string{"--append"}, args...)
+			}
+			var out bytes.Buffer
+			cmd := newTestCmd()
+			cmd.SetOut(&out)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(args)
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			// Headers and all, the file gets what stdout does
+			if !strings.Contains(out.String(), "==> "+file2+" <==\nb1\n") {
+				t.Fatalf("stdout = %q, want both files with headers", out.String())
+			}
+			got, err := os.ReadFile(outFile)
+			if err != nil {
+				t.Fatalf("reading output file: %v", err)
+			}
+			if want := tt.prefix + out.String(); string(got) != want {
+				t.Errorf("output file = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// terminalWidth returns the number of columns in the visible window of the
+// console f refers to.
+func terminalWidth(f *os.File) (int, bool) {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(f.Fd()), &info); err != nil {
+		return 0, false
+	}
+	return int(info.Window.Right-info.Window.Left) + 1, true
+}
+
+// watchResize is a no-op on Windows, which has no resize signal; the width
+// is read once at startup.
+func watchResize(ctx context.Context, fn func()) {}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version, commit, build date and platform",
+	Long: `Print the full build information for wail: the version, the commit and
+date it was built from, the Go version it was built with, and the OS and
+architecture it was built for. Include this in bug reports.`,
+	Args: cobra.NoArgs,
+	// The config file has nothing to say about the version, and a broken one
+	// shouldn't stop it being reported
+	PersistentPreRun: func(*cobra.Command, []string) {},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printVersion(cmd.OutOrStdout(), "full")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+}
+
+// printVersion writes the version for --version: the version alone for mode
+// "short", as cobra's own --version did, or the full build information for
+// "full".
+func printVersion(w io.Writer, mode string) error {
+	switch mode {
+	case "short":
+		_, err := fmt.Fprintf(w, "wail version %s\n", version)
+		return err
+	case "full":
+		_, err := fmt.Fprintf(w, "wail version %s\ncommit:   %s\nbuilt:    %s\ngo:       %s\nplatform: %s/%s\n",
+			version, commit, date, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+		return err
+	}
+	return fmt.Errorf("invalid version value: %s (use 'short' or 'full')", mode)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestVersion_Subcommand(t *testing.T) {
+	defer func(v, c string) { version, commit = v, c }(version, commit)
+	version, commit = "1.2.3", "abc1234"
+
+	for _, args := range [][]string{{"version"}, {"--version=full"}} {
+		var out bytes.Buffer
+		rootCmd.SetOut(&out)
+		rootCmd.SetArgs(args)
+		err := rootCmd.Execute()
+		rootCmd.SetOut(nil)
+		rootCmd.Flags().Lookup("version").Changed = false
+		if err != nil {
+			t.Fatalf("%v: Execute() error = %v", args, err)
+		}
+
+		got := out.String()
+		for _, want := range []string{"wail version 1.2.3\n", "commit:   abc1234\n", runtime.Version(), runtime.GOOS + "/" + runtime.GOARCH} {
+			if !strings.Contains(got, want) {
+				t.Errorf("%v: output = %q, want it to contain %q", args, got, want)
+			}
+		}
+	}
+}
+
+func TestVersion_ShortFlag(t *testing.T) {
+	defer func(v string) { version = v }(version)
+	version = "1.2.3"
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"--version"})
+	err := rootCmd.Execute()
+	rootCmd.SetOut(nil)
+	rootCmd.Flags().Lookup("version").Changed = false
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got, want := out.String(), "wail version 1.2.3\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync/atomic"
+)
+
+// fallbackWidth is the --max-line-width used for auto when output isn't a
+// terminal or its size can't be read.
+const fallbackWidth = 80
+
+// maxLineWidth returns a function giving the width lines are cut to for a
+// --max-line-width value: a positive number of characters, or auto for the
+// width of the terminal out writes to. An auto width follows the terminal
+// as it is resized until ctx is cancelled.
+func maxLineWidth(ctx context.Context, value string, out io.Writer) (func() int, error) {
+	if value != "auto" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid max-line-width value: %s (use a positive number or 'auto')", value)
+		}
+		return func() int { return n }, nil
+	}
+
+	f, ok := out.(*os.File)
+	if !ok || !isTerminal(out) {
+		return func() int { return fallbackWidth }, nil
+	}
+
+	var width atomic.Int64
+	update := func() {
+		w, ok := terminalWidth(f)
+		if !ok {
+			w = fallbackWidth
+		}
+		width.Store(int64(w))
+	}
+	update()
+	watchResize(ctx, update)
+	return func() int { return int(width.Load()) }, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMaxLineWidth(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"40", 40, false},
+		{"auto", fallbackWidth, false}, // Not a terminal
+		{"0", 0, true},
+		{"-5", 0, true},
+		{"wide", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			width, err := maxLineWidth(context.Background(), tt.value, &bytes.Buffer{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("maxLineWidth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && width() != tt.want {
+				t.Errorf("width = %d, want %d", width(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCLI_MaxLineWidth(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.txt")
+	os.WriteFile(testFile, []byte("short\na rather longer line\n"), 0644)
+
+	var out bytes.Buffer
+	cmd := newTestCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--max-line-width=10", testFile})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got, want := out.String(), "short\na rather …\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,3 @@
+// Package eventlog reads the Windows Event Log, so a channel can be tailed
+// like a file.
+package eventlog
@@ -0,0 +1,81 @@
+package eventlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Scheme prefixes a path that names an Event Log channel rather than a file,
+// as in evtlog://Application.
+const Scheme = "evtlog://"
+
+// ErrUnsupported is returned by Open on platforms without an Event Log.
+var ErrUnsupported = errors.New("event logs are only available on Windows")
+
+// timeLayout is how an event's creation time is shown.
+const timeLayout = "2006-01-02 15:04:05"
+
+// ParseSource returns the channel named by an evtlog:// path. ok is false if
+// path does not use the scheme or names no channel.
+func ParseSource(path string) (channel string, ok bool) {
+	channel, ok = strings.CutPrefix(path, Scheme)
+	if !ok || channel == "" {
+		return "", false
+	}
+	return channel, true
+}
+
+// Event is a single Event Log record.
+type Event struct {
+	RecordID uint64 // Increases with each event written to the channel
+	Time     time.Time
+	Level    uint8
+	Provider string
+	EventID  uint16
+	Message  string // Empty if the provider's message could not be formatted
+}
+
+// String formats the event as one line: time, level, source and message.
+// Line breaks inside the message are folded into spaces.
+func (e Event) String() string {
+	msg := strings.Join(strings.Fields(e.Message), " ")
+	if msg == "" {
+		msg = fmt.Sprintf("(event ID %d)", e.EventID)
+	}
+	return fmt.Sprintf("%s %s %s: %s", e.Time.Local().Format(timeLayout), LevelName(e.Level), e.Provider, msg)
+}
+
+// LevelName returns the Event Viewer name for a level. Level 0 (LogAlways)
+// is shown as Information, as Event Viewer does for classic events.
+func LevelName(level uint8) string {
+	switch level {
+	case 0, 4:
+		return "Information"
+	case 1:
+		return "Critical"
+	case 2:
+		return "Error"
+	case 3:
+		return "Warning"
+	case 5:
+		return "Verbose"
+	default:
+		return fmt.Sprintf("Level%d", level)
+	}
+}
+
+// Reader reads events from one Event Log channel.
+type Reader interface {
+	// Last returns up to n of the channel's most recent events, oldest first.
+	Last(n int) ([]Event, error)
+
+	// Follow calls fn with each event whose RecordID is greater than after,
+	// including events written while it runs, until ctx is cancelled or fn
+	// returns an error. Cancellation is not an error.
+	Follow(ctx context.Context, after uint64, fn func(Event) error) error
+
+	Close() error
+}
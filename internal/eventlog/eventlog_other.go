@@ -0,0 +1,8 @@
+//go:build !windows
+
+package eventlog
+
+// Open always fails outside Windows.
+func Open(channel string) (Reader, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,72 @@
+package eventlog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		path    string
+		channel string
+		ok      bool
+	}{
+		{"evtlog://Application", "Application", true},
+		{"evtlog://Microsoft-Windows-PowerShell/Operational", "Microsoft-Windows-PowerShell/Operational", true},
+		{"evtlog://", "", false},
+		{"app.log", "", false},
+		{"EVTLOG://System", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			channel, ok := ParseSource(tt.path)
+			if channel != tt.channel || ok != tt.ok {
+				t.Errorf("ParseSource(%q) = %q, %v; want %q, %v", tt.path, channel, ok, tt.channel, tt.ok)
+			}
+		})
+	}
+}
+
+func TestEvent_String(t *testing.T) {
+	when := time.Date(2024, 3, 1, 9, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name  string
+		event Event
+		want  string
+	}{
+		{
+			"single line",
+			Event{Time: when, Level: 2, Provider: "Service Control Manager", Message: "The service stopped."},
+			"2024-03-01 09:30:00 Error Service Control Manager: The service stopped.",
+		},
+		{
+			"multi-line message folded",
+			Event{Time: when, Level: 3, Provider: "disk", Message: "Reset to device.\r\n\r\nDetails follow.\r\n"},
+			"2024-03-01 09:30:00 Warning disk: Reset to device. Details follow.",
+		},
+		{
+			"no message",
+			Event{Time: when, Level: 0, Provider: "MyApp", EventID: 1000},
+			"2024-03-01 09:30:00 Information MyApp: (event ID 1000)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.event.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelName(t *testing.T) {
+	tests := map[uint8]string{0: "Information", 1: "Critical", 2: "Error", 3: "Warning", 4: "Information", 5: "Verbose", 16: "Level16"}
+	for level, want := range tests {
+		if got := LevelName(level); got != want {
+			t.Errorf("LevelName(%d) = %q, want %q", level, got, want)
+		}
+	}
+}
@@ -0,0 +1,382 @@
+//go:build windows
+
+package eventlog
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	wevtapi = windows.NewLazySystemDLL("wevtapi.dll")
+
+	procEvtQuery                 = wevtapi.NewProc("EvtQuery")
+	procEvtNext                  = wevtapi.NewProc("EvtNext")
+	procEvtSubscribe             = wevtapi.NewProc("EvtSubscribe")
+	procEvtCreateRenderContext   = wevtapi.NewProc("EvtCreateRenderContext")
+	procEvtRender                = wevtapi.NewProc("EvtRender")
+	procEvtOpenPublisherMetadata = wevtapi.NewProc("EvtOpenPublisherMetadata")
+	procEvtFormatMessage         = wevtapi.NewProc("EvtFormatMessage")
+	procEvtClose                 = wevtapi.NewProc("EvtClose")
+)
+
+// Flags and values from winevt.h.
+const (
+	evtQueryChannelPath             = 0x1
+	evtQueryReverseDirection        = 0x200
+	evtSubscribeStartAtOldestRecord = 2
+	evtSubscribeActionError         = 0
+	evtSubscribeActionDeliver       = 1
+	evtRenderContextSystem          = 1
+	evtRenderEventValues            = 0
+	evtFormatMessageEvent           = 1
+	evtVarTypeNull                  = 0
+
+	// A message whose insertion strings couldn't all be resolved is still
+	// formatted, just with placeholders left in
+	errorEvtUnresolvedValueInsert = windows.Errno(15029)
+)
+
+// Indexes into the values rendered with evtRenderContextSystem
+// (EVT_SYSTEM_PROPERTY_ID).
+const (
+	sysProviderName  = 0
+	sysEventID       = 2
+	sysLevel         = 4
+	sysTimeCreated   = 8
+	sysEventRecordID = 9
+)
+
+// batchSize is how many events are fetched or queued at once.
+const batchSize = 64
+
+// evtHandle is an EVT_HANDLE.
+type evtHandle uintptr
+
+func (h evtHandle) close() {
+	procEvtClose.Call(uintptr(h))
+}
+
+// evtVariant mirrors EVT_VARIANT: an 8-byte union, a count and a type.
+type evtVariant struct {
+	data  [8]byte
+	count uint32
+	typ   uint32
+}
+
+func (v *evtVariant) uint64() uint64 {
+	return binary.LittleEndian.Uint64(v.data[:])
+}
+
+func (v *evtVariant) string() string {
+	return windows.UTF16PtrToString(*(**uint16)(unsafe.Pointer(&v.data)))
+}
+
+// reader implements Reader with the wevtapi query and subscription calls.
+type reader struct {
+	channel string
+
+	mu         sync.Mutex // Guards rendering, which subscription callbacks also do
+	render     evtHandle
+	publishers map[string]evtHandle // Message metadata by provider; 0 if it has none
+}
+
+// Open returns a Reader for the named channel, such as Application or
+// Microsoft-Windows-PowerShell/Operational.
+func Open(channel string) (Reader, error) {
+	if err := wevtapi.Load(); err != nil {
+		return nil, fmt.Errorf("loading wevtapi.dll: %w", err)
+	}
+
+	// Query once so an unknown channel fails here rather than on first read
+	q, err := query(channel, evtQueryChannelPath|evtQueryReverseDirection)
+	if err != nil {
+		return nil, err
+	}
+	q.close()
+
+	render, _, err := procEvtCreateRenderContext.Call(0, 0, evtRenderContextSystem)
+	if render == 0 {
+		return nil, fmt.Errorf("creating render context: %w", err)
+	}
+
+	return &reader{
+		channel:    channel,
+		render:     evtHandle(render),
+		publishers: make(map[string]evtHandle),
+	}, nil
+}
+
+// query runs an EvtQuery for every event in channel.
+func query(channel string, flags uintptr) (evtHandle, error) {
+	path, err := windows.UTF16PtrFromString(channel)
+	if err != nil {
+		return 0, fmt.Errorf("invalid channel name: %w", err)
+	}
+	all, _ := windows.UTF16PtrFromString("*")
+
+	h, _, err := procEvtQuery.Call(0, uintptr(unsafe.Pointer(path)), uintptr(unsafe.Pointer(all)), flags)
+	if h == 0 {
+		return 0, fmt.Errorf("querying %s: %w", channel, err)
+	}
+	return evtHandle(h), nil
+}
+
+// next fills handles from the result set rs, returning how many it got;
+// 0 means the results are exhausted.
+func next(rs evtHandle, handles []evtHandle) (int, error) {
+	var returned uint32
+	ok, _, err := procEvtNext.Call(uintptr(rs), uintptr(len(handles)), uintptr(unsafe.Pointer(&handles[0])),
+		uintptr(windows.INFINITE), 0, uintptr(unsafe.Pointer(&returned)))
+	if ok == 0 {
+		if errors.Is(err, windows.ERROR_NO_MORE_ITEMS) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("reading events: %w", err)
+	}
+	return int(returned), nil
+}
+
+// Last returns up to n of the channel's most recent events, oldest first.
+func (r *reader) Last(n int) ([]Event, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	q, err := query(r.channel, evtQueryChannelPath|evtQueryReverseDirection)
+	if err != nil {
+		return nil, err
+	}
+	defer q.close()
+
+	var events []Event
+	handles := make([]evtHandle, min(n, batchSize))
+	for len(events) < n {
+		got, err := next(q, handles[:min(n-len(events), len(handles))])
+		if err != nil {
+			return nil, err
+		}
+		if got == 0 {
+			break
+		}
+
+		// Close every handle in the batch, even after a render fails
+		var renderErr error
+		for _, h := range handles[:got] {
+			if renderErr == nil {
+				var ev Event
+				ev, renderErr = r.renderEvent(h)
+				events = append(events, ev)
+			}
+			h.close()
+		}
+		if renderErr != nil {
+			return nil, renderErr
+		}
+	}
+
+	slices.Reverse(events) // The query ran newest first
+	return events, nil
+}
+
+// subscription carries one Follow call's events from the callback thread.
+type subscription struct {
+	reader *reader
+	events chan Event
+	errs   chan error
+	done   chan struct{}
+}
+
+var (
+	subscriptions    sync.Map // Subscription ID (the callback's user context) to *subscription
+	lastSubscription atomic.Uintptr
+
+	// Callbacks can't be freed, so every subscription shares one
+	subscribeCallback = sync.OnceValue(func() uintptr {
+		return windows.NewCallback(onEvent)
+	})
+)
+
+// onEvent is the EVT_SUBSCRIBE_CALLBACK for every subscription. The event
+// handle is only valid during the call, so the event is rendered here and
+// handed to the goroutine running Follow.
+func onEvent(action, userContext, event uintptr) uintptr {
+	v, ok := subscriptions.Load(userContext)
+	if !ok {
+		return 0
+	}
+	sub := v.(*subscription)
+
+	switch action {
+	case evtSubscribeActionDeliver:
+		ev, err := sub.reader.renderEvent(evtHandle(event))
+		if err != nil {
+			select {
+			case sub.errs <- err:
+			case <-sub.done:
+			}
+			return 0
+		}
+		select {
+		case sub.events <- ev:
+		case <-sub.done:
+		}
+	case evtSubscribeActionError:
+		// For errors, the event handle is the Win32 error code
+		select {
+		case sub.errs <- fmt.Errorf("subscription to %s: %w", sub.reader.channel, windows.Errno(event)):
+		case <-sub.done:
+		}
+	}
+	return 0
+}
+
+// Follow calls fn with each event newer than after, via an EvtSubscribe
+// subscription that starts with any already written and continues with new
+// ones, so nothing is missed between Last and Follow.
+func (r *reader) Follow(ctx context.Context, after uint64, fn func(Event) error) error {
+	id := lastSubscription.Add(1)
+	sub := &subscription{
+		reader: r,
+		events: make(chan Event, batchSize),
+		errs:   make(chan error, 1),
+		done:   make(chan struct{}),
+	}
+	subscriptions.Store(id, sub)
+	defer subscriptions.Delete(id)
+
+	path, err := windows.UTF16PtrFromString(r.channel)
+	if err != nil {
+		return fmt.Errorf("invalid channel name: %w", err)
+	}
+	xpath, _ := windows.UTF16PtrFromString(fmt.Sprintf("*[System[(EventRecordID > %d)]]", after))
+
+	h, _, err := procEvtSubscribe.Call(0, 0, uintptr(unsafe.Pointer(path)), uintptr(unsafe.Pointer(xpath)),
+		0, id, subscribeCallback(), evtSubscribeStartAtOldestRecord)
+	if h == 0 {
+		return fmt.Errorf("subscribing to %s: %w", r.channel, err)
+	}
+	defer func() {
+		close(sub.done) // Release a callback blocked on a send before EvtClose waits for it
+		evtHandle(h).close()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-sub.errs:
+			return err
+		case ev := <-sub.events:
+			if err := fn(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// renderEvent extracts an Event from an event handle.
+func (r *reader) renderEvent(h evtHandle) (Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Variants come first in the buffer, followed by the strings they point
+	// to; []uint64 keeps it aligned for them
+	buf := make([]uint64, 64)
+	var used, count uint32
+	for {
+		ok, _, err := procEvtRender.Call(uintptr(r.render), uintptr(h), evtRenderEventValues,
+			uintptr(len(buf)*8), uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&used)), uintptr(unsafe.Pointer(&count)))
+		if ok != 0 {
+			break
+		}
+		if errors.Is(err, windows.ERROR_INSUFFICIENT_BUFFER) && int(used) > len(buf)*8 {
+			buf = make([]uint64, (used+7)/8)
+			continue
+		}
+		return Event{}, fmt.Errorf("rendering event: %w", err)
+	}
+
+	values := unsafe.Slice((*evtVariant)(unsafe.Pointer(&buf[0])), count)
+	value := func(i int) *evtVariant {
+		if i < len(values) && values[i].typ != evtVarTypeNull {
+			return &values[i]
+		}
+		return nil
+	}
+
+	var ev Event
+	if v := value(sysProviderName); v != nil {
+		ev.Provider = v.string()
+	}
+	if v := value(sysEventID); v != nil {
+		ev.EventID = binary.LittleEndian.Uint16(v.data[:])
+	}
+	if v := value(sysLevel); v != nil {
+		ev.Level = v.data[0]
+	}
+	if v := value(sysTimeCreated); v != nil {
+		ft := windows.Filetime{LowDateTime: uint32(v.uint64()), HighDateTime: uint32(v.uint64() >> 32)}
+		ev.Time = time.Unix(0, ft.Nanoseconds())
+	}
+	if v := value(sysEventRecordID); v != nil {
+		ev.RecordID = v.uint64()
+	}
+	ev.Message = r.message(ev.Provider, h)
+	return ev, nil
+}
+
+// message formats an event's message from its provider's metadata,
+// returning "" if the provider has none. Callers hold r.mu.
+func (r *reader) message(provider string, h evtHandle) string {
+	meta, ok := r.publishers[provider]
+	if !ok {
+		if p, err := windows.UTF16PtrFromString(provider); err == nil && provider != "" {
+			m, _, _ := procEvtOpenPublisherMetadata.Call(0, uintptr(unsafe.Pointer(p)), 0, 0, 0)
+			meta = evtHandle(m)
+		}
+		r.publishers[provider] = meta
+	}
+	if meta == 0 {
+		return ""
+	}
+
+	buf := make([]uint16, 1024)
+	for {
+		var used uint32
+		ok, _, err := procEvtFormatMessage.Call(uintptr(meta), uintptr(h), 0, 0, 0, evtFormatMessageEvent,
+			uintptr(len(buf)), uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&used)))
+		switch {
+		case ok != 0, errors.Is(err, errorEvtUnresolvedValueInsert):
+			return windows.UTF16ToString(buf[:min(int(used), len(buf))])
+		case errors.Is(err, windows.ERROR_INSUFFICIENT_BUFFER) && int(used) > len(buf):
+			buf = make([]uint16, used)
+		default:
+			return ""
+		}
+	}
+}
+
+// Close releases the render context and cached provider metadata.
+func (r *reader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, meta := range r.publishers {
+		if meta != 0 {
+			meta.close()
+		}
+	}
+	r.publishers = nil
+	r.render.close()
+	return nil
+}
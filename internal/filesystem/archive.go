@@ -0,0 +1,180 @@
+package filesystem
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ArchiveSeparator separates an archive path from the name of a member inside
+// it, as in bundle.zip::logs/app.log.
+const ArchiveSeparator = "::"
+
+// ErrMemberNotFound is returned when an archive has no member with the
+// requested name.
+var ErrMemberNotFound = errors.New("no such member in archive")
+
+// maxListedMembers caps how many member names a not-found error suggests.
+const maxListedMembers = 10
+
+// SplitArchivePath splits an archive::member path into its parts. ok is false
+// if path has no separator, or if it names an existing file (so a file that
+// happens to contain "::" in its name is still opened as a file).
+func SplitArchivePath(path string) (archive, member string, ok bool) {
+	archive, member, ok = strings.Cut(path, ArchiveSeparator)
+	if !ok || archive == "" || member == "" {
+		return "", "", false
+	}
+	if _, err := os.Stat(path); err == nil {
+		return "", "", false
+	}
+	return archive, member, true
+}
+
+// ArchiveOpener opens individual members of zip and tar archives.
+type ArchiveOpener interface {
+	// Open opens the member named by an archive::member path. The returned
+	// stream holds the member's contents and is not seekable.
+	Open(path string) (io.ReadCloser, error)
+}
+
+// archiveOpener implements ArchiveOpener on top of a FileOpener.
+type archiveOpener struct {
+	files FileOpener
+}
+
+// NewArchiveOpener returns an ArchiveOpener that opens archives with files.
+// Tar archives may be compressed in any format DecompressingReader supports.
+func NewArchiveOpener(files FileOpener) ArchiveOpener {
+	return &archiveOpener{files: files}
+}
+
+// Open opens the member named by an archive::member path.
+func (o *archiveOpener) Open(path string) (io.ReadCloser, error) {
+	archive, member, ok := SplitArchivePath(path)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an archive%smember path", path, ArchiveSeparator)
+	}
+
+	f, err := o.files.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 4)
+	n, _ := io.ReadFull(f, header)
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("seeking: %w", err)
+	}
+
+	var rc io.ReadCloser
+	if bytes.Equal(header[:n], []byte("PK\x03\x04")) {
+		rc, err = openZipMember(f, member)
+	} else {
+		rc, err = openTarMember(f, member)
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", archive, err)
+	}
+	return rc, nil
+}
+
+// openZipMember returns the contents of member in the zip archive f.
+// Closing the result closes f.
+func openZipMember(f ReadSeekCloser, member string) (io.ReadCloser, error) {
+	ra, ok := f.(io.ReaderAt)
+	if !ok {
+		return nil, fmt.Errorf("zip archives need random access")
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat file: %w", err)
+	}
+	zr, err := zip.NewReader(ra, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("reading zip archive: %w", err)
+	}
+
+	var names []string
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() {
+			continue
+		}
+		if zf.Name == member {
+			mr, err := zf.Open()
+			if err != nil {
+				return nil, fmt.Errorf("opening %s: %w", member, err)
+			}
+			return &memberReader{Reader: mr, closers: []io.Closer{mr, f}}, nil
+		}
+		names = append(names, zf.Name)
+	}
+	return nil, memberNotFound(member, names)
+}
+
+// openTarMember returns the contents of member in the (optionally compressed)
+// tar archive f. Closing the result closes f.
+func openTarMember(f ReadSeekCloser, member string) (io.ReadCloser, error) {
+	dr, _, err := DecompressingReader(f)
+	if err != nil {
+		return nil, err
+	}
+
+	tr := tar.NewReader(dr)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			dr.Close()
+			return nil, fmt.Errorf("not a zip or tar archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if strings.TrimPrefix(hdr.Name, "./") == member {
+			return &memberReader{Reader: tr, closers: []io.Closer{dr, f}}, nil
+		}
+		names = append(names, hdr.Name)
+	}
+	dr.Close()
+	return nil, memberNotFound(member, names)
+}
+
+// memberNotFound builds an ErrMemberNotFound error listing some of the
+// members that do exist.
+func memberNotFound(member string, names []string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("%s: %w (archive has no files)", member, ErrMemberNotFound)
+	}
+	listed := names
+	more := ""
+	if len(listed) > maxListedMembers {
+		listed = listed[:maxListedMembers]
+		more = fmt.Sprintf(", and %d more", len(names)-maxListedMembers)
+	}
+	return fmt.Errorf("%s: %w (available: %s%s)", member, ErrMemberNotFound, strings.Join(listed, ", "), more)
+}
+
+// memberReader reads an archive member and closes everything underneath it.
+type memberReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (m *memberReader) Close() error {
+	var errs []error
+	for _, c := range m.closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,143 @@
+package filesystem
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeZip creates a zip archive at path holding the given members.
+func writeZip(t *testing.T, path string, members map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create archive: %v", err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, content := range members {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("failed to add %s: %v", name, err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to write archive: %v", err)
+	}
+}
+
+// writeTarGz creates a gzip-compressed tar archive at path holding the given members.
+func writeTarGz(t *testing.T, path string, members map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create archive: %v", err)
+	}
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for name, content := range members {
+		hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("failed to add %s: %v", name, err)
+		}
+		tw.Write([]byte(content))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to write archive: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("failed to write archive: %v", err)
+	}
+}
+
+func TestSplitArchivePath(t *testing.T) {
+	dir := t.TempDir()
+	literal := filepath.Join(dir, "odd::name.log")
+	if err := os.WriteFile(literal, nil, 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		path    string
+		archive string
+		member  string
+		ok      bool
+	}{
+		{"bundle.zip::logs/app.log", "bundle.zip", "logs/app.log", true},
+		{"app.log", "", "", false},
+		{"bundle.zip::", "", "", false},
+		{"::app.log", "", "", false},
+		{literal, "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			archive, member, ok := SplitArchivePath(tt.path)
+			if archive != tt.archive || member != tt.member || ok != tt.ok {
+				t.Errorf("SplitArchivePath(%q) = %q, %q, %v; want %q, %q, %v",
+					tt.path, archive, member, ok, tt.archive, tt.member, tt.ok)
+			}
+		})
+	}
+}
+
+func TestArchiveOpener_Open(t *testing.T) {
+	dir := t.TempDir()
+	members := map[string]string{
+		"logs/app.log":   "app\n",
+		"logs/error.log": "error\n",
+	}
+	zipPath := filepath.Join(dir, "bundle.zip")
+	writeZip(t, zipPath, members)
+	tarPath := filepath.Join(dir, "bundle.tar.gz")
+	writeTarGz(t, tarPath, members)
+
+	opener := NewArchiveOpener(NewFileOpener())
+	for _, archive := range []string{zipPath, tarPath} {
+		t.Run(filepath.Base(archive), func(t *testing.T) {
+			r, err := opener.Open(archive + "::logs/error.log")
+			if err != nil {
+				t.Fatalf("Open error = %v", err)
+			}
+			defer r.Close()
+
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("ReadAll error = %v", err)
+			}
+			if string(got) != "error\n" {
+				t.Errorf("got %q, want %q", got, "error\n")
+			}
+		})
+	}
+}
+
+func TestArchiveOpener_MemberNotFound(t *testing.T) {
+	dir := t.TempDir()
+	members := map[string]string{"logs/app.log": "app\n"}
+	zipPath := filepath.Join(dir, "bundle.zip")
+	writeZip(t, zipPath, members)
+	tarPath := filepath.Join(dir, "bundle.tar.gz")
+	writeTarGz(t, tarPath, members)
+
+	opener := NewArchiveOpener(NewFileOpener())
+	for _, archive := range []string{zipPath, tarPath} {
+		t.Run(filepath.Base(archive), func(t *testing.T) {
+			_, err := opener.Open(archive + "::logs/missing.log")
+			if !errors.Is(err, ErrMemberNotFound) {
+				t.Fatalf("expected ErrMemberNotFound, got %v", err)
+			}
+			if !strings.Contains(err.Error(), "logs/app.log") {
+				t.Errorf("expected available members in error, got %q", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package filesystem
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression identifies the compression format of a stream.
+type Compression int
+
+const (
+	CompressionNone Compression = iota
+	CompressionGzip
+	CompressionBzip2
+	CompressionZstd
+)
+
+// String returns the conventional name of the format.
+func (c Compression) String() string {
+	switch c {
+	case CompressionGzip:
+		return "gzip"
+	case CompressionBzip2:
+		return "bzip2"
+	case CompressionZstd:
+		return "zstd"
+	default:
+		return "none"
+	}
+}
+
+// magicLen is how many leading bytes DetectCompression needs to see.
+const magicLen = 4
+
+// probeLen is how much of a stream that starts with a magic number is
+// decoded to check it really is compressed; see decodes.
+const probeLen = 4096
+
+// DetectCompression identifies a compression format from the first bytes of
+// a stream. Add new formats here and in DecompressingReader.
+func DetectCompression(header []byte) Compression {
+	switch {
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return CompressionGzip
+	case len(header) >= 4 && bytes.HasPrefix(header, []byte("BZh")) && header[3] >= '1' && header[3] <= '9':
+		return CompressionBzip2
+	case bytes.HasPrefix(header, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return CompressionZstd
+	default:
+		return CompressionNone
+	}
+}
+
+// DecompressingReader sniffs the first bytes of r and, if they carry a known
+// magic number, returns a reader of the decompressed stream along with the
+// detected format. Otherwise the returned reader yields r's contents unchanged
+// and the format is CompressionNone, as it is for a plain file that merely
+// starts with a magic number, such as a log whose first line begins "BZh9".
+// Data that is compressed but corrupt further on fails with an error naming
+// the format rather than producing garbage.
+func DecompressingReader(r io.Reader) (io.ReadCloser, Compression, error) {
+	br := bufio.NewReaderSize(r, probeLen)
+	header, err := br.Peek(magicLen)
+	if err != nil && err != io.EOF {
+		return nil, CompressionNone, err
+	}
+
+	format := DetectCompression(header)
+	if format != CompressionNone {
+		probe, err := br.Peek(probeLen)
+		if err != nil && err != io.EOF {
+			return nil, CompressionNone, err
+		}
+		if !decodes(format, probe) {
+			format = CompressionNone
+		}
+	}
+	d := &decompressor{format: format}
+	switch format {
+	case CompressionNone:
+		return io.NopCloser(br), format, nil
+	case CompressionGzip:
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, format, fmt.Errorf("reading gzip data: %w", err)
+		}
+		d.r, d.close = gz, gz.Close
+	case CompressionBzip2:
+		d.r = bzip2.NewReader(br)
+	case CompressionZstd:
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, format, fmt.Errorf("reading zstd data: %w", err)
+		}
+		d.r, d.close = zr, func() error { zr.Close(); return nil }
+	}
+	return d, format, nil
+}
+
+// decodes reports whether probe, the start of a stream DetectCompression
+// took for format, carries that format's header rather than only its magic
+// number: gzip's method and flags, bzip2's block or end-of-stream magic,
+// or for zstd, a first block that decodes as far as probe goes.
+func decodes(format Compression, probe []byte) bool {
+	switch format {
+	case CompressionGzip:
+		// Deflate is the only method, and the top three flag bits are reserved
+		return len(probe) >= 10 && probe[2] == 8 && probe[3]&0xe0 == 0
+	case CompressionBzip2:
+		return len(probe) >= 10 &&
+			(bytes.Equal(probe[4:10], []byte{0x31, 0x41, 0x59, 0x26, 0x53, 0x59}) ||
+				bytes.Equal(probe[4:10], []byte{0x17, 0x72, 0x45, 0x38, 0x50, 0x90}))
+	case CompressionZstd:
+		zr, err := zstd.NewReader(bytes.NewReader(probe), zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return false
+		}
+		defer zr.Close()
+		_, err = zr.Read(make([]byte, 1))
+		return err == nil || truncated(err)
+	}
+	return false
+}
+
+func truncated(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// decompressor labels read errors with the format, so a corrupt or
+// misidentified file says why it could not be read.
+type decompressor struct {
+	r      io.Reader
+	format Compression
+	close  func() error
+}
+
+func (d *decompressor) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("reading %s data: %w", d.format, err)
+	}
+	return n, err
+}
+
+func (d *decompressor) Close() error {
+	if d.close == nil {
+		return nil
+	}
+	return d.close()
+}
@@ -0,0 +1,136 @@
+package filesystem
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestDetectCompression(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   Compression
+	}{
+		{"gzip", []byte{0x1f, 0x8b, 0x08, 0x00}, CompressionGzip},
+		{"bzip2", []byte("BZh9"), CompressionBzip2},
+		{"zstd", []byte{0x28, 0xb5, 0x2f, 0xfd}, CompressionZstd},
+		{"plain text", []byte("line"), CompressionNone},
+		{"BZh without block size", []byte("BZhx"), CompressionNone},
+		{"short", []byte{0x28, 0xb5}, CompressionNone},
+		{"empty", nil, CompressionNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectCompression(tt.header); got != tt.want {
+				t.Errorf("DetectCompression(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecompressingReader(t *testing.T) {
+	content := "hello\nworld\n"
+
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write([]byte(content))
+	gw.Close()
+
+	var zs bytes.Buffer
+	zw, err := zstd.NewWriter(&zs)
+	if err != nil {
+		t.Fatalf("zstd.NewWriter error = %v", err)
+	}
+	zw.Write([]byte(content))
+	zw.Close()
+
+	tests := []struct {
+		name  string
+		input []byte
+		want  Compression
+	}{
+		{"gzip", gz.Bytes(), CompressionGzip},
+		{"zstd", zs.Bytes(), CompressionZstd},
+		{"plain", []byte(content), CompressionNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, format, err := DecompressingReader(bytes.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("DecompressingReader error = %v", err)
+			}
+			defer r.Close()
+
+			if format != tt.want {
+				t.Errorf("format = %v, want %v", format, tt.want)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("ReadAll error = %v", err)
+			}
+			if string(got) != content {
+				t.Errorf("got %q, want %q", got, content)
+			}
+		})
+	}
+}
+
+func TestDecompressingReader_NotActuallyCompressed(t *testing.T) {
+	// Plain text that starts with a magic number is passed through as it is
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{"gzip", []byte{0x1f, 0x8b, 'n', 'o', 'p', 'e', '\n'}},
+		{"bzip2", []byte("BZh9 is how this log line starts\n")},
+		{"zstd", append([]byte{0x28, 0xb5, 0x2f, 0xfd}, "not zstd\n"...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, format, err := DecompressingReader(bytes.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("DecompressingReader error = %v", err)
+			}
+			defer r.Close()
+			if format != CompressionNone {
+				t.Errorf("format = %v, want %v", format, CompressionNone)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("ReadAll error = %v", err)
+			}
+			if !bytes.Equal(got, tt.input) {
+				t.Errorf("got %q, want %q", got, tt.input)
+			}
+		})
+	}
+}
+
+func TestDecompressingReader_CorruptAfterHeader(t *testing.T) {
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write([]byte(strings.Repeat("hello\n", 1000)))
+	gw.Close()
+	corrupt := gz.Bytes()
+	for i := 20; i < len(corrupt)-8; i++ {
+		corrupt[i] ^= 0xff
+	}
+
+	// A real header with broken data after it is still gzip, and says so
+	r, format, err := DecompressingReader(bytes.NewReader(corrupt))
+	if err == nil {
+		_, err = io.ReadAll(r)
+		r.Close()
+	}
+	if format != CompressionGzip || err == nil || !strings.Contains(err.Error(), "gzip") {
+		t.Errorf("format = %v, error = %v, want a gzip error", format, err)
+	}
+}
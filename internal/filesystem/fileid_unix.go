@@ -0,0 +1,19 @@
+//go:build !windows
+
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// FileID returns a printable identity for the file described by info, for
+// diagnostics. On Unix this is the device and inode number ("dev:ino").
+func FileID(info os.FileInfo) string {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", st.Dev, st.Ino)
+}
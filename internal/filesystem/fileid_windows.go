@@ -0,0 +1,12 @@
+//go:build windows
+
+package filesystem
+
+import "os"
+
+// FileID returns a printable identity for the file described by info, for
+// diagnostics. On Windows a path Stat does not carry the volume serial and
+// file index, so this returns "" and callers fall back to os.SameFile alone.
+func FileID(info os.FileInfo) string {
+	return ""
+}
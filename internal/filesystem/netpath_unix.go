@@ -0,0 +1,10 @@
+//go:build !windows
+
+package filesystem
+
+// IsNetworkPath reports whether path is on a network share. On Unix, shares
+// are mounted into the ordinary namespace and can't be told apart by path,
+// so this always returns false; use --poll for NFS and SMB mounts.
+func IsNetworkPath(path string) bool {
+	return false
+}
@@ -0,0 +1,27 @@
+//go:build windows
+
+package filesystem
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// IsNetworkPath reports whether path is on a network share. On Windows this
+// is a UNC path: \\server\share\..., or its extended-length forms
+// \\?\UNC\server\share\... and \\.\UNC\server\share\.... Other \\?\ and \\.\
+// paths name local volumes and devices. Drive letters mapped to shares are
+// not recognised, since telling them apart needs a query per drive.
+func IsNetworkPath(path string) bool {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs // A relative path may be under a UNC working directory
+	}
+	p := strings.ToUpper(strings.ReplaceAll(path, "/", `\`))
+	switch {
+	case strings.HasPrefix(p, `\\?\UNC\`), strings.HasPrefix(p, `\\.\UNC\`):
+		return true
+	case strings.HasPrefix(p, `\\?\`), strings.HasPrefix(p, `\\.\`):
+		return false
+	}
+	return strings.HasPrefix(p, `\\`)
+}
@@ -0,0 +1,31 @@
+//go:build windows
+
+package filesystem
+
+import "testing"
+
+func TestIsNetworkPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{`\\server\share\logs\app.log`, true},
+		{`//server/share/logs/app.log`, true},
+		{`\\?\UNC\server\share\logs\app.log`, true},
+		{`\\?\unc\server\share\app.log`, true},
+		{`\\.\UNC\server\share\app.log`, true},
+		{`\\?\C:\logs\app.log`, false},
+		{`\\.\pipe\wail`, false},
+		{`C:\logs\app.log`, false},
+		{`C:/logs/app.log`, false},
+		{`logs\app.log`, false}, // Relative to a local working directory
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := IsNetworkPath(tt.path); got != tt.want {
+				t.Errorf("IsNetworkPath(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
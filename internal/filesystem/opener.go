@@ -1,6 +1,18 @@
 package filesystem
 
-import "io"
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// ErrSharingViolation is returned when a file cannot be opened because another
+// process holds it open without sharing (ERROR_SHARING_VIOLATION on Windows).
+var ErrSharingViolation = errors.New("file is locked by another process")
+
+// ErrAccessDenied is returned when the caller lacks permission to read the file
+// (ERROR_ACCESS_DENIED on Windows).
+var ErrAccessDenied = errors.New("access denied")
 
 // FileOpener opens files for reading with appropriate share modes.
 // On Windows, this means FILE_SHARE_READ | FILE_SHARE_WRITE | FILE_SHARE_DELETE
@@ -11,9 +23,15 @@ type FileOpener interface {
 	Open(name string) (ReadSeekCloser, error)
 }
 
-// ReadSeekCloser combines io.Reader, io.Seeker, and io.Closer.
+// ReadSeekCloser combines io.Reader, io.Seeker, and io.Closer with Stat.
 type ReadSeekCloser interface {
 	io.Reader
 	io.Seeker
 	io.Closer
+
+	// Stat returns file info for the open file itself rather than whatever
+	// currently lives at its path. On Windows this queries the retained HANDLE
+	// (GetFileInformationByHandle); on Unix it uses fstat on the descriptor.
+	// This keeps size checks correct after the file has been renamed.
+	Stat() (os.FileInfo, error)
 }
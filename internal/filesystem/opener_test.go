@@ -78,3 +78,37 @@ func TestFileOpener_SeekInFile(t *testing.T) {
 		t.Errorf("got %q, want %q", buf[:n], "line2")
 	}
 }
+
+func TestFileOpener_StatFollowsOpenFileAfterRename(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+	renamed := filepath.Join(dir, "test.log.1")
+
+	if err := os.WriteFile(testFile, []byte("short\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	opener := NewFileOpener()
+
+	f, err := opener.Open(testFile)
+	if err != nil {
+		t.Fatalf("Open(%q) error = %v", testFile, err)
+	}
+	defer f.Close()
+
+	// Rotate: rename the open file and put a larger one at the original path
+	if err := os.Rename(testFile, renamed); err != nil {
+		t.Fatalf("failed to rename file: %v", err)
+	}
+	if err := os.WriteFile(testFile, []byte("a much longer replacement file\n"), 0644); err != nil {
+		t.Fatalf("failed to create replacement file: %v", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatalf("Stat error = %v", err)
+	}
+	if info.Size() != int64(len("short\n")) {
+		t.Errorf("Stat size = %d, want %d (size of the open file, not the path)", info.Size(), len("short\n"))
+	}
+}
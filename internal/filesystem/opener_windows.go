@@ -3,6 +3,7 @@
 package filesystem
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -49,6 +50,13 @@ func (o *windowsOpener) Open(name string) (ReadSeekCloser, error) {
 		0,
 	)
 	if err != nil {
+		// Tag the common lock/permission failures so callers can react with errors.Is
+		switch {
+		case errors.Is(err, windows.ERROR_SHARING_VIOLATION):
+			return nil, fmt.Errorf("opening %s: %w: %w", name, ErrSharingViolation, err)
+		case errors.Is(err, windows.ERROR_ACCESS_DENIED):
+			return nil, fmt.Errorf("opening %s: %w: %w", name, ErrAccessDenied, err)
+		}
 		return nil, fmt.Errorf("opening %s: %w", name, err)
 	}
 
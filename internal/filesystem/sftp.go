@@ -0,0 +1,210 @@
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SFTPScheme starts paths naming a file on an SFTP server, as in
+// sftp://user@host/var/log/app.log.
+const SFTPScheme = "sftp://"
+
+// sftpDialTimeout bounds connecting and the SSH handshake.
+const sftpDialTimeout = 15 * time.Second
+
+// SFTPConfig says how to authenticate SFTP connections.
+type SFTPConfig struct {
+	KeyFiles       []string // Private keys to offer after ssh-agent's; if empty, the usual ones in ~/.ssh
+	KnownHostsFile string   // Host keys servers must match; "" means ~/.ssh/known_hosts
+}
+
+// IsSFTPPath reports whether path names a file on an SFTP server.
+func IsSFTPPath(path string) bool {
+	return strings.HasPrefix(path, SFTPScheme)
+}
+
+// sftpTarget is a parsed sftp:// path.
+type sftpTarget struct {
+	user string
+	addr string // host:port
+	path string
+}
+
+// parseSFTPPath parses sftp://[user@]host[:port]/path. The scp-like form
+// sftp://user@host:/path, with an empty port, is accepted too. Without a user
+// the local user name is used, as ssh does.
+func parseSFTPPath(path string) (sftpTarget, error) {
+	u, err := url.Parse(path)
+	if err != nil || u.Scheme != "sftp" || u.Hostname() == "" || u.Path == "" || u.Path == "/" {
+		return sftpTarget{}, fmt.Errorf("invalid sftp path %q (use sftp://[user@]host[:port]/path)", path)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "22"
+	}
+	target := sftpTarget{user: u.User.Username(), addr: net.JoinHostPort(u.Hostname(), port), path: u.Path}
+	if target.user == "" {
+		current, err := user.Current()
+		if err != nil {
+			return sftpTarget{}, fmt.Errorf("no user in %q: %w", path, err)
+		}
+		// Windows names come as DOMAIN\user
+		target.user = current.Username[strings.LastIndex(current.Username, `\`)+1:]
+	}
+	return target, nil
+}
+
+// sftpClients holds one client per user@host:port, so every file tailed from
+// a server shares a single SSH connection. A client is dropped when its
+// connection ends, and the next open dials again.
+var sftpClients = struct {
+	mu sync.Mutex
+	m  map[string]*sftp.Client
+}{m: make(map[string]*sftp.Client)}
+
+// sftpOpener implements FileOpener for sftp:// paths.
+type sftpOpener struct {
+	config SFTPConfig
+}
+
+// NewSFTPOpener returns a FileOpener for sftp:// paths. The files it opens
+// seek and stat over the connection, so reading the last lines fetches only
+// the end of the file.
+func NewSFTPOpener(config SFTPConfig) FileOpener {
+	return &sftpOpener{config: config}
+}
+
+// Open opens the file named by an sftp:// path.
+func (o *sftpOpener) Open(name string) (ReadSeekCloser, error) {
+	target, err := parseSFTPPath(name)
+	if err != nil {
+		return nil, err
+	}
+	client, err := o.client(target)
+	if err != nil {
+		return nil, err
+	}
+	f, err := client.Open(target.path)
+	if errors.Is(err, os.ErrPermission) {
+		return nil, fmt.Errorf("%w: %v", ErrAccessDenied, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// client returns the shared client for target's user and server, connecting
+// if there isn't one.
+func (o *sftpOpener) client(target sftpTarget) (*sftp.Client, error) {
+	key := target.user + "@" + target.addr
+	sftpClients.mu.Lock()
+	defer sftpClients.mu.Unlock()
+	if c, ok := sftpClients.m[key]; ok {
+		return c, nil
+	}
+
+	config, err := o.sshConfig(target.user)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := ssh.Dial("tcp", target.addr, config)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", target.addr, err)
+	}
+	c, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("starting sftp on %s: %w", target.addr, err)
+	}
+	sftpClients.m[key] = c
+
+	go func() {
+		c.Wait()
+		sftpClients.mu.Lock()
+		defer sftpClients.mu.Unlock()
+		if sftpClients.m[key] == c {
+			delete(sftpClients.m, key)
+		}
+	}()
+	return c, nil
+}
+
+// sshConfig authenticates as user with ssh-agent's keys, if an agent is
+// running, then the configured key files, checking the server's host key
+// against the known hosts file.
+func (o *sftpOpener) sshConfig(user string) (*ssh.ClientConfig, error) {
+	home, _ := os.UserHomeDir()
+	knownHostsFile := o.config.KnownHostsFile
+	if knownHostsFile == "" {
+		knownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	hostKeys, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading known hosts: %w", err)
+	}
+
+	var auth []ssh.AuthMethod
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			auth = append(auth, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+
+	// Like ssh, the default keys are tried only if they exist
+	keyFiles, explicit := o.config.KeyFiles, true
+	if len(keyFiles) == 0 {
+		explicit = false
+		for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+			keyFiles = append(keyFiles, filepath.Join(home, ".ssh", name))
+		}
+	}
+	var signers []ssh.Signer
+	for _, file := range keyFiles {
+		pem, err := os.ReadFile(file)
+		if err != nil {
+			if explicit || !errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("reading key: %w", err)
+			}
+			continue
+		}
+		signer, err := ssh.ParsePrivateKey(pem)
+		var missing *ssh.PassphraseMissingError
+		if errors.As(err, &missing) {
+			if explicit {
+				return nil, fmt.Errorf("key %s has a passphrase; add it to ssh-agent instead", file)
+			}
+			continue // ssh-agent may hold it
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing key %s: %w", file, err)
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) > 0 {
+		auth = append(auth, ssh.PublicKeys(signers...))
+	}
+	if len(auth) == 0 {
+		return nil, fmt.Errorf("no SSH keys: start ssh-agent or give a key file")
+	}
+
+	return &ssh.ClientConfig{
+		User:            user,
+		Auth:            auth,
+		HostKeyCallback: hostKeys,
+		Timeout:         sftpDialTimeout,
+	}, nil
+}
@@ -0,0 +1,191 @@
+package filesystem
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestParseSFTPPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    sftpTarget
+		wantErr bool
+	}{
+		{"sftp://deploy@web1/var/log/app.log", sftpTarget{"deploy", "web1:22", "/var/log/app.log"}, false},
+		{"sftp://deploy@web1:2222/var/log/app.log", sftpTarget{"deploy", "web1:2222", "/var/log/app.log"}, false},
+		{"sftp://deploy@web1:/var/log/app.log", sftpTarget{"deploy", "web1:22", "/var/log/app.log"}, false},
+		{"sftp://deploy@[::1]:2222/app.log", sftpTarget{"deploy", "[::1]:2222", "/app.log"}, false},
+		{"sftp://deploy@web1", sftpTarget{}, true},
+		{"sftp:///var/log/app.log", sftpTarget{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSFTPPath(tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSFTPPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseSFTPPath(%q) = %+v, want %+v", tt.path, got, tt.want)
+		}
+	}
+}
+
+// testSFTPServer serves the local file system over SFTP on a loopback port
+// to the holder of a key it generates. It returns the server's address and
+// a config with that key and a known hosts file trusting the server.
+func testSFTPServer(t *testing.T) (string, SFTPConfig) {
+	t.Helper()
+	t.Setenv("SSH_AUTH_SOCK", "") // Only the test key is offered
+	dir := t.TempDir()
+
+	_, hostKey, _ := ed25519.GenerateKey(rand.Reader)
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		t.Fatalf("host key: %v", err)
+	}
+	clientPub, clientKey, _ := ed25519.GenerateKey(rand.Reader)
+	block, err := ssh.MarshalPrivateKey(clientKey, "")
+	if err != nil {
+		t.Fatalf("client key: %v", err)
+	}
+	keyFile := filepath.Join(dir, "id_ed25519")
+	os.WriteFile(keyFile, pem.EncodeToMemory(block), 0600)
+	authorized, _ := ssh.NewPublicKey(clientPub)
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if !bytes.Equal(key.Marshal(), authorized.Marshal()) {
+				return nil, io.EOF
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSFTP(conn, config)
+		}
+	}()
+
+	addr := ln.Addr().String()
+	knownHosts := filepath.Join(dir, "known_hosts")
+	os.WriteFile(knownHosts, []byte(knownhosts.Line([]string{addr}, hostSigner.PublicKey())+"\n"), 0600)
+	return addr, SFTPConfig{KeyFiles: []string{keyFile}, KnownHostsFile: knownHosts}
+}
+
+// serveSFTP runs the sftp subsystem for each session on conn.
+func serveSFTP(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for nc := range chans {
+		if nc.ChannelType() != "session" {
+			nc.Reject(ssh.UnknownChannelType, "session only")
+			continue
+		}
+		ch, reqs, err := nc.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range reqs {
+				ok := req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+				req.Reply(ok, nil)
+				if ok {
+					if server, err := sftp.NewServer(ch); err == nil {
+						server.Serve()
+					}
+					ch.Close()
+				}
+			}
+		}()
+	}
+}
+
+func TestSFTPOpener(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test server's paths are Unix paths")
+	}
+	addr, config := testSFTPServer(t)
+	path := filepath.Join(t.TempDir(), "app.log")
+	os.WriteFile(path, []byte("line1\nline2\nline3\n"), 0644)
+	url := "sftp://tester@" + addr + path
+
+	opener := NewSFTPOpener(config)
+	f, err := opener.Open(url)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	defer f.Close()
+
+	// The handle seeks and stats like a local file
+	info, err := f.Stat()
+	if err != nil || info.Size() != 18 {
+		t.Fatalf("Stat() = %v, %v, want size 18", info, err)
+	}
+	if _, err := f.Seek(-6, io.SeekEnd); err != nil {
+		t.Fatalf("Seek() error: %v", err)
+	}
+	if got, _ := io.ReadAll(f); string(got) != "line3\n" {
+		t.Errorf("read after Seek = %q, want %q", got, "line3\n")
+	}
+
+	// A second file on the same server shares the connection
+	f2, err := opener.Open(url)
+	if err != nil {
+		t.Fatalf("second Open() error: %v", err)
+	}
+	f2.Close()
+	sftpClients.mu.Lock()
+	n := 0
+	for key := range sftpClients.m {
+		if strings.HasSuffix(key, "@"+addr) {
+			n++
+		}
+	}
+	sftpClients.mu.Unlock()
+	if n != 1 {
+		t.Errorf("%d connections to %s, want 1", n, addr)
+	}
+
+	if _, err := opener.Open("sftp://tester@" + addr + path + ".missing"); !os.IsNotExist(err) {
+		t.Errorf("Open() of a missing file error = %v, want not exist", err)
+	}
+}
+
+func TestSFTPOpener_UnknownHost(t *testing.T) {
+	addr, config := testSFTPServer(t)
+	config.KnownHostsFile = filepath.Join(t.TempDir(), "empty")
+	os.WriteFile(config.KnownHostsFile, nil, 0600)
+
+	_, err := NewSFTPOpener(config).Open("sftp://stranger@" + addr + "/app.log")
+	if err == nil || !strings.Contains(err.Error(), "knownhosts") {
+		t.Errorf("Open() error = %v, want a host key error", err)
+	}
+}
@@ -0,0 +1,32 @@
+//go:build darwin || freebsd || linux
+
+package filesystem
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// DataEnd returns the offset where the last run of data in the first size
+// bytes of f ends, which is where a trailing hole begins, by walking the
+// data regions with SEEK_DATA and SEEK_HOLE. It moves f's offset. ok is
+// false if the file system can't report holes.
+func DataEnd(f *os.File, size int64) (end int64, ok bool) {
+	fd := int(f.Fd())
+	for off := int64(0); off < size; {
+		data, err := unix.Seek(fd, off, unix.SEEK_DATA)
+		if err == unix.ENXIO {
+			break // Nothing but hole past off
+		}
+		if err != nil {
+			return 0, false
+		}
+		hole, err := unix.Seek(fd, data, unix.SEEK_HOLE)
+		if err != nil {
+			return 0, false
+		}
+		end, off = hole, hole
+	}
+	return min(end, size), true
+}
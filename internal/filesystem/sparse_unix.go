@@ -0,0 +1,20 @@
+//go:build !windows
+
+package filesystem
+
+import (
+	"os"
+	"syscall"
+)
+
+// IsSparse reports whether the file appears to contain holes.
+// On Unix this compares allocated blocks (st_blocks, in 512-byte units)
+// against the apparent size. It is a heuristic: small files and some
+// filesystems (compression, inline data) can mislead it either way.
+func IsSparse(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+	return int64(st.Blocks)*512 < info.Size()
+}
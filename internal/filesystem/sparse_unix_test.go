@@ -0,0 +1,63 @@
+//go:build !windows
+
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsSparse(t *testing.T) {
+	dir := t.TempDir()
+
+	dense := filepath.Join(dir, "dense.log")
+	if err := os.WriteFile(dense, make([]byte, 64*1024), 0644); err != nil {
+		t.Fatalf("failed to create dense file: %v", err)
+	}
+	info, err := os.Stat(dense)
+	if err != nil {
+		t.Fatalf("Stat error = %v", err)
+	}
+	if IsSparse(info) {
+		t.Error("IsSparse = true for a fully written file, want false")
+	}
+
+	sparse := filepath.Join(dir, "sparse.log")
+	f, err := os.Create(sparse)
+	if err != nil {
+		t.Fatalf("failed to create sparse file: %v", err)
+	}
+	f.WriteString("line1\n")
+	f.Truncate(16 * 1024 * 1024)
+	f.Close()
+
+	info, err = os.Stat(sparse)
+	if err != nil {
+		t.Fatalf("Stat error = %v", err)
+	}
+	if !IsSparse(info) {
+		t.Skip("filesystem does not create sparse files")
+	}
+}
+
+func TestDataEnd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prealloc.log")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create sparse file: %v", err)
+	}
+	defer f.Close()
+	f.WriteString("line1\n")
+	f.Truncate(16 * 1024 * 1024)
+
+	end, ok := DataEnd(f, 16*1024*1024)
+	if !ok {
+		t.Skip("filesystem does not report holes")
+	}
+	// The data ends somewhere in its first block, unless the file system
+	// allocated the whole file after all
+	if end < 6 || (end > 1024*1024 && end != 16*1024*1024) {
+		t.Errorf("DataEnd = %d, want the end of the first block", end)
+	}
+}
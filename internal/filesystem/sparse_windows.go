@@ -0,0 +1,57 @@
+//go:build windows
+
+package filesystem
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// IsSparse reports whether the file is marked sparse.
+// On Windows this checks FILE_ATTRIBUTE_SPARSE_FILE, which NTFS sets for
+// files created with FSCTL_SET_SPARSE (e.g. pre-allocated logs).
+func IsSparse(info os.FileInfo) bool {
+	attrs, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return false
+	}
+	return attrs.FileAttributes&windows.FILE_ATTRIBUTE_SPARSE_FILE != 0
+}
+
+// allocatedRange is a FILE_ALLOCATED_RANGE_BUFFER.
+type allocatedRange struct {
+	FileOffset int64
+	Length     int64
+}
+
+// DataEnd returns the offset where the last allocated range in the first
+// size bytes of f ends, which is where a trailing hole begins, by asking
+// NTFS with FSCTL_QUERY_ALLOCATED_RANGES. ok is false if the file system
+// can't report them.
+func DataEnd(f *os.File, size int64) (end int64, ok bool) {
+	query := allocatedRange{FileOffset: 0, Length: size}
+	ranges := make([]allocatedRange, 64)
+	rangeSize := uint32(unsafe.Sizeof(ranges[0]))
+	for {
+		var n uint32
+		err := windows.DeviceIoControl(windows.Handle(f.Fd()), windows.FSCTL_QUERY_ALLOCATED_RANGES,
+			(*byte)(unsafe.Pointer(&query)), rangeSize,
+			(*byte)(unsafe.Pointer(&ranges[0])), uint32(len(ranges))*rangeSize, &n, nil)
+		if err != nil && err != windows.ERROR_MORE_DATA {
+			return 0, false
+		}
+		got := ranges[:n/rangeSize]
+		if len(got) > 0 {
+			last := got[len(got)-1]
+			end = last.FileOffset + last.Length
+		}
+		if err == nil || len(got) == 0 {
+			return min(end, size), true
+		}
+		// More ranges than fit: carry on after the last one returned
+		query = allocatedRange{FileOffset: end, Length: size - end}
+	}
+}
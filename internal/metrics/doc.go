@@ -0,0 +1,3 @@
+// Package metrics counts what wail emits per file and serves the counts over
+// HTTP in the Prometheus text format, for running wail as a log shipper.
+package metrics
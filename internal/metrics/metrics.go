@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Counters tracks one file's activity. Its methods are safe for concurrent
+// use and do nothing on a nil *Counters, so callers needn't check whether
+// metrics are enabled.
+type Counters struct {
+	lines        atomic.Uint64
+	bytes        atomic.Uint64
+	rotations    atomic.Uint64
+	reopenErrors atomic.Uint64
+}
+
+// Emitted counts one line (or byte-mode chunk) of n bytes written to output.
+func (c *Counters) Emitted(n int) {
+	if c == nil {
+		return
+	}
+	c.lines.Add(1)
+	c.bytes.Add(uint64(n))
+}
+
+// Rotated counts a truncation or replacement of the file.
+func (c *Counters) Rotated() {
+	if c == nil {
+		return
+	}
+	c.rotations.Add(1)
+}
+
+// ReopenFailed counts a failed attempt to reopen the file by name.
+func (c *Counters) ReopenFailed() {
+	if c == nil {
+		return
+	}
+	c.reopenErrors.Add(1)
+}
+
+// Registry holds the Counters for every file, keyed by name.
+type Registry struct {
+	mu    sync.Mutex
+	files map[string]*Counters
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{files: make(map[string]*Counters)}
+}
+
+// File returns the Counters for name, creating them on first use. A nil
+// Registry returns nil Counters.
+func (r *Registry) File(name string) *Counters {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.files[name]
+	if !ok {
+		c = &Counters{}
+		r.files[name] = c
+	}
+	return c
+}
+
+// metric describes one exported counter.
+type metric struct {
+	name  string
+	help  string
+	value func(*Counters) uint64
+}
+
+var metricList = []metric{
+	{"wail_lines_emitted_total", "Lines written to output.", func(c *Counters) uint64 { return c.lines.Load() }},
+	{"wail_bytes_emitted_total", "Bytes written to output, delimiters included.", func(c *Counters) uint64 { return c.bytes.Load() }},
+	{"wail_rotations_total", "Truncations and replacements of the file detected while following.", func(c *Counters) uint64 { return c.rotations.Load() }},
+	{"wail_reopen_errors_total", "Failed attempts to reopen the file by name.", func(c *Counters) uint64 { return c.reopenErrors.Load() }},
+}
+
+// WriteText writes every counter to w in the Prometheus text exposition
+// format, with a file label per series, in name order.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	files := maps.Clone(r.files)
+	r.mu.Unlock()
+	names := slices.Sorted(maps.Keys(files))
+
+	var b strings.Builder
+	for _, m := range metricList {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n", m.name, m.help, m.name)
+		for _, name := range names {
+			fmt.Fprintf(&b, "%s{file=\"%s\"} %d\n", m.name, labelEscaper.Replace(name), m.value(files[name]))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// labelEscaper escapes a label value as the text format requires.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// ServeHTTP serves the counters in the Prometheus text format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.WriteText(w)
+}
+
+// Serve listens on addr and serves r at /metrics until ctx is cancelled or
+// stop is called. Listening happens before Serve returns, so a bad or busy
+// address is reported straight away. stop waits for the server to close.
+func Serve(ctx context.Context, addr string, r *Registry) (stop func(), err error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return serve(ctx, ln, r), nil
+}
+
+// serve is Serve on an existing listener.
+func serve(ctx context.Context, ln net.Listener, r *Registry) (stop func()) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		srv.Serve(ln)
+	}()
+	stopOnCancel := context.AfterFunc(ctx, func() { srv.Close() })
+
+	return func() {
+		stopOnCancel()
+		srv.Close()
+		<-done
+	}
+}
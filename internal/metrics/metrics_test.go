@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	r := NewRegistry()
+	app := r.File("app.log")
+	app.Emitted(6)
+	app.Emitted(4)
+	app.Rotated()
+	r.File(`odd "name".log`).ReopenFailed()
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("WriteText() error: %v", err)
+	}
+	got := b.String()
+
+	for _, want := range []string{
+		"# TYPE wail_lines_emitted_total counter\n",
+		`wail_lines_emitted_total{file="app.log"} 2` + "\n",
+		`wail_bytes_emitted_total{file="app.log"} 10` + "\n",
+		`wail_rotations_total{file="app.log"} 1` + "\n",
+		`wail_reopen_errors_total{file="app.log"} 0` + "\n",
+		`wail_reopen_errors_total{file="odd \"name\".log"} 1` + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestRegistry_Nil(t *testing.T) {
+	var r *Registry
+	c := r.File("app.log")
+	if c != nil {
+		t.Fatalf("File() on nil Registry = %v, want nil", c)
+	}
+
+	// Updates to nil Counters are discarded rather than panicking
+	c.Emitted(1)
+	c.Rotated()
+	c.ReopenFailed()
+}
+
+func TestServe(t *testing.T) {
+	r := NewRegistry()
+	r.File("app.log").Emitted(3)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	url := "http://" + ln.Addr().String() + "/metrics"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stop := serve(ctx, ln, r)
+	defer stop()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET /metrics: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Content-Type = %q, want text/plain", ct)
+	}
+	if !strings.Contains(string(body), `wail_lines_emitted_total{file="app.log"} 1`) {
+		t.Errorf("unexpected body:\n%s", body)
+	}
+
+	// Cancelling the context shuts the server down
+	cancel()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		resp, err := http.Get(url)
+		if err != nil {
+			break
+		}
+		resp.Body.Close()
+		if time.Now().After(deadline) {
+			t.Fatal("server still answering after cancel")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServe_BadAddress(t *testing.T) {
+	if _, err := Serve(context.Background(), "not an address", NewRegistry()); err == nil {
+		t.Error("expected error for bad address")
+	}
+}
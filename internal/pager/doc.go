@@ -0,0 +1,4 @@
+// Package pager implements wail's interactive mode: a less-like pager over a
+// single file, with paging, search, and a follow mode that keeps the end of
+// the file on screen as it grows.
+package pager
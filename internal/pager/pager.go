@@ -0,0 +1,440 @@
+package pager
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jmurray2011/wail/internal/filesystem"
+)
+
+const (
+	chunkSize    = 64 * 1024 // Bytes read at a time while indexing lines
+	maxLineBytes = 64 * 1024 // Bytes of a line read for display and search
+	tabWidth     = 8
+
+	defaultPollInterval = 100 * time.Millisecond
+
+	// Terminal size when it can't be read
+	fallbackWidth  = 80
+	fallbackHeight = 24
+
+	ctrlC     = 0x03
+	backspace = 0x08
+	escape    = 0x1b
+	del       = 0x7f
+
+	enterAltScreen = "\x1b[?1049h"
+	exitAltScreen  = "\x1b[?1049l"
+	clearScreen    = "\x1b[H\x1b[2J"
+	reverseVideo   = "\x1b[7m"
+	resetVideo     = "\x1b[0m"
+)
+
+// Config says what to page and how.
+type Config struct {
+	Path         string
+	PollInterval time.Duration // How often to check the file for growth and the terminal for resizing
+}
+
+// Run pages through the file at config.Path on the terminal in and out until
+// the user quits or ctx is cancelled. Both must be terminals: callers check,
+// since in is switched to raw mode for the duration. The keys are those of
+// less: space and b page, j and k scroll, g and G jump to the start and end,
+// / and n search, and q quits. G also follows the file as it grows.
+func Run(ctx context.Context, config Config, in, out *os.File) error {
+	f, err := filesystem.NewFileOpener().Open(config.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	restore, err := setupTerminal(in, out)
+	if err != nil {
+		return fmt.Errorf("setting up terminal: %w", err)
+	}
+	defer restore()
+
+	// The alternate screen leaves the shell's scrollback as it was
+	io.WriteString(out, enterAltScreen)
+	defer io.WriteString(out, exitAltScreen)
+
+	size := func() (int, int) {
+		if w, h, ok := terminalSize(out); ok {
+			return w, h
+		}
+		return fallbackWidth, fallbackHeight
+	}
+	interval := config.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	p := newPager(f, config.Path, out, size)
+	return p.run(ctx, readKeys(in), interval)
+}
+
+// readKeys sends each byte read from r until reading fails. When the pager
+// quits, the goroutine is left blocked in Read; wail exits straight after.
+func readKeys(r io.Reader) <-chan byte {
+	keys := make(chan byte)
+	go func() {
+		defer close(keys)
+		buf := make([]byte, 64)
+		for {
+			n, err := r.Read(buf)
+			for _, b := range buf[:n] {
+				keys <- b
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return keys
+}
+
+// pager holds the state of one interactive session. Lines are indexed
+// lazily, as far as the screen, a jump or a search needs, so opening a
+// large file is instant until G asks for its end.
+type pager struct {
+	f    filesystem.ReadSeekCloser
+	name string
+	out  io.Writer
+	size func() (width, height int)
+
+	starts  []int64 // Offset of each line found so far; a line begins after every delimiter
+	scanned int64   // Bytes indexed so far
+	eof     bool    // Indexing reached the end of the file when last tried
+
+	top       int  // Index of the first line on screen
+	following bool // Keep the end of the file on screen as it grows
+
+	pattern   *regexp.Regexp // The last search
+	prompting bool           // Reading a search pattern
+	input     []byte         // The pattern typed so far
+	message   string         // Shown in the status line until the next key
+
+	width, height int // Terminal size at the last draw
+}
+
+func newPager(f filesystem.ReadSeekCloser, name string, out io.Writer, size func() (int, int)) *pager {
+	return &pager{f: f, name: name, out: out, size: size, starts: []int64{0}}
+}
+
+// count returns the number of lines known, counting a final line with no
+// delimiter (yet).
+func (p *pager) count() int {
+	n := len(p.starts) - 1
+	if p.scanned > p.starts[n] {
+		n++
+	}
+	return n
+}
+
+// rows returns the number of lines on screen, below which is the status line.
+func (p *pager) rows() int {
+	_, height := p.size()
+	return max(height-1, 1)
+}
+
+// index reads ahead until at least n complete lines are known or the end of
+// the file is reached.
+func (p *pager) index(n int) {
+	var buf []byte
+	for !p.eof && len(p.starts)-1 < n {
+		if buf == nil {
+			buf = make([]byte, chunkSize)
+		}
+		if _, err := p.f.Seek(p.scanned, io.SeekStart); err != nil {
+			p.fail(err)
+			return
+		}
+		m, err := p.f.Read(buf)
+		for i, b := range buf[:m] {
+			if b == '\n' {
+				p.starts = append(p.starts, p.scanned+int64(i)+1)
+			}
+		}
+		p.scanned += int64(m)
+		if err == io.EOF || (err == nil && m == 0) {
+			p.eof = true
+		} else if err != nil {
+			p.fail(err)
+			return
+		}
+	}
+}
+
+// fail reports a read error in the status line and stops indexing until
+// the file next changes.
+func (p *pager) fail(err error) {
+	p.message = fmt.Sprintf("read error: %v", err)
+	p.eof = true
+}
+
+// line returns the text of line i, without its delimiter, cut short at
+// maxLineBytes.
+func (p *pager) line(i int) string {
+	start, end := p.starts[i], p.scanned
+	if i+1 < len(p.starts) {
+		end = p.starts[i+1] - 1
+	}
+	buf := make([]byte, min(end-start, maxLineBytes))
+	if _, err := p.f.Seek(start, io.SeekStart); err != nil {
+		p.fail(err)
+		return ""
+	}
+	if _, err := io.ReadFull(p.f, buf); err != nil {
+		p.fail(err)
+		return ""
+	}
+	return strings.TrimSuffix(string(buf), "\r")
+}
+
+// refresh checks the file for growth or truncation, reporting whether the
+// screen needs redrawing. A truncated file is indexed again from the start.
+func (p *pager) refresh() bool {
+	info, err := p.f.Stat()
+	if err != nil {
+		return false
+	}
+	switch size := info.Size(); {
+	case size < p.scanned:
+		p.starts, p.scanned, p.eof, p.top = []int64{0}, 0, false, 0
+	case size > p.scanned && p.eof:
+		p.eof = false
+	default:
+		return false
+	}
+	if p.following {
+		p.end()
+	}
+	return true
+}
+
+// end indexes the whole file and shows its last page.
+func (p *pager) end() {
+	p.index(math.MaxInt)
+	p.top = max(0, p.count()-p.rows())
+}
+
+// scroll moves the screen n lines down, or up for negative n, stopping at
+// the first line and at the last full page.
+func (p *pager) scroll(n int) {
+	rows := p.rows()
+	p.index(p.top + n + rows)
+	p.top = max(0, min(p.top+n, p.count()-rows))
+}
+
+// search moves the screen to the next line after the top one matching the
+// last pattern.
+func (p *pager) search() {
+	if p.pattern == nil {
+		p.message = "no previous search"
+		return
+	}
+	for i := p.top + 1; ; i++ {
+		p.index(i + 1)
+		if i >= p.count() {
+			p.message = "pattern not found"
+			return
+		}
+		if p.pattern.MatchString(p.line(i)) {
+			p.top = i
+			return
+		}
+	}
+}
+
+// key acts on a key press, reporting whether it was one to quit.
+func (p *pager) key(k byte) (quit bool) {
+	p.message = ""
+	if p.prompting {
+		p.promptKey(k)
+		return false
+	}
+
+	// Any key but G stops following, so the user can look around
+	p.following = false
+	switch k {
+	case 'q', ctrlC:
+		return true
+	case ' ', 'f':
+		p.scroll(p.rows())
+	case 'b':
+		p.scroll(-p.rows())
+	case 'j', '\r', '\n':
+		p.scroll(1)
+	case 'k':
+		p.scroll(-1)
+	case 'g':
+		p.top = 0
+	case 'G':
+		p.end()
+		p.following = true
+	case '/':
+		p.prompting = true
+		p.input = p.input[:0]
+	case 'n':
+		p.search()
+	}
+	return false
+}
+
+// promptKey edits the search pattern being typed. Enter searches, with the
+// previous pattern if none was typed; Escape or Ctrl+C cancels.
+func (p *pager) promptKey(k byte) {
+	switch k {
+	case '\r', '\n':
+		p.prompting = false
+		if len(p.input) > 0 {
+			re, err := regexp.Compile(string(p.input))
+			if err != nil {
+				p.message = fmt.Sprintf("invalid pattern: %v", err)
+				return
+			}
+			p.pattern = re
+		}
+		p.search()
+	case escape, ctrlC:
+		p.prompting = false
+	case backspace, del:
+		if len(p.input) > 0 {
+			_, n := utf8.DecodeLastRune(p.input)
+			p.input = p.input[:len(p.input)-n]
+		}
+	default:
+		if k >= ' ' {
+			p.input = append(p.input, k)
+		}
+	}
+}
+
+// screen returns the lines to show, each fitted to the terminal's width,
+// with the status line last. Rows past the end of the file show "~".
+func (p *pager) screen() []string {
+	width, _ := p.size()
+	rows := p.rows()
+	p.index(p.top + rows)
+
+	lines := make([]string, 0, rows+1)
+	for i := p.top; i < p.top+rows; i++ {
+		if i < p.count() {
+			lines = append(lines, fit(p.line(i), width))
+		} else {
+			lines = append(lines, "~")
+		}
+	}
+	return append(lines, fit(p.status(rows), width))
+}
+
+// status returns the status line for a screen of rows lines.
+func (p *pager) status(rows int) string {
+	switch {
+	case p.prompting:
+		return "/" + string(p.input)
+	case p.message != "":
+		return p.message
+	case p.count() == 0:
+		return p.name + "  (empty)"
+	}
+
+	last := min(p.top+rows, p.count())
+	s := fmt.Sprintf("%s  lines %d-%d", p.name, p.top+1, last)
+	if p.eof {
+		s += fmt.Sprintf(" of %d", p.count())
+	}
+	if p.following {
+		s += "  (following)"
+	} else if p.eof && last == p.count() {
+		s += "  (END)"
+	}
+	return s
+}
+
+// draw redraws the whole screen.
+func (p *pager) draw() error {
+	p.width, p.height = p.size()
+	lines := p.screen()
+
+	var b strings.Builder
+	b.WriteString(clearScreen)
+	for _, line := range lines[:len(lines)-1] {
+		b.WriteString(line)
+		b.WriteString("\r\n")
+	}
+	status := lines[len(lines)-1]
+	if p.prompting {
+		b.WriteString(status) // The cursor is left after the pattern
+	} else {
+		b.WriteString(reverseVideo + status + resetVideo)
+	}
+	_, err := io.WriteString(p.out, b.String())
+	return err
+}
+
+// resized reports whether the terminal's size has changed since the last draw.
+func (p *pager) resized() bool {
+	width, height := p.size()
+	return width != p.width || height != p.height
+}
+
+// run draws the screen and acts on keys until one quits, keys is closed or
+// ctx is cancelled. Every interval the file is checked for changes and the
+// terminal for a new size.
+func (p *pager) run(ctx context.Context, keys <-chan byte, interval time.Duration) error {
+	if err := p.draw(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case k, ok := <-keys:
+			if !ok || p.key(k) {
+				return nil
+			}
+		case <-ticker.C:
+			if !p.refresh() && !p.resized() {
+				continue
+			}
+		}
+		if err := p.draw(); err != nil {
+			return err
+		}
+	}
+}
+
+// fit prepares text for one row of a terminal width columns wide: tabs are
+// expanded, other control characters (which could drive the terminal) are
+// shown as '?', and anything past the last column is cut.
+func fit(text string, width int) string {
+	var b strings.Builder
+	col := 0
+	for _, r := range text {
+		if col >= width {
+			break
+		}
+		switch {
+		case r == '\t':
+			n := min(tabWidth-col%tabWidth, width-col)
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+			continue
+		case r < ' ' || r == del || (r >= 0x80 && r < 0xa0):
+			r = '?'
+		}
+		b.WriteRune(r)
+		col++
+	}
+	return b.String()
+}
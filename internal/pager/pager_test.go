@@ -0,0 +1,185 @@
+package pager
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmurray2011/wail/internal/filesystem"
+)
+
+// testPager returns a pager over a file holding content, on a screen of
+// three lines and a status line, 40 columns wide.
+func testPager(t *testing.T, content string) (*pager, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	f, err := filesystem.NewFileOpener().Open(path)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return newPager(f, "test.log", &bytes.Buffer{}, func() (int, int) { return 40, 4 }), path
+}
+
+// numbered returns lines "line1" to "lineN", each with a newline.
+func numbered(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "line%d\n", i)
+	}
+	return b.String()
+}
+
+// press sends each key in keys to p.
+func press(p *pager, keys string) {
+	for _, k := range []byte(keys) {
+		p.key(k)
+	}
+}
+
+// checkScreen compares the lines on p's screen, and its status line, with
+// want and a substring of the status.
+func checkScreen(t *testing.T, p *pager, want []string, status string) {
+	t.Helper()
+	screen := p.screen()
+	if got := screen[:len(screen)-1]; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("screen = %q, want %q", got, want)
+	}
+	if got := screen[len(screen)-1]; !strings.Contains(got, status) {
+		t.Errorf("status = %q, want it to contain %q", got, status)
+	}
+}
+
+func TestPager_Paging(t *testing.T) {
+	p, _ := testPager(t, numbered(10))
+
+	checkScreen(t, p, []string{"line1", "line2", "line3"}, "lines 1-3")
+	press(p, " ")
+	checkScreen(t, p, []string{"line4", "line5", "line6"}, "lines 4-6")
+	press(p, "jj")
+	checkScreen(t, p, []string{"line6", "line7", "line8"}, "lines 6-8")
+	press(p, "b")
+	checkScreen(t, p, []string{"line3", "line4", "line5"}, "lines 3-5")
+	press(p, "G")
+	checkScreen(t, p, []string{"line8", "line9", "line10"}, "(following)")
+	press(p, "   ") // Past the end stays on the last page
+	checkScreen(t, p, []string{"line8", "line9", "line10"}, "of 10  (END)")
+	press(p, "g")
+	checkScreen(t, p, []string{"line1", "line2", "line3"}, "lines 1-3")
+	press(p, "kb") // Before the start stays on the first page
+	checkScreen(t, p, []string{"line1", "line2", "line3"}, "lines 1-3")
+}
+
+func TestPager_ShortFile(t *testing.T) {
+	p, _ := testPager(t, "only\r\nlast without newline")
+	checkScreen(t, p, []string{"only", "last without newline", "~"}, "of 2  (END)")
+
+	empty, _ := testPager(t, "")
+	checkScreen(t, empty, []string{"~", "~", "~"}, "(empty)")
+}
+
+func TestPager_Search(t *testing.T) {
+	p, _ := testPager(t, numbered(10)+"line7 again\n")
+
+	press(p, "/line7\r")
+	checkScreen(t, p, []string{"line7", "line8", "line9"}, "lines 7-9")
+	press(p, "n")
+	checkScreen(t, p, []string{"line7 again", "~", "~"}, "lines 11-11")
+	press(p, "n")
+	checkScreen(t, p, []string{"line7 again", "~", "~"}, "pattern not found")
+
+	// A bare Enter repeats the last search; Backspace edits the pattern
+	press(p, "g/\r")
+	checkScreen(t, p, []string{"line7", "line8", "line9"}, "lines 7-9")
+	press(p, "g/line99\x7f\x7f3\r")
+	checkScreen(t, p, []string{"line3", "line4", "line5"}, "lines 3-5")
+
+	press(p, "/(\r")
+	checkScreen(t, p, []string{"line3", "line4", "line5"}, "invalid pattern")
+	press(p, "/line9\x1b")
+	checkScreen(t, p, []string{"line3", "line4", "line5"}, "lines 3-5")
+}
+
+func TestPager_Follow(t *testing.T) {
+	p, path := testPager(t, numbered(5))
+	appendLines := func(s string) {
+		f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		f.WriteString(s)
+		f.Close()
+	}
+
+	press(p, "G")
+	appendLines("line6\nline7\n")
+	if !p.refresh() {
+		t.Fatal("refresh() = false after the file grew")
+	}
+	checkScreen(t, p, []string{"line5", "line6", "line7"}, "(following)")
+
+	// Once the user looks around, growth doesn't move the screen
+	press(p, "g")
+	appendLines("line8\n")
+	p.refresh()
+	checkScreen(t, p, []string{"line1", "line2", "line3"}, "lines 1-3")
+	press(p, "G")
+	checkScreen(t, p, []string{"line6", "line7", "line8"}, "(following)")
+
+	// A truncated file is read again from the start
+	os.WriteFile(path, []byte("new1\n"), 0644)
+	p.refresh()
+	checkScreen(t, p, []string{"new1", "~", "~"}, "(following)")
+}
+
+func TestPager_Run(t *testing.T) {
+	p, _ := testPager(t, numbered(10))
+	var out bytes.Buffer
+	p.out = &out
+
+	keys := make(chan byte, 2)
+	keys <- ' '
+	keys <- 'q'
+	done := make(chan error, 1)
+	go func() {
+		done <- p.run(context.Background(), keys, 10*time.Millisecond)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("run() error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("run() did not return after q")
+	}
+	if got := out.String(); !strings.Contains(got, "line1\r\n") || !strings.Contains(got, "line4\r\n") {
+		t.Errorf("output %q is missing the first or second page", got)
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"much too long", 8, "much too"},
+		{"a\tb", 20, "a       b"},
+		{"a\tb", 4, "a   "},
+		{"bell\x07 and \x1b[2Jclear", 30, "bell? and ?[2Jclear"},
+		{"héllo wörld", 5, "héllo"},
+	}
+
+	for _, tt := range tests {
+		if got := fit(tt.text, tt.width); got != tt.want {
+			t.Errorf("fit(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
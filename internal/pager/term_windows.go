@@ -0,0 +1,47 @@
+//go:build windows
+
+package pager
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// setupTerminal turns off line input, echo and Ctrl+C handling on the
+// console in, so each key arrives as it is pressed, and turns on escape
+// sequence handling on out. It returns a function that restores both.
+func setupTerminal(in, out *os.File) (restore func(), err error) {
+	inHandle, outHandle := windows.Handle(in.Fd()), windows.Handle(out.Fd())
+
+	var inMode, outMode uint32
+	if err := windows.GetConsoleMode(inHandle, &inMode); err != nil {
+		return nil, err
+	}
+	if err := windows.GetConsoleMode(outHandle, &outMode); err != nil {
+		return nil, err
+	}
+
+	raw := inMode &^ (windows.ENABLE_ECHO_INPUT | windows.ENABLE_PROCESSED_INPUT | windows.ENABLE_LINE_INPUT)
+	if err := windows.SetConsoleMode(inHandle, raw); err != nil {
+		return nil, err
+	}
+	if err := windows.SetConsoleMode(outHandle, outMode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING); err != nil {
+		windows.SetConsoleMode(inHandle, inMode)
+		return nil, err
+	}
+	return func() {
+		windows.SetConsoleMode(outHandle, outMode)
+		windows.SetConsoleMode(inHandle, inMode)
+	}, nil
+}
+
+// terminalSize returns the columns and rows in the visible window of the
+// console f refers to.
+func terminalSize(f *os.File) (width, height int, ok bool) {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(f.Fd()), &info); err != nil {
+		return 0, 0, false
+	}
+	return int(info.Window.Right-info.Window.Left) + 1, int(info.Window.Bottom-info.Window.Top) + 1, true
+}
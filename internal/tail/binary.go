@@ -0,0 +1,50 @@
+package tail
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/jmurray2011/wail/internal/filesystem"
+)
+
+// BinaryFiles says what Tail does with a file that looks binary: one with a
+// NUL byte in its first binarySniffSize bytes, as grep judges it.
+type BinaryFiles int
+
+const (
+	// BinaryText outputs a binary file like any other, as GNU tail does.
+	BinaryText BinaryFiles = iota
+	// BinarySkip outputs nothing for a binary file.
+	BinarySkip
+	// BinaryWarn outputs nothing for a binary file and says so on Stderr.
+	BinaryWarn
+)
+
+// binarySniffSize is how much of the start of a file is checked for NULs.
+const binarySniffSize = 8 * 1024
+
+// skipBinary reports whether f is to be left alone as a binary file, warning
+// about it with BinaryWarn. With ZeroTerminated, NULs are line delimiters, so
+// no file is binary. f is left at its start.
+func (t *tailer) skipBinary(f filesystem.ReadSeekCloser) (bool, error) {
+	if t.config.BinaryFiles == BinaryText || t.config.ZeroTerminated {
+		return false, nil
+	}
+	buf := make([]byte, binarySniffSize)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, fmt.Errorf("reading: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return false, fmt.Errorf("seeking: %w", err)
+	}
+	if bytes.IndexByte(buf[:n], 0) < 0 {
+		return false, nil
+	}
+	t.debugf("NUL in the first %d bytes, skipping as binary", n)
+	if t.config.BinaryFiles == BinaryWarn {
+		t.diagnose("binary file; use --text to force")
+	}
+	return true, nil
+}
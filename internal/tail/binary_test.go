@@ -0,0 +1,58 @@
+package tail
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTailer_BinaryFiles(t *testing.T) {
+	dir := t.TempDir()
+	binaryFile := filepath.Join(dir, "app.bin")
+	os.WriteFile(binaryFile, []byte("head\x00\x01\x02\nline2\n"), 0644)
+	textFile := filepath.Join(dir, "app.log")
+	os.WriteFile(textFile, []byte("line1\nline2\n"), 0644)
+
+	tests := []struct {
+		name       string
+		path       string
+		mode       BinaryFiles
+		zero       bool
+		wantOut    string
+		wantStderr string
+	}{
+		{"text outputs it", binaryFile, BinaryText, false, "head\x00\x01\x02\nline2\n", ""},
+		{"skip outputs nothing", binaryFile, BinarySkip, false, "", ""},
+		{"warn says why", binaryFile, BinaryWarn, false, "", "binary file; use --text to force"},
+		{"NULs delimit lines with -z", binaryFile, BinaryWarn, true, "head\x00\x01\x02\nline2\n\x00", ""},
+		{"text files are untouched", textFile, BinaryWarn, false, "line1\nline2\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, stderr bytes.Buffer
+			tailer := NewTailer(TailerConfig{
+				Path:           tt.path,
+				Lines:          10,
+				BinaryFiles:    tt.mode,
+				ZeroTerminated: tt.zero,
+				Stderr:         &stderr,
+			})
+			if err := tailer.Tail(context.Background(), &out); err != nil {
+				t.Fatalf("Tail() error: %v", err)
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("output = %q, want %q", got, tt.wantOut)
+			}
+			if tt.wantStderr == "" && stderr.Len() > 0 {
+				t.Errorf("stderr = %q, want nothing", stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
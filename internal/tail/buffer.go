@@ -0,0 +1,117 @@
+package tail
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sync"
+)
+
+// outputBufferSize is how much output Tail holds before writing it out
+// regardless of the FlushMode.
+const outputBufferSize = 64 * 1024
+
+// FlushMode selects when Tail writes buffered output through to its writer.
+type FlushMode int
+
+const (
+	// FlushBatch writes out everything read in one poll together, once the
+	// poll's lines have all been handled. Initial output is written when it
+	// is complete, or as the buffer fills.
+	FlushBatch FlushMode = iota
+	// FlushLine writes every line as soon as it is emitted, unbuffered:
+	// the lowest latency, at the cost of a write per line.
+	FlushLine
+	// FlushTimed writes out every FlushEvery, on a timer of its own, so a
+	// busy file is written in a few large writes however often it is polled.
+	FlushTimed
+)
+
+// outputBuffer batches writes to an io.Writer. A FlushTimed timer flushes it
+// from another goroutine, so access is serialised.
+type outputBuffer struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.w.Write(p)
+}
+
+// Flush writes out everything buffered.
+func (b *outputBuffer) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.w.Flush()
+}
+
+// buffered wraps output as OutputFlush says. It returns the writer for Tail
+// to use and a function to call when tailing stops, which stops any flush
+// timer and writes out whatever is left, so nothing is lost on exit.
+//
+// Rate-limited output is never batched: the limiter spaces lines out, and
+// holding them back for a whole poll would undo that.
+func (t *tailer) buffered(ctx context.Context, output io.Writer) (io.Writer, func() error) {
+	mode := t.config.OutputFlush
+	if mode == FlushLine || (mode == FlushBatch && t.config.MaxRate > 0) || (mode == FlushTimed && t.config.FlushEvery <= 0) {
+		return output, func() error { return nil }
+	}
+
+	b := &outputBuffer{w: bufio.NewWriterSize(output, outputBufferSize)}
+	t.out = b
+	done := func() error {
+		t.out = nil
+		return b.Flush()
+	}
+	if mode == FlushBatch {
+		return b, done
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := newPollTicker(t.clock, t.config.FlushEvery, 0)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				b.Flush()
+			}
+		}
+	}()
+	return b, func() error {
+		cancel()
+		<-stopped
+		return done()
+	}
+}
+
+// flushBatch writes out the output buffered since the last poll, when
+// batching by poll. Follow loops call it each time they go back to waiting.
+func (t *tailer) flushBatch() error {
+	if t.out == nil || t.config.OutputFlush != FlushBatch {
+		return nil
+	}
+	return t.out.Flush()
+}
+
+// unbatched returns emit flushing after each line when batching by poll,
+// for inputs that are read as lines arrive rather than polled, such as
+// pipes and event logs, whose lines would otherwise wait for the buffer to
+// fill.
+func (t *tailer) unbatched(emit LineFunc) LineFunc {
+	if t.out == nil || t.config.OutputFlush != FlushBatch {
+		return emit
+	}
+	return func(line Line) error {
+		if err := emit(line); err != nil {
+			return err
+		}
+		return t.flushBatch()
+	}
+}
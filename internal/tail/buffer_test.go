@@ -0,0 +1,202 @@
+package tail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeRecorder records each write to it separately.
+type writeRecorder struct {
+	mu     sync.Mutex
+	writes []string
+}
+
+func (r *writeRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writes = append(r.writes, string(p))
+	return len(p), nil
+}
+
+func (r *writeRecorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.writes...)
+}
+
+// waitForWrites waits for r to have been written to n times.
+func (r *writeRecorder) waitForWrites(t *testing.T, n int) []string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if got := r.get(); len(got) >= n {
+			return got
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got writes %q, want %d", r.get(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestTailer_OutputFlush(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(testFile, []byte("one\ntwo\nthree\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		mode FlushMode
+		want []string
+	}{
+		{"batch", FlushBatch, []string{"one\ntwo\nthree\n"}},
+		{"line", FlushLine, []string{"one\n", "two\n", "three\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rec writeRecorder
+			tailer := NewTailer(TailerConfig{Path: testFile, Lines: 10, OutputFlush: tt.mode})
+			if err := tailer.Tail(context.Background(), &rec); err != nil {
+				t.Fatalf("Tail() error: %v", err)
+			}
+			if got := rec.get(); !slices.Equal(got, tt.want) {
+				t.Errorf("writes = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTailer_OutputFlush_BatchPerPoll(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(testFile, []byte("initial\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	clk := newFakeClock()
+	tailer := NewTailer(TailerConfig{
+		Path:         testFile,
+		Lines:        10,
+		Follow:       true,
+		ForcePoll:    true,
+		PollInterval: 10 * time.Millisecond,
+	}).(*tailer)
+	tailer.clock = clk
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var rec writeRecorder
+	done := make(chan error, 1)
+	go func() {
+		done <- tailer.Tail(ctx, &rec)
+	}()
+
+	// The initial lines go out before following starts
+	rec.waitForWrites(t, 1)
+	clk.waitForWaiters(t, 1)
+
+	f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	f.WriteString("a\nb\nc\n")
+	f.Close()
+
+	// One poll reads all three lines, which are written together
+	clk.advance(10 * time.Millisecond)
+	got := rec.waitForWrites(t, 2)
+	want := []string{"initial\n", "a\nb\nc\n"}
+	if !slices.Equal(got, want) {
+		t.Errorf("writes = %q, want %q", got, want)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Tail() error: %v", err)
+	}
+}
+
+func TestTailer_OutputFlush_LineWhileFollowing(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(testFile, []byte("initial\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	clk := newFakeClock()
+	tailer := NewTailer(TailerConfig{
+		Path:         testFile,
+		Lines:        10,
+		Follow:       true,
+		ForcePoll:    true,
+		PollInterval: 10 * time.Millisecond,
+		OutputFlush:  FlushLine,
+	}).(*tailer)
+	tailer.clock = clk
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var rec writeRecorder
+	done := make(chan error, 1)
+	go func() {
+		done <- tailer.Tail(ctx, &rec)
+	}()
+	rec.waitForWrites(t, 1)
+	clk.waitForWaiters(t, 1)
+
+	f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	f.WriteString("a\nb\nc\n")
+	f.Close()
+
+	// The lines one poll reads are each written as soon as they are read,
+	// rather than together at the end of the poll
+	clk.advance(10 * time.Millisecond)
+	got := rec.waitForWrites(t, 4)
+	want := []string{"initial\n", "a\n", "b\n", "c\n"}
+	if !slices.Equal(got, want) {
+		t.Errorf("writes = %q, want %q", got, want)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Tail() error: %v", err)
+	}
+}
+
+func TestTailer_OutputFlush_Timed(t *testing.T) {
+	clk := newFakeClock()
+	tailer := NewTailer(TailerConfig{OutputFlush: FlushTimed, FlushEvery: 200 * time.Millisecond}).(*tailer)
+	tailer.clock = clk
+
+	var rec writeRecorder
+	w, done := tailer.buffered(context.Background(), &rec)
+	w.Write([]byte("one\n"))
+	w.Write([]byte("two\n"))
+
+	// Nothing is written until the timer fires
+	clk.waitForWaiters(t, 1)
+	clk.advance(100 * time.Millisecond)
+	if got := rec.get(); len(got) != 0 {
+		t.Fatalf("writes before the interval: %q", got)
+	}
+	clk.advance(100 * time.Millisecond)
+	rec.waitForWrites(t, 1)
+
+	// What is left goes out when tailing stops
+	w.Write([]byte("three\n"))
+	if err := done(); err != nil {
+		t.Fatalf("done() error: %v", err)
+	}
+	want := []string{"one\ntwo\n", "three\n"}
+	if got := rec.get(); !slices.Equal(got, want) {
+		t.Errorf("writes = %q, want %q", got, want)
+	}
+}
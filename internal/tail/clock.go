@@ -0,0 +1,35 @@
+package tail
+
+import "time"
+
+// clock is where follow and retry loops get the time and their tickers
+// from. NewTailer uses the real clock; tests swap in one they advance by
+// hand, so a loop can be stepped one poll at a time without sleeping.
+type clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) ticker
+	After(d time.Duration) <-chan time.Time
+}
+
+// ticker is the part of time.Ticker the loops use.
+type ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// realClock is the system clock.
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func (realClock) NewTicker(d time.Duration) ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (rt realTicker) C() <-chan time.Time { return rt.t.C }
+func (rt realTicker) Stop()               { rt.t.Stop() }
@@ -0,0 +1,139 @@
+package tail
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock that only moves when advance is called, firing the
+// tickers and After channels that fall due, so a test steps a follow loop
+// one poll at a time instead of sleeping and hoping it has polled.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeTimer
+}
+
+// fakeTimer is one ticker or After channel on a fakeClock.
+type fakeTimer struct {
+	clock  *fakeClock
+	when   time.Time
+	period time.Duration // 0 for After, which fires once
+	c      chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	return c.add(d, 0).c
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) ticker {
+	return c.add(d, d)
+}
+
+func (c *fakeClock) add(d, period time.Duration) *fakeTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ft := &fakeTimer{clock: c, when: c.now.Add(d), period: period, c: make(chan time.Time, 1)}
+	c.waiters = append(c.waiters, ft)
+	return ft
+}
+
+func (ft *fakeTimer) C() <-chan time.Time { return ft.c }
+
+func (ft *fakeTimer) Stop() {
+	c := ft.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waiters = slices.DeleteFunc(c.waiters, func(w *fakeTimer) bool { return w == ft })
+}
+
+// advance moves the clock on by d and fires everything due. As with
+// time.Ticker, a ticker whose last tick hasn't been received drops the new one.
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.waiters = slices.DeleteFunc(c.waiters, func(ft *fakeTimer) bool {
+		if ft.when.After(c.now) {
+			return false
+		}
+		select {
+		case ft.c <- c.now:
+		default:
+		}
+		if ft.period == 0 {
+			return true
+		}
+		for !ft.when.After(c.now) {
+			ft.when = ft.when.Add(ft.period)
+		}
+		return false
+	})
+}
+
+// waitForWaiters blocks until at least n tickers and After channels are
+// waiting on the clock, i.e. the code under test has reached its loop, and
+// fails the test if that takes more than a few seconds.
+func (c *fakeClock) waitForWaiters(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		c.mu.Lock()
+		got := len(c.waiters)
+		c.mu.Unlock()
+		if got >= n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d timers waiting on the fake clock, want %d", got, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestFakeClock(t *testing.T) {
+	c := newFakeClock()
+	tk := c.NewTicker(10 * time.Millisecond)
+	after := c.After(25 * time.Millisecond)
+
+	c.advance(5 * time.Millisecond)
+	select {
+	case <-tk.C():
+		t.Fatal("ticker fired early")
+	default:
+	}
+
+	// Two periods pass, but an unreceived tick isn't queued behind another
+	c.advance(15 * time.Millisecond)
+	<-tk.C()
+	select {
+	case <-tk.C():
+		t.Fatal("ticker queued a second tick")
+	default:
+	}
+
+	c.advance(5 * time.Millisecond)
+	if got := <-after; !got.Equal(c.Now()) {
+		t.Errorf("After fired with %v, want %v", got, c.Now())
+	}
+
+	tk.Stop()
+	c.advance(time.Second)
+	select {
+	case <-tk.C():
+		t.Fatal("tick after Stop")
+	default:
+	}
+}
@@ -0,0 +1,45 @@
+package tail
+
+import (
+	"strings"
+	"time"
+)
+
+// contentWait is a line WaitForContent is holding back: where it starts, and
+// when it was first read with NULs in it.
+type contentWait struct {
+	offset int64
+	since  time.Time
+}
+
+// awaitContent reports whether line is to be held back, for WaitForContent,
+// as not yet written: it has NUL bytes in it, as a file extended ahead of
+// its writer reads back before the hole is filled in. Once a line at the
+// same offset has been held for WaitForContent it is let through NULs and
+// all, so a log that really does contain NULs is only delayed.
+func (t *tailer) awaitContent(line Line) bool {
+	if t.config.WaitForContent <= 0 || t.config.ZeroTerminated || !strings.Contains(line.Text, "\x00") {
+		t.waiting = nil
+		return false
+	}
+	now := t.clock.Now()
+	if t.waiting == nil || t.waiting.offset != line.Offset {
+		t.debugf("NUL bytes at offset %d, waiting for them to be written", line.Offset)
+		t.waiting = &contentWait{offset: line.Offset, since: now}
+	}
+	if now.Sub(t.waiting.since) < t.config.WaitForContent {
+		return true
+	}
+	t.debugf("gave up waiting for the NUL bytes at offset %d after %v", line.Offset, t.config.WaitForContent)
+	t.waiting = nil
+	return false
+}
+
+// holding reports whether partial is a line awaitContent is holding back.
+// Such a line is read again at every poll, whether or not the file has grown,
+// since a hole is filled in without changing the file's size; and it is
+// never output when following stops or moves to another file, since what it
+// holds was never written.
+func (t *tailer) holding(partial *Line) bool {
+	return partial != nil && t.waiting != nil && t.waiting.offset == partial.Offset
+}
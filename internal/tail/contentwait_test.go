@@ -0,0 +1,103 @@
+package tail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTailer_WaitForContent(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "app.log")
+	os.WriteFile(testFile, []byte("initial\n"), 0644)
+
+	clk := newFakeClock()
+	var debug lockedBuffer
+	tailer := NewTailer(TailerConfig{
+		Path:           testFile,
+		Lines:          10,
+		Follow:         true,
+		ForcePoll:      true,
+		PollInterval:   10 * time.Millisecond,
+		WaitForContent: time.Second,
+		Debug:          true,
+		Stderr:         &debug,
+	}).(*tailer)
+	tailer.clock = clk
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lines := make(chan string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- tailer.TailFunc(ctx, func(line Line) error {
+			lines <- line.Text
+			return nil
+		})
+	}()
+	next := func() string {
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(5 * time.Second):
+			t.Fatal("no line output")
+			return ""
+		}
+	}
+	// waitFor waits for the tailer to have traced msg, i.e. to have polled
+	// and seen what the test wrote
+	waitFor := func(msg string) {
+		deadline := time.Now().Add(5 * time.Second)
+		for !strings.Contains(debug.String(), msg) {
+			if time.Now().After(deadline) {
+				t.Fatalf("debug output = %q, want %q", debug.String(), msg)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	if got := next(); got != "initial" {
+		t.Fatalf("first line = %q, want %q", got, "initial")
+	}
+	clk.waitForWaiters(t, 1)
+
+	// The writer extends the file with a hole and writes the end of its
+	// line first: the newline after five NULs reads as a complete line
+	f, err := os.OpenFile(testFile, os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	defer f.Close()
+	f.Truncate(14)
+	f.WriteAt([]byte("\n"), 13)
+	clk.advance(10 * time.Millisecond)
+	waitFor("NUL bytes at offset 8")
+
+	// Filling the hole leaves the size alone, and the line comes out whole
+	f.WriteAt([]byte("hello"), 8)
+	clk.advance(10 * time.Millisecond)
+	if got := next(); got != "hello" {
+		t.Fatalf("filled line = %q, want %q", got, "hello")
+	}
+
+	// A line that really has a NUL in it is only delayed
+	f.WriteAt([]byte("a\x00b\n"), 14)
+	clk.advance(10 * time.Millisecond)
+	waitFor("NUL bytes at offset 14")
+	clk.advance(time.Second)
+	if got := next(); got != "a\x00b" {
+		t.Fatalf("line with a NUL = %q, want %q", got, "a\x00b")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("TailFunc() error = %v", err)
+	}
+	select {
+	case line := <-lines:
+		t.Errorf("unexpected line %q", line)
+	default:
+	}
+}
@@ -0,0 +1,80 @@
+package tail
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmurray2011/wail/internal/eventlog"
+)
+
+// tailEventLog outputs the last Lines events of an Event Log channel, one
+// line each, then follows new events if configured. A line's Offset is the
+// event's record ID.
+func (t *tailer) tailEventLog(ctx context.Context, channel string, emit LineFunc) error {
+	if t.byteMode() || t.config.FromStart || t.config.AllButLast || t.config.HeadTail > 0 {
+		return fmt.Errorf("event logs support only -n N")
+	}
+
+	r, err := t.events(channel)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	n := t.config.Lines
+	if n <= 0 && !t.config.LinesSet {
+		n = 10
+	}
+
+	// With -n 0, fetch the newest event anyway to learn where following starts
+	events, err := r.Last(max(n, 1))
+	if err != nil {
+		return err
+	}
+	var after uint64
+	if len(events) > 0 {
+		after = events[len(events)-1].RecordID
+	}
+	events = events[len(events)-min(n, len(events)):]
+
+	for _, ev := range events {
+		if err := emit(t.eventLine(ev, true)); err != nil {
+			return err
+		}
+	}
+
+	if !t.config.Follow {
+		return nil
+	}
+	if t.config.LiveMarker != "" && len(events) > 0 {
+		if err := emit(t.markerLine(int64(after))); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if exited := t.watchProcesses(ctx); exited != nil {
+		go func() {
+			select {
+			case <-exited:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	// Events arrive one at a time, not in polls, so each goes out as it comes
+	if err := t.flushBatch(); err != nil {
+		return err
+	}
+	emit = t.unbatched(emit)
+	return r.Follow(ctx, after, func(ev eventlog.Event) error {
+		return emit(t.eventLine(ev, false))
+	})
+}
+
+// eventLine converts an event to a Line.
+func (t *tailer) eventLine(ev eventlog.Event, initial bool) Line {
+	return t.line(ev.String(), int64(ev.RecordID), initial)
+}
@@ -0,0 +1,126 @@
+package tail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmurray2011/wail/internal/eventlog"
+)
+
+// fakeEventLog is an eventlog.Reader over a fixed set of events, delivering
+// live ones through a channel.
+type fakeEventLog struct {
+	events []eventlog.Event
+	live   chan eventlog.Event
+	after  uint64 // As passed to Follow
+}
+
+func (f *fakeEventLog) Last(n int) ([]eventlog.Event, error) {
+	return f.events[len(f.events)-min(n, len(f.events)):], nil
+}
+
+func (f *fakeEventLog) Follow(ctx context.Context, after uint64, fn func(eventlog.Event) error) error {
+	f.after = after
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev := <-f.live:
+			if err := fn(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (f *fakeEventLog) Close() error { return nil }
+
+func newFakeEventLog(count int) *fakeEventLog {
+	f := &fakeEventLog{live: make(chan eventlog.Event, 1)}
+	for i := 1; i <= count; i++ {
+		f.events = append(f.events, eventlog.Event{RecordID: uint64(i), Provider: "test", Message: fmt.Sprintf("event %d", i)})
+	}
+	return f
+}
+
+func TestTailer_EventLog(t *testing.T) {
+	tests := []struct {
+		name   string
+		config TailerConfig
+		count  int
+		want   []string
+	}{
+		{"last N", TailerConfig{Lines: 2}, 5, []string{"event 4", "event 5"}},
+		{"default of 10", TailerConfig{}, 11, []string{"event 2", "event 3", "event 4", "event 5", "event 6", "event 7", "event 8", "event 9", "event 10", "event 11"}},
+		{"fewer than N", TailerConfig{Lines: 10}, 2, []string{"event 1", "event 2"}},
+		{"zero lines", TailerConfig{Lines: 0, LinesSet: true}, 5, nil},
+		{"empty log", TailerConfig{Lines: 10}, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Path = "evtlog://Test"
+			tl := NewTailer(tt.config).(*tailer)
+			tl.events = func(string) (eventlog.Reader, error) { return newFakeEventLog(tt.count), nil }
+
+			var got []string
+			err := tl.TailFunc(context.Background(), func(line Line) error {
+				_, msg, _ := strings.Cut(line.Text, "test: ")
+				got = append(got, msg)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("TailFunc() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTailer_EventLog_Follow(t *testing.T) {
+	log := newFakeEventLog(3)
+	tl := NewTailer(TailerConfig{Path: "evtlog://Test", Lines: 1, Follow: true, LiveMarker: "--"}).(*tailer)
+	tl.events = func(string) (eventlog.Reader, error) { return log, nil }
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var buf bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- tl.Tail(ctx, &buf)
+	}()
+
+	log.live <- eventlog.Event{RecordID: 4, Provider: "test", Message: "event 4"}
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Tail() error = %v", err)
+	}
+
+	if log.after != 3 {
+		t.Errorf("Follow after = %d, want 3", log.after)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[0], "test: event 3") || lines[1] != "--" || !strings.HasSuffix(lines[2], "test: event 4") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestTailer_EventLog_Unsupported(t *testing.T) {
+	for _, config := range []TailerConfig{{Bytes: 10}, {FromStart: true, Lines: 1}, {AllButLast: true, Lines: 1}} {
+		config.Path = "evtlog://Test"
+		tl := NewTailer(config).(*tailer)
+		tl.events = func(string) (eventlog.Reader, error) { return newFakeEventLog(1), nil }
+		if err := tl.Tail(context.Background(), &bytes.Buffer{}); err == nil {
+			t.Errorf("config %+v: expected an error", config)
+		}
+	}
+}
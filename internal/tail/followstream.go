@@ -0,0 +1,91 @@
+package tail
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// followStream emits the last Lines of what r delivers in its first
+// PollInterval, then each further line as soon as it arrives, for
+// FollowStream. A pipe can't seek, so the last lines can only be picked from
+// what has been written so far; once the interval is up the choice is made,
+// and a slow generator's lines come through as it writes them instead of
+// waiting for it to finish. It returns at the end of input, or when ctx is
+// cancelled, leaving a read in progress to end with the process.
+//
+// Modes that already stream, or that need the end of the input, read r as
+// TailReader otherwise would.
+func (t *tailer) followStream(ctx context.Context, r io.Reader, emit LineFunc) error {
+	if t.byteMode() || t.config.StartOffsetSet || t.config.FromStart || t.config.HeadTail > 0 || t.config.AllButLast {
+		return t.readStream(r, emit)
+	}
+	keep := t.config.Lines
+	if keep <= 0 && !t.config.LinesSet {
+		keep = 10
+	}
+
+	type read struct {
+		line Line
+		err  error
+	}
+	reads := make(chan read)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		lr := t.newLineReader(r)
+		for {
+			text, err := lr.ReadLine()
+			res := read{err: err}
+			if err == nil {
+				res.line = t.lineAt(lr, text, 0, true)
+			}
+			select {
+			case reads <- res:
+			case <-stop:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	// The initial lines are held until the interval is up; settled is nil
+	// from then on, and every line is live
+	var initial []Line
+	settled := t.clock.After(t.config.PollInterval)
+	for {
+		select {
+		case <-ctx.Done():
+			return t.emitLines(emit, initial)
+		case <-settled:
+			settled = nil
+			if err := t.emitLines(emit, initial); err != nil {
+				return err
+			}
+			initial = nil
+		case res := <-reads:
+			if res.err == io.EOF {
+				return t.emitLines(emit, initial)
+			}
+			if res.err != nil {
+				if err := t.emitLines(emit, initial); err != nil {
+					return err
+				}
+				return fmt.Errorf("reading lines: %w", res.err)
+			}
+			if settled == nil {
+				res.line.IsInitial = false
+				if err := emit(res.line); err != nil {
+					return err
+				}
+				continue
+			}
+			initial = append(initial, res.line)
+			if len(initial) > keep {
+				initial = initial[1:]
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package tail
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestTailer_FollowStream(t *testing.T) {
+	clk := newFakeClock()
+	tailer := NewTailer(TailerConfig{Lines: 2, FollowStream: true, PollInterval: 100 * time.Millisecond}).(*tailer)
+	tailer.clock = clk
+
+	pr, pw := io.Pipe()
+	var buf lockedBuffer
+	done := make(chan error, 1)
+	go func() {
+		done <- tailer.TailReader(context.Background(), pr, &buf)
+	}()
+
+	// Lines written before the interval is up are the initial block, of
+	// which only the last two are kept. Writing the start of the next line
+	// returns once the reader has handed over those before it.
+	clk.waitForWaiters(t, 1)
+	pw.Write([]byte("a\nb\nc\n"))
+	pw.Write([]byte("d"))
+	if got := buf.String(); got != "" {
+		t.Fatalf("output before the interval = %q, want none", got)
+	}
+	clk.advance(100 * time.Millisecond)
+	waitForOutput(t, &buf, "b\nc\n")
+
+	// From then on each line comes through as it is written, before the
+	// input ends
+	pw.Write([]byte("\n"))
+	waitForOutput(t, &buf, "b\nc\nd\n")
+	pw.Write([]byte("e\n"))
+	waitForOutput(t, &buf, "b\nc\nd\ne\n")
+
+	pw.Close()
+	if err := <-done; err != nil {
+		t.Fatalf("TailReader() error: %v", err)
+	}
+}
+
+func TestTailer_FollowStream_EndsEarly(t *testing.T) {
+	// Input that ends within the interval is tailed as usual
+	tailer := NewTailer(TailerConfig{Lines: 2, FollowStream: true, PollInterval: time.Hour})
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte("a\nb\nc\n"))
+		pw.Close()
+	}()
+
+	var buf lockedBuffer
+	if err := tailer.TailReader(context.Background(), pr, &buf); err != nil {
+		t.Fatalf("TailReader() error: %v", err)
+	}
+	if got := buf.String(); got != "b\nc\n" {
+		t.Errorf("output = %q, want %q", got, "b\nc\n")
+	}
+}
+
+// waitForOutput waits for buf to hold exactly want.
+func waitForOutput(t *testing.T, buf *lockedBuffer, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for buf.String() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("output = %q, want %q", buf.String(), want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
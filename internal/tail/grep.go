@@ -0,0 +1,83 @@
+package tail
+
+import "regexp"
+
+// contextSeparator is output between groups of context that aren't
+// contiguous, as in grep.
+const contextSeparator = "--"
+
+// grepFilter passes on lines matching a pattern. With before or after set it
+// also passes up to that many lines either side of each match, like grep -B
+// and -A, holding the most recent unmatched lines in a window in case a match
+// follows.
+type grepFilter struct {
+	emit    LineFunc
+	pattern *regexp.Regexp
+	before  int
+	after   int
+
+	window  []Line // Unmatched lines not yet output, oldest first
+	pending int    // Lines still to output after the last match
+	output  bool   // Something has been output, so a later gap needs a separator
+	gap     bool   // Lines were dropped since the last one output
+}
+
+// newGrepFilter returns a grepFilter passing matching lines, with before and
+// after lines of context, to emit.
+func newGrepFilter(emit LineFunc, pattern *regexp.Regexp, before, after int) *grepFilter {
+	return &grepFilter{emit: emit, pattern: pattern, before: before, after: after}
+}
+
+// line is the grepFilter's LineFunc.
+func (g *grepFilter) line(line Line) error {
+	if line.marker {
+		return g.emit(line)
+	}
+
+	if g.pattern.MatchString(line.Text) {
+		if g.gap && g.output && (g.before > 0 || g.after > 0) {
+			sep := line
+			sep.Text = contextSeparator
+			sep.marker = true
+			if err := g.emit(sep); err != nil {
+				return err
+			}
+		}
+		for _, l := range g.window {
+			l.context = true
+			if err := g.emit(l); err != nil {
+				return err
+			}
+		}
+		g.window = g.window[:0]
+		g.pending = g.after
+		g.output = true
+		g.gap = false
+		return g.emit(line)
+	}
+
+	if g.pending > 0 {
+		g.pending--
+		line.context = true
+		return g.emit(line)
+	}
+
+	if g.before == 0 {
+		g.gap = true
+		return nil
+	}
+	if len(g.window) == g.before {
+		g.window = append(g.window[:0], g.window[1:]...)
+		g.gap = true
+	}
+	g.window = append(g.window, line)
+	return nil
+}
+
+// reset forgets the context window and any after-context still owed, e.g.
+// after rotation, so context never spans two files.
+func (g *grepFilter) reset() {
+	g.window = g.window[:0]
+	g.pending = 0
+	g.gap = true
+}
@@ -0,0 +1,64 @@
+package tail
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestGrepFilter(t *testing.T) {
+	input := []string{"a", "ERR 1", "b", "c", "d", "ERR 2", "e", "ERR 3", "f"}
+
+	tests := []struct {
+		name          string
+		before, after int
+		want          []string
+	}{
+		{"matches only", 0, 0, []string{"ERR 1", "ERR 2", "ERR 3"}},
+		{"-A1", 0, 1, []string{"ERR 1", "b", "--", "ERR 2", "e", "ERR 3", "f"}},
+		{"-B1", 1, 0, []string{"a", "ERR 1", "--", "d", "ERR 2", "e", "ERR 3"}},
+		{"-C1", 1, 1, []string{"a", "ERR 1", "b", "--", "d", "ERR 2", "e", "ERR 3", "f"}},
+		{"-C2 groups merge", 2, 2, []string{"a", "ERR 1", "b", "c", "d", "ERR 2", "e", "ERR 3", "f"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			g := newGrepFilter(func(line Line) error {
+				got = append(got, line.Text)
+				return nil
+			}, regexp.MustCompile("ERR"), tt.before, tt.after)
+
+			for _, text := range input {
+				g.line(Line{Text: text})
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrepFilter_ResetsOnRotation(t *testing.T) {
+	var got []string
+	g := newGrepFilter(func(line Line) error {
+		got = append(got, line.Text)
+		return nil
+	}, regexp.MustCompile("ERR"), 1, 1)
+
+	// Context from the old file neither leads into nor trails after a
+	// match across the rotation
+	for _, text := range []string{"ERR old", "x"} {
+		g.line(Line{Text: text})
+	}
+	g.line(Line{Text: "old tail"})
+	g.reset()
+	for _, text := range []string{"ERR new", "y"} {
+		g.line(Line{Text: text})
+	}
+
+	want := []string{"ERR old", "x", "--", "ERR new", "y"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -3,9 +3,32 @@ package tail
 import (
 	"bufio"
 	"bytes"
+	"errors"
+	"fmt"
 	"io"
 )
 
+// ErrRecordTooLong is returned when no delimiter appears within maxLineSize
+// bytes, so the line (or NUL-terminated record) can't be read.
+var ErrRecordTooLong = errors.New("record too large")
+
+// LineEnding says how lines end in output, and so whether a CR before each
+// LF is read as part of the line ending or as content.
+type LineEnding int
+
+const (
+	// LineEndingLF reads LF and CRLF alike as line endings, and ends every
+	// line of output with LF.
+	LineEndingLF LineEnding = iota
+	// LineEndingCRLF reads line endings as LineEndingLF does, and ends every
+	// line of output with CRLF.
+	LineEndingCRLF
+	// LineEndingKeep splits lines on LF only, keeping any CR as content, and
+	// doesn't add a final line ending the input lacks, so output is
+	// byte-for-byte what was read.
+	LineEndingKeep
+)
+
 // LineReader reads lines from a source, handling both LF and CRLF endings.
 type LineReader interface {
 	// ReadLine reads the next line, stripping the line ending.
@@ -17,6 +40,10 @@ type LineReader interface {
 type lineReader struct {
 	scanner *bufio.Scanner
 	err     error
+	pos     int64 // bytes consumed from the source so far
+	start   int64 // offset of the most recently returned line
+	delim   bool  // whether the most recently returned line ended in a delimiter
+	nul     bool  // lines end in NUL rather than newline, for error messages
 }
 
 // maxLineSize is the maximum line length we support (1MB)
@@ -25,19 +52,46 @@ const maxLineSize = 1024 * 1024
 // NewLineReader creates a LineReader from an io.Reader.
 // It handles both LF and CRLF line endings transparently.
 func NewLineReader(r io.Reader) LineReader {
-	scanner := bufio.NewScanner(r)
-	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
-	scanner.Split(scanLinesWithCRLF)
-	return &lineReader{scanner: scanner}
+	return newLineReader(r, scanLinesWithCRLF, '\n')
 }
 
 // NewLineReaderWithDelimiter creates a LineReader with a custom delimiter byte.
 // Use '\x00' for NUL-terminated lines (-z flag).
 func NewLineReaderWithDelimiter(r io.Reader, delim byte) LineReader {
-	scanner := bufio.NewScanner(r)
-	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
-	scanner.Split(makeScanDelimited(delim))
-	return &lineReader{scanner: scanner}
+	lr := newLineReader(r, makeScanDelimited(delim), delim)
+	lr.nul = delim == '\x00'
+	return lr
+}
+
+// newLineReader creates a lineReader that splits with split and records the
+// byte offset of each line it returns. A line counts as terminated only if
+// split consumed delimiter after it; one that ran into the end of the data,
+// even with a CR stripped from its end, did not.
+func newLineReader(r io.Reader, split bufio.SplitFunc, delimiter byte) *lineReader {
+	lr := &lineReader{scanner: bufio.NewScanner(r)}
+	lr.scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+	lr.scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := split(data, atEOF)
+		if token != nil {
+			lr.start = lr.pos
+			lr.delim = advance > 0 && data[advance-1] == delimiter
+		}
+		lr.pos += int64(advance)
+		return advance, token, err
+	})
+	return lr
+}
+
+// offset returns the byte offset, relative to the start of the source, at
+// which the most recently returned line began.
+func (lr *lineReader) offset() int64 {
+	return lr.start
+}
+
+// terminated reports whether the most recently returned line ended in a
+// delimiter, rather than running into the end of the source.
+func (lr *lineReader) terminated() bool {
+	return lr.delim
 }
 
 // makeScanDelimited creates a split function that uses the given delimiter.
@@ -72,6 +126,9 @@ func (lr *lineReader) ReadLine() (string, error) {
 	}
 
 	if err := lr.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			err = lr.tooLong()
+		}
 		lr.err = err
 		return "", err
 	}
@@ -80,6 +137,15 @@ func (lr *lineReader) ReadLine() (string, error) {
 	return "", io.EOF
 }
 
+// tooLong describes a record that overflowed maxLineSize. In NUL mode the
+// usual cause is input that isn't NUL-delimited at all.
+func (lr *lineReader) tooLong() error {
+	if lr.nul {
+		return fmt.Errorf("%w (no NUL delimiter found within %d bytes; the input may not be NUL-delimited, try without -z)", ErrRecordTooLong, maxLineSize)
+	}
+	return fmt.Errorf("%w (no newline found within %d bytes)", ErrRecordTooLong, maxLineSize)
+}
+
 // scanLinesWithCRLF is a split function for bufio.Scanner that handles
 // both LF and CRLF line endings. Based on bufio.ScanLines but strips \r.
 func scanLinesWithCRLF(data []byte, atEOF bool) (advance int, token []byte, err error) {
@@ -1,6 +1,7 @@
 package tail
 
 import (
+	"errors"
 	"io"
 	"strings"
 	"testing"
@@ -110,3 +111,28 @@ func TestLineReader_EmptyLines(t *testing.T) {
 		}
 	}
 }
+
+func TestLineReader_RecordTooLong(t *testing.T) {
+	input := strings.Repeat("a", maxLineSize+1)
+
+	tests := []struct {
+		name   string
+		reader LineReader
+		hint   string
+	}{
+		{"newline", NewLineReader(strings.NewReader(input)), "no newline found"},
+		{"NUL", NewLineReaderWithDelimiter(strings.NewReader(input), '\x00'), "may not be NUL-delimited"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.reader.ReadLine()
+			if !errors.Is(err, ErrRecordTooLong) {
+				t.Fatalf("ReadLine() error = %v, want ErrRecordTooLong", err)
+			}
+			if !strings.Contains(err.Error(), tt.hint) {
+				t.Errorf("error %q does not contain %q", err, tt.hint)
+			}
+		})
+	}
+}
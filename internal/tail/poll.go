@@ -0,0 +1,126 @@
+package tail
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jmurray2011/wail/internal/watcher"
+)
+
+// pollTicker delivers the ticks that drive a follow or retry loop.
+type pollTicker struct {
+	C    <-chan time.Time
+	stop func()
+}
+
+// Stop turns off the ticker. No more ticks are sent after it returns.
+func (pt *pollTicker) Stop() {
+	pt.stop()
+}
+
+// newPollTicker returns a ticker on clk firing every interval. A jitter
+// above zero varies each period randomly within ±jitter of interval (0.1 is
+// ±10%), so loops started together drift apart instead of polling in
+// lockstep. Jitter is capped below 1 so a period is never zero or negative.
+func newPollTicker(clk clock, interval time.Duration, jitter float64) *pollTicker {
+	if jitter <= 0 {
+		tk := clk.NewTicker(interval)
+		return &pollTicker{C: tk.C(), stop: tk.Stop}
+	}
+	jitter = min(jitter, 0.9)
+	next := func() time.Duration {
+		return time.Duration(float64(interval) * (1 + jitter*(2*rand.Float64()-1)))
+	}
+
+	// Each tick picks the next period
+	c := make(chan time.Time, 1)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-clk.After(next()):
+				// Like time.Ticker, drop a tick the loop isn't ready for
+				select {
+				case c <- now:
+				default:
+				}
+			}
+		}
+	}()
+
+	return &pollTicker{C: c, stop: func() {
+		close(done)
+		<-exited
+	}}
+}
+
+// wakeOn returns a ticker that also ticks, without waiting out the period,
+// whenever changes delivers an event. Ticks are dropped, as with time.Ticker,
+// if the loop isn't ready for them. An event's tick carries clk's time.
+// Stopping it stops pt too.
+func (pt *pollTicker) wakeOn(clk clock, changes <-chan watcher.Event) *pollTicker {
+	c := make(chan time.Time, 1)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+
+	forward := func(now time.Time) {
+		select {
+		case c <- now:
+		default:
+		}
+	}
+	go func() {
+		defer close(exited)
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-pt.C:
+				forward(now)
+			case _, ok := <-changes:
+				if !ok {
+					changes = nil // Watcher gave up; keep polling
+					continue
+				}
+				forward(clk.Now())
+			}
+		}
+	}()
+
+	return &pollTicker{C: c, stop: func() {
+		pt.Stop()
+		close(done)
+		<-exited
+	}}
+}
+
+// followTicker returns the ticker driving a follow loop. Unless ForcePoll is
+// set or Path is on a network share, file system events for Path tick it
+// early, so new lines appear without waiting out PollInterval; polling
+// continues regardless, as a safety net for file systems whose events are
+// unreliable. If events are unavailable, it just polls.
+func (t *tailer) followTicker(ctx context.Context) *pollTicker {
+	pt := newPollTicker(t.clock, t.config.PollInterval, t.config.PollJitter)
+	if t.config.ForcePoll {
+		return pt
+	}
+	if t.network {
+		t.debugf("network path, polling only")
+		return pt
+	}
+
+	changes, err := watcher.NewWatcher(watcher.Config{
+		Path:    t.config.Path,
+		Backend: watcher.BackendEvents,
+	}).Watch(ctx)
+	if err != nil {
+		t.debugf("file system events unavailable, polling: %v", err)
+		return pt
+	}
+	return pt.wakeOn(t.clock, changes)
+}
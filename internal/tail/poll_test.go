@@ -0,0 +1,49 @@
+package tail
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPollTicker_Jitter(t *testing.T) {
+	const interval = 20 * time.Millisecond
+	const jitter = 0.5
+
+	pt := newPollTicker(realClock{}, interval, jitter)
+	defer pt.Stop()
+
+	last := time.Now()
+	for range 5 {
+		select {
+		case <-pt.C:
+		case <-time.After(time.Second):
+			t.Fatal("no tick")
+		}
+		now := time.Now()
+		// Only the bottom of the band is checked; scheduling delays can
+		// stretch a gap past the top
+		if gap := now.Sub(last); gap < time.Duration(float64(interval)*(1-jitter)) {
+			t.Errorf("tick after %v, want at least %v", gap, time.Duration(float64(interval)*(1-jitter)))
+		}
+		last = now
+	}
+}
+
+func TestPollTicker_Stop(t *testing.T) {
+	for _, jitter := range []float64{0, 0.2} {
+		pt := newPollTicker(realClock{}, 5*time.Millisecond, jitter)
+		<-pt.C
+		pt.Stop()
+
+		// A tick may already be buffered; none may follow it
+		select {
+		case <-pt.C:
+		default:
+		}
+		select {
+		case <-pt.C:
+			t.Errorf("jitter %v: tick after Stop", jitter)
+		case <-time.After(30 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package tail
+
+import (
+	"context"
+	"time"
+)
+
+// ProcessWaiter reports when a process exits.
+type ProcessWaiter interface {
+	// Wait returns a channel that is closed once the process with the given PID
+	// has exited. If ctx is cancelled first, the channel is left open and any
+	// background work stops.
+	Wait(ctx context.Context, pid int) <-chan struct{}
+}
+
+// pollProcess closes exited once processExists reports pid gone,
+// checking every interval of clk until ctx is cancelled.
+func pollProcess(ctx context.Context, clk clock, pid int, interval time.Duration, exited chan<- struct{}) {
+	ticker := clk.NewTicker(interval)
+	defer ticker.Stop()
+
+	for processExists(pid) {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+	close(exited)
+}
@@ -3,8 +3,10 @@
 package tail
 
 import (
+	"context"
 	"os"
 	"syscall"
+	"time"
 )
 
 // processExists checks if a process with the given PID exists on Unix.
@@ -19,3 +21,28 @@ func processExists(pid int) bool {
 	err = process.Signal(syscall.Signal(0))
 	return err == nil
 }
+
+// pollingProcessWaiter implements ProcessWaiter by polling with signal 0.
+// Unix has no portable way to wait on a process we didn't start.
+type pollingProcessWaiter struct {
+	clock    clock
+	interval time.Duration
+}
+
+// NewProcessWaiter returns a ProcessWaiter appropriate for the current OS.
+// On Unix it polls every interval.
+func NewProcessWaiter(interval time.Duration) ProcessWaiter {
+	return newProcessWaiter(realClock{}, interval)
+}
+
+// newProcessWaiter is NewProcessWaiter with polls timed by clk.
+func newProcessWaiter(clk clock, interval time.Duration) ProcessWaiter {
+	return &pollingProcessWaiter{clock: clk, interval: interval}
+}
+
+// Wait returns a channel that is closed once the process has exited.
+func (w *pollingProcessWaiter) Wait(ctx context.Context, pid int) <-chan struct{} {
+	exited := make(chan struct{})
+	go pollProcess(ctx, w.clock, pid, w.interval, exited)
+	return exited
+}
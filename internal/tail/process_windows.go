@@ -3,6 +3,9 @@
 package tail
 
 import (
+	"context"
+	"time"
+
 	"golang.org/x/sys/windows"
 )
 
@@ -26,3 +29,53 @@ func processExists(pid int) bool {
 	// STILL_ACTIVE (259) means the process is still running
 	return exitCode == 259
 }
+
+// handleProcessWaiter implements ProcessWaiter by waiting on the process handle,
+// so exit is noticed as soon as it happens rather than on the next poll.
+type handleProcessWaiter struct {
+	clock    clock
+	interval time.Duration
+}
+
+// NewProcessWaiter returns a ProcessWaiter appropriate for the current OS.
+// On Windows it waits on the process handle, checking for cancellation every interval.
+func NewProcessWaiter(interval time.Duration) ProcessWaiter {
+	return newProcessWaiter(realClock{}, interval)
+}
+
+// newProcessWaiter is NewProcessWaiter with any fallback polling timed by clk.
+func newProcessWaiter(clk clock, interval time.Duration) ProcessWaiter {
+	return &handleProcessWaiter{clock: clk, interval: interval}
+}
+
+// Wait returns a channel that is closed once the process has exited.
+func (w *handleProcessWaiter) Wait(ctx context.Context, pid int) <-chan struct{} {
+	exited := make(chan struct{})
+
+	handle, err := windows.OpenProcess(windows.SYNCHRONIZE, false, uint32(pid))
+	if err != nil {
+		// Already gone, or we lack SYNCHRONIZE access: fall back to polling
+		go pollProcess(ctx, w.clock, pid, w.interval, exited)
+		return exited
+	}
+
+	timeout := uint32(max(w.interval.Milliseconds(), 1))
+	go func() {
+		defer windows.CloseHandle(handle)
+		for {
+			event, err := windows.WaitForSingleObject(handle, timeout)
+			if err != nil {
+				pollProcess(ctx, w.clock, pid, w.interval, exited)
+				return
+			}
+			if event == windows.WAIT_OBJECT_0 {
+				close(exited)
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+	return exited
+}
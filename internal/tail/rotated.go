@@ -0,0 +1,78 @@
+package tail
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// findRotated returns the path of the file matching the DrainRotated glob
+// that is old, the file last read at Path, or "" if none is.
+func (t *tailer) findRotated(old os.FileInfo) string {
+	matches, err := filepath.Glob(t.config.DrainRotated)
+	if err != nil {
+		t.debugf("bad drain-rotated pattern: %v", err)
+		return ""
+	}
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && t.sameFile(old, info) {
+			return match
+		}
+	}
+	return ""
+}
+
+// drainRotated emits what was written to old, the file last read at Path,
+// after lastPos (or from the start of partial, the line held back then),
+// having found it renamed to a path matching DrainRotated, so lines written
+// just before a rotation aren't lost when following by name moves on to the
+// new file. A writer may still be finishing with the old file, so it is read
+// again each poll until it stops growing or DrainTimeout has passed. ok
+// reports whether the file was found; partial has been emitted if so.
+func (t *tailer) drainRotated(ctx context.Context, old os.FileInfo, lastPos int64, partial *Line, emit LineFunc) (ok bool, err error) {
+	path := t.findRotated(old)
+	if path == "" {
+		t.debugf("no file matching %s is the rotated file", t.config.DrainRotated)
+		return false, nil
+	}
+	f, err := t.opener.Open(path)
+	if err != nil {
+		t.debugf("open of rotated file %s failed: %v", path, err)
+		return false, nil
+	}
+	defer f.Close()
+	t.debugf("draining rotated file %s from offset %d", path, lastPos)
+
+	deadline := t.clock.Now().Add(t.config.DrainTimeout)
+	for {
+		base := lastPos
+		if partial != nil {
+			base = partial.Offset
+		}
+		if _, err := f.Seek(base, io.SeekStart); err != nil {
+			break
+		}
+		if partial, _, err = t.readComplete(f, base, emit); err != nil {
+			return true, err
+		}
+		pos, err := f.Seek(0, io.SeekCurrent)
+		if err != nil || pos == lastPos || !t.clock.Now().Before(deadline) {
+			break
+		}
+		lastPos = pos
+
+		select {
+		case <-ctx.Done():
+		case <-t.clock.After(t.config.PollInterval):
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if partial == nil || t.holding(partial) {
+		return true, nil
+	}
+	return true, emit(*partial)
+}
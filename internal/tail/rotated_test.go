@@ -0,0 +1,111 @@
+package tail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTailer_DrainRotated(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "app.log")
+	os.WriteFile(testFile, []byte("initial\n"), 0644)
+
+	clk := newFakeClock()
+	tailer := NewTailer(TailerConfig{
+		Path:         testFile,
+		Lines:        10,
+		Follow:       true,
+		FollowName:   true,
+		ForcePoll:    true,
+		PollInterval: 10 * time.Millisecond,
+		DrainRotated: filepath.Join(dir, "app.log.*"),
+		DrainTimeout: time.Second,
+	}).(*tailer)
+	tailer.clock = clk
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lines := make(chan string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- tailer.TailFunc(ctx, func(line Line) error {
+			lines <- line.Text
+			return nil
+		})
+	}()
+	next := func() string {
+		t.Helper()
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(5 * time.Second):
+			t.Fatal("no line output")
+			return ""
+		}
+	}
+	rotate := func(to, fresh string) {
+		if err := os.Rename(testFile, filepath.Join(dir, to)); err != nil {
+			t.Fatalf("rotating: %v", err)
+		}
+		os.WriteFile(testFile, []byte(fresh), 0644)
+	}
+
+	if got := next(); got != "initial" {
+		t.Fatalf("first line = %q, want %q", got, "initial")
+	}
+	clk.waitForWaiters(t, 1)
+
+	// Appended and rotated away between polls: the lines are read from the
+	// renamed file before the new one
+	f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	f.WriteString("last words\n")
+	f.Close()
+	rotate("app.log.1", "fresh\n")
+	clk.advance(10 * time.Millisecond)
+	if got := next(); got != "last words" {
+		t.Fatalf("line = %q, want %q", got, "last words")
+	}
+
+	// Once a poll passes with nothing more written to it, the new file is read
+	clk.waitForWaiters(t, 2) // The ticker, and the drain waiting a poll
+	clk.advance(10 * time.Millisecond)
+	if got := next(); got != "fresh" {
+		t.Fatalf("line = %q, want %q", got, "fresh")
+	}
+
+	// A writer still using the old file after the rename is read from for
+	// as long as it keeps writing, up to DrainTimeout
+	f, err = os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	defer f.Close()
+	f.WriteString("one\n")
+	rotate("app.log.2", "second fresh\n")
+	clk.advance(10 * time.Millisecond)
+	if got := next(); got != "one" {
+		t.Fatalf("line = %q, want %q", got, "one")
+	}
+	clk.waitForWaiters(t, 2)
+	f.WriteString("two\n")
+	clk.advance(10 * time.Millisecond)
+	if got := next(); got != "two" {
+		t.Fatalf("line = %q, want %q", got, "two")
+	}
+	clk.waitForWaiters(t, 2)
+	clk.advance(10 * time.Millisecond)
+	if got := next(); got != "second fresh" {
+		t.Fatalf("line = %q, want %q", got, "second fresh")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("TailFunc() error = %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package tail
+
+import (
+	"context"
+	"io"
+)
+
+// streamReader implements io.ReadCloser over a Tail running in a goroutine.
+type streamReader struct {
+	pr     *io.PipeReader
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewStreamReader starts t tailing in the background and returns a reader
+// of its output, for use with io.Copy, bufio and the like. Without Follow,
+// Read returns io.EOF once the output is complete; with it, Read blocks for
+// new lines until ctx is cancelled or the reader is closed. A failed tail
+// surfaces as the error from Read.
+//
+// Close cancels the tail and waits for it to stop. It must be called, even
+// after io.EOF, or the goroutine may leak.
+func NewStreamReader(ctx context.Context, t Tailer) io.ReadCloser {
+	ctx, cancel := context.WithCancel(ctx)
+	pr, pw := io.Pipe()
+	s := &streamReader{pr: pr, cancel: cancel, done: make(chan struct{})}
+
+	go func() {
+		defer close(s.done)
+		pw.CloseWithError(t.Tail(ctx, pw)) // A nil error closes with io.EOF
+	}()
+	return s
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	return s.pr.Read(p)
+}
+
+// Close stops the tail. Closing the pipe first releases a Tail blocked
+// writing output nobody will read.
+func (s *streamReader) Close() error {
+	s.cancel()
+	s.pr.Close()
+	<-s.done
+	return nil
+}
@@ -0,0 +1,71 @@
+package tail
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStreamReader(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+	if err := os.WriteFile(testFile, []byte("line1\nline2\nline3\nline4\nline5\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	r := NewStreamReader(context.Background(), NewTailer(TailerConfig{Path: testFile, Lines: 3}))
+	defer r.Close()
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if want := "line3\nline4\nline5\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestStreamReader_Error(t *testing.T) {
+	r := NewStreamReader(context.Background(), NewTailer(TailerConfig{Path: filepath.Join(t.TempDir(), "missing.log")}))
+	defer r.Close()
+
+	if _, err := io.ReadAll(r); err == nil {
+		t.Error("expected the open failure from Read")
+	}
+}
+
+func TestStreamReader_CloseStopsFollow(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+	if err := os.WriteFile(testFile, []byte("line1\nline2\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	r := NewStreamReader(context.Background(), NewTailer(TailerConfig{
+		Path:         testFile,
+		Lines:        10,
+		Follow:       true,
+		PollInterval: 10 * time.Millisecond,
+	}))
+
+	sc := bufio.NewScanner(r)
+	if !sc.Scan() || sc.Text() != "line1" {
+		t.Fatalf("first line = %q, want %q", sc.Text(), "line1")
+	}
+
+	// Close mid-follow, without reading the rest of the output
+	closed := make(chan struct{})
+	go func() {
+		r.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close did not stop the tail")
+	}
+}
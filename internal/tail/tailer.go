@@ -2,12 +2,19 @@ package tail
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/jmurray2011/wail/internal/eventlog"
 	"github.com/jmurray2011/wail/internal/filesystem"
+	"github.com/jmurray2011/wail/internal/metrics"
 )
 
 // Tailer reads the last N lines of a file and optionally follows for new content.
@@ -17,160 +24,454 @@ type Tailer interface {
 	Tail(ctx context.Context, output io.Writer) error
 
 	// TailReader outputs the last N lines from a reader (e.g., stdin).
-	// Follow mode is not supported for readers.
+	// Follow mode is not supported for readers; FollowStream streams lines
+	// as they arrive instead.
 	TailReader(ctx context.Context, input io.Reader, output io.Writer) error
+
+	// TailFunc is like Tail but hands each line to fn instead of writing it.
+	// Tailing stops and returns fn's error if fn fails.
+	TailFunc(ctx context.Context, fn LineFunc) error
+}
+
+// Line is a single line delivered to a LineFunc.
+//
+// In byte mode (Bytes > 0, or BytesSet) output is not split into lines,
+// initially or when following: each call carries a raw chunk of the file in
+// Text, delimiters included.
+type Line struct {
+	Text      string // Line contents without the delimiter
+	Offset    int64  // Byte offset of the line within the file
+	File      string // Path the line was read from
+	IsInitial bool   // True for lines from the initial read, false once following
+
+	chunk   bool // Text is a raw byte-mode chunk and already carries its delimiters
+	noDelim bool // With LineEndingKeep, the line had no delimiter in the input
+	marker  bool // A separator (LiveMarker, elision or context break) rather than file content
+	context bool // Output as context around a Filter match rather than as a match
 }
 
+// LineFunc receives lines from TailFunc.
+type LineFunc func(line Line) error
+
 // TailerConfig holds configuration for the tailer.
 type TailerConfig struct {
-	Path              string
-	Lines             int
-	Bytes             int64 // If > 0, output last N bytes instead of lines
-	FromStart         bool  // If true, start from line/byte N instead of last N
-	Follow            bool
-	FollowName        bool          // Follow by name (detect rotation) - like -F
-	Retry             bool          // Keep trying to open file if inaccessible
-	PID               int           // If > 0, terminate when this process dies
-	PollInterval      time.Duration
-	ZeroTerminated    bool // If true, use NUL as line delimiter instead of newline
-	MaxUnchangedStats int  // With --follow=name, reopen file after N unchanged polls
+	Path                 string
+	SFTP                 filesystem.SFTPConfig // How to authenticate when Path is an sftp:// URL
+	Lines                int
+	LinesSet             bool  // Lines was given explicitly, so 0 means no lines rather than the default of 10
+	Bytes                int64 // If > 0, output last N bytes instead of lines
+	BytesSet             bool  // Bytes was given, so 0 means no bytes (or all of them with FromStart) rather than line mode
+	BytesThrough         int64 // With FromStart in byte mode, if positive, stop after byte N (1-indexed, inclusive), so Bytes through BytesThrough is output
+	FromStart            bool  // If true, start from line/byte N instead of last N
+	AllButLast           bool  // If true, output all lines except the last N (not valid with Follow)
+	HeadTail             int   // If > 0, output the first and last HeadTail lines with a marker for those omitted between (not valid with Follow or Bytes)
+	StartOffset          int64 // With StartOffsetSet, begin at exactly this byte offset (0-indexed) instead of using Lines or Bytes
+	StartOffsetSet       bool  // StartOffset was given, so 0 means the start of the file rather than unset
+	ClampStartOffset     bool  // Start at the end of the input, rather than failing, when StartOffset is past it
+	Follow               bool
+	FollowName           bool          // Follow by name (detect rotation) - like -F
+	FollowSymlinkTarget  bool          // With -f, switch to the file Path resolves to as soon as a symlink along it (the file or a directory) is repointed
+	WarnRotation         bool          // With -f, warn once when Path is renamed away or replaced, while the open file keeps being followed
+	KeepOpen             bool          // With FollowName, hold the file open between reads while Path names the same file, instead of opening it for each read
+	ReapplyWindow        bool          // With FollowName, start a replacement file with the Lines or Bytes window, as at startup, rather than from its beginning
+	DrainRotated         string        // With FollowName, a glob (e.g. "/var/log/app.log.*") to find a rotated-away file under, to read the lines written to it since the last poll before moving to the new file
+	DrainTimeout         time.Duration // With DrainRotated, keep reading the rotated file each poll while it grows, for up to this long (0 reads it once)
+	MaxMemory            int64         // If positive, last-N lines spanning more bytes than this are streamed in two passes instead of held in memory
+	FollowStream         bool          // With TailReader, emit the last Lines of what the input delivers in its first PollInterval, then each line as it arrives, rather than waiting for the end of input
+	Retry                bool          // Keep trying to open file if inaccessible
+	RetryTimeout         time.Duration // With Retry, give up after this long (0 waits forever)
+	RetryAttempts        int           // With Retry, give up after this many failed opens (0 tries forever); whichever of this and RetryTimeout comes first
+	PID                  int           // If > 0, terminate when this process dies
+	PIDs                 []int         // Further processes to monitor alongside PID
+	AnyPIDDies           bool          // With several PIDs, terminate when any dies instead of when all have
+	PIDPollInterval      time.Duration // How often to check whether PIDs are alive, where that needs polling (0 means PollInterval)
+	PollInterval         time.Duration
+	PollJitter           float64           // Vary each poll period randomly by up to ± this fraction of PollInterval (0 is a fixed rate)
+	ForcePoll            bool              // With Follow, poll only, without waking early on file system events (for SMB/NFS, where events are unreliable)
+	ZeroTerminated       bool              // If true, use NUL as line delimiter instead of newline
+	WaitForContent       time.Duration     // With Follow, hold back new lines containing NUL bytes (as a file extended ahead of its writer reads) for up to this long, until they are written over; not in byte mode or with ZeroTerminated
+	LineEnding           LineEnding        // How lines end in output: LF (the zero value), CRLF, or as they were read
+	BinaryFiles          BinaryFiles       // What to do with a file that looks binary (a NUL in its first 8KB, unless ZeroTerminated); the zero value outputs it
+	OutputDelimiter      byte              // With OutputDelimiterSet, end each line of output with this byte instead of the input delimiter
+	OutputDelimiterSet   bool              // OutputDelimiter was given, so NUL is a delimiter rather than unset
+	OutputFlush          FlushMode         // When Tail writes buffered output; the zero value, FlushBatch, writes once per poll
+	FlushEvery           time.Duration     // With OutputFlush set to FlushTimed, how often Tail writes out buffered output
+	MaxLineWidth         func() int        // If set, cut lines longer than the returned number of characters, ending them with an ellipsis
+	MaxUnchangedStats    int               // With --follow=name, reopen file after N unchanged polls
+	ReopenOnMaxUnchanged bool              // With -f and MaxUnchangedStats, switch to a replacement file at Path after N unchanged polls
+	ReopenAfterErrors    int               // With -f, reopen by path after N consecutive read errors (0 disables)
+	LiveMarker           string            // With Follow, line written between a non-empty initial block and live content (empty disables)
+	MaxRate              float64           // With Follow, limit live output to this many lines per second (0 is unlimited)
+	RateBytes            bool              // MaxRate counts bytes per second instead of lines
+	DropOverflow         bool              // Over MaxRate, discard lines (with a periodic notice) instead of delaying
+	Uniq                 bool              // Suppress consecutive duplicate lines
+	UniqCount            bool              // With Uniq, report how many times a suppressed line repeated
+	Stderr               io.Writer         // Where to report recoverable problems; nil discards them
+	Debug                bool              // Trace follow-loop events (opens, reads, truncation, rotation) to Stderr
+	Metrics              *metrics.Registry // Count emitted lines, rotations and reopen failures under Path; nil disables
+	Filter               *regexp.Regexp    // Output only lines matching this pattern; nil passes everything
+	ContextBefore        int               // With Filter, also output up to this many lines before each match
+	ContextAfter         int               // With Filter, also output up to this many lines after each match
+	Since                time.Time         // Output only lines whose leading timestamp (see TimeLayout) is at or after this; zero leaves the window open
+	Until                time.Time         // Output only lines whose leading timestamp is at or before this; with Follow, a live line past it ends tailing
+	TimeLayout           string            // Go layout of the timestamp starting each line, for Since and Until; "" is RFC 3339
+	DropUnparseable      bool              // With Since or Until, drop lines not starting with a timestamp rather than output them
+	OnLive               func(Line)        // With Follow, called with each live line that passes Filter
 }
 
 // tailer implements Tailer.
 type tailer struct {
-	config TailerConfig
-	opener filesystem.FileOpener
+	config   TailerConfig
+	opener   filesystem.FileOpener
+	archives filesystem.ArchiveOpener
+	events   func(channel string) (eventlog.Reader, error)
+	uniq     *uniqFilter       // set during TailFunc when Uniq is configured
+	grep     *grepFilter       // set during TailFunc when Filter is configured
+	stats    *metrics.Counters // set during TailFunc when Metrics is configured
+	network  bool              // Path is on a network share, see sameFile
+	clock    clock             // Time for the follow and retry loops; tests replace it
+	out      *outputBuffer     // set during Tail when output is buffered, see buffered
+	waiting  *contentWait      // the line WaitForContent is holding back, if any
 }
 
-// NewTailer creates a new Tailer with the given configuration.
+// NewTailer creates a new Tailer with the given configuration, which it
+// doesn't check; NewTailerWithValidation does.
 func NewTailer(config TailerConfig) Tailer {
 	if config.PollInterval == 0 {
 		config.PollInterval = 100 * time.Millisecond
 	}
+	opener := filesystem.NewFileOpener()
+	network := filesystem.IsNetworkPath(config.Path)
+	if filesystem.IsSFTPPath(config.Path) {
+		opener, network = filesystem.NewSFTPOpener(config.SFTP), true
+	}
 	return &tailer{
-		config: config,
-		opener: filesystem.NewFileOpener(),
+		config:   config,
+		opener:   opener,
+		archives: filesystem.NewArchiveOpener(opener),
+		events:   eventlog.Open,
+		network:  network,
+		clock:    realClock{},
 	}
 }
 
+// NewTailerWithOpener creates a new Tailer like NewTailer, but opening Path,
+// and archives it names, through opener: one backed by an embedded file
+// system, an object store or a test double, say. Following by name, and
+// the replacement checks -f can make, look at Path on the local file system,
+// so an input only opener can reach is read once or followed by descriptor.
+func NewTailerWithOpener(config TailerConfig, opener filesystem.FileOpener) Tailer {
+	t := NewTailer(config).(*tailer)
+	t.opener = opener
+	t.archives = filesystem.NewArchiveOpener(opener)
+	return t
+}
+
 // Tail outputs the last N lines to the writer, then follows if configured.
 func (t *tailer) Tail(ctx context.Context, output io.Writer) error {
+	output, done := t.buffered(ctx, output)
+	err := t.TailFunc(ctx, t.writerFunc(output))
+	if flushErr := done(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// TailFunc hands the last N lines to fn, then follows if configured.
+func (t *tailer) TailFunc(ctx context.Context, fn LineFunc) error {
+	t.stats = t.config.Metrics.File(t.config.Path)
+	fn = t.throttled(ctx, t.truncated(t.counted(fn)))
+	if t.config.Uniq {
+		t.uniq = newUniqFilter(t.clock, fn, t.config.Path, t.config.UniqCount)
+		defer func() { t.uniq = nil }()
+		err := stopped(t.tail(ctx, t.windowed(t.filtered(t.hooked(t.uniq.line)))))
+		// Report a run of repeats still going when tailing stopped
+		if flushErr := t.uniq.flush(); err == nil {
+			err = flushErr
+		}
+		return err
+	}
+	return stopped(t.tail(ctx, t.windowed(t.filtered(t.hooked(fn)))))
+}
+
+// tail does the work of TailFunc once output filters are in place.
+func (t *tailer) tail(ctx context.Context, fn LineFunc) error {
+	// archive::member paths name a file inside a zip or tar archive
+	if _, _, ok := filesystem.SplitArchivePath(t.config.Path); ok {
+		return t.tailArchiveMember(fn)
+	}
+
+	// evtlog://Channel names a Windows Event Log channel
+	if channel, ok := eventlog.ParseSource(t.config.Path); ok {
+		return t.tailEventLog(ctx, channel, fn)
+	}
+
+	// Files over SFTP are polled through their handle; there is no path to
+	// watch for a replacement
+	if filesystem.IsSFTPPath(t.config.Path) && t.config.FollowName {
+		return fmt.Errorf("cannot follow an sftp path by name; use -f")
+	}
+
 	// If retry is enabled, wait for file to appear
 	if t.config.Retry {
-		return t.tailWithRetry(ctx, output)
+		return t.tailWithRetry(ctx, fn)
 	}
 
 	f, err := t.opener.Open(t.config.Path)
 	if err != nil {
 		return fmt.Errorf("opening file: %w", err)
 	}
+	t.debugOpened(f)
 	// Don't defer close - managed by follow functions or closed below
 
-	var pos int64
+	if ok, err := t.tailStream(f, fn); ok {
+		return err
+	}
+	if skip, err := t.skipBinary(f); skip || err != nil {
+		f.Close()
+		return err
+	}
 
-	// Bytes mode: output last N bytes (or from byte N if FromStart)
-	if t.config.Bytes > 0 {
-		info, err := os.Stat(t.config.Path)
+	pos, err := t.readInitial(f, fn)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	if !t.config.Follow {
+		f.Close()
+		return nil
+	}
+
+	// For follow-by-descriptor (-f), pass the open file handle
+	// For follow-by-name (-F), we'll reopen by path in followByName
+	if t.config.FollowName {
+		f.Close() // Close and reopen by path
+		return t.followByName(ctx, fn, pos)
+	}
+	return t.followByDescriptor(ctx, f, fn, pos)
+}
+
+// retryFailed is tailWithRetry's error for giving up, when is "after N
+// attempts" or "within D". A file that was there all along but couldn't be
+// read, for want of permission or because another process has it locked,
+// is reported with why, rather than as not having appeared.
+func retryFailed(when string, err error) error {
+	if errors.Is(err, os.ErrPermission) || errors.Is(err, filesystem.ErrAccessDenied) ||
+		errors.Is(err, filesystem.ErrSharingViolation) {
+		return fmt.Errorf("file still not readable %s: %w", when, err)
+	}
+	return fmt.Errorf("file did not appear %s", when)
+}
+
+// tailArchiveMember emits the selected lines or bytes of an archive member.
+// Members are streamed out of the archive, so they are read forward only.
+func (t *tailer) tailArchiveMember(emit LineFunc) error {
+	if t.config.Follow {
+		return fmt.Errorf("cannot follow an archive member")
+	}
+
+	mr, err := t.archives.Open(t.config.Path)
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+	defer mr.Close()
+
+	// Members may themselves be compressed (e.g. logs.zip::app.log.gz)
+	dr, _, err := filesystem.DecompressingReader(mr)
+	if err != nil {
+		return err
+	}
+	defer dr.Close()
+
+	return t.readStream(dr, emit)
+}
+
+// readInitial emits the initial output selected by the config (last N lines,
+// from line N, all but the last N lines, or the byte-mode equivalents) and
+// returns the position to follow from.
+func (t *tailer) readInitial(f filesystem.ReadSeekCloser, emit LineFunc) (int64, error) {
+	pos, emitted, err := t.readInitialBlock(f, emit)
+	if err != nil {
+		return 0, err
+	}
+
+	// Separate the snapshot from live lines, unless there was no snapshot
+	if t.config.Follow && t.config.LiveMarker != "" && emitted {
+		if err := emit(t.markerLine(pos)); err != nil {
+			return 0, err
+		}
+	}
+	return pos, nil
+}
+
+// readInitialBlock does the work of readInitial and also reports whether
+// anything was emitted.
+func (t *tailer) readInitialBlock(f filesystem.ReadSeekCloser, emit LineFunc) (pos int64, emitted bool, err error) {
+	inner := emit
+	emit = func(line Line) error {
+		emitted = true
+		return inner(line)
+	}
+
+	// Compressed files can only be read forward, through a decompressor
+	dr, format, err := filesystem.DecompressingReader(f)
+	if err != nil {
+		return 0, false, err
+	}
+	if format != filesystem.CompressionNone {
+		defer dr.Close()
+		if t.config.Follow {
+			return 0, false, fmt.Errorf("cannot follow %s-compressed file", format)
+		}
+		return 0, emitted, t.readStream(dr, emit)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, false, fmt.Errorf("seeking: %w", err)
+	}
+
+	// An explicit start offset replaces the -n/-c computation
+	if t.config.StartOffsetSet {
+		size, err := f.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, false, fmt.Errorf("seeking: %w", err)
+		}
+		start, err := t.startOffset(size)
 		if err != nil {
-			return fmt.Errorf("stat file: %w", err)
+			return 0, false, err
+		}
+		if _, err := f.Seek(start, io.SeekStart); err != nil {
+			return 0, false, fmt.Errorf("seeking: %w", err)
 		}
+		if err := t.readFromLineN(f, start, 0, emit); err != nil {
+			return 0, false, err
+		}
+		pos, err = f.Seek(0, io.SeekCurrent)
+		return pos, emitted, err
+	}
 
+	// Bytes mode: output last N bytes (or from byte N if FromStart)
+	if t.byteMode() {
 		var startPos int64
+		var r io.Reader = f
 		if t.config.FromStart {
 			// +N means start from byte N (1-indexed, so byte 1 = offset 0)
-			startPos = t.config.Bytes - 1
-			if startPos < 0 {
-				startPos = 0
-			}
+			startPos, err = f.Seek(max(t.config.Bytes-1, 0), io.SeekStart)
+			r = t.byteRange(f)
 		} else {
-			// -N means last N bytes
-			startPos = info.Size() - t.config.Bytes
-			if startPos < 0 {
-				startPos = 0
+			// -N means last N bytes. Seeking relative to the end measures the
+			// size and positions in one step, so a file growing in between
+			// can't skew the window the way Stat then Seek could.
+			startPos, err = f.Seek(-t.config.Bytes, io.SeekEnd)
+			if err != nil {
+				// File is shorter than N bytes: output all of it
+				startPos, err = f.Seek(0, io.SeekStart)
 			}
 		}
-
-		_, err = f.Seek(startPos, io.SeekStart)
 		if err != nil {
-			return fmt.Errorf("seeking: %w", err)
+			return 0, false, fmt.Errorf("seeking: %w", err)
 		}
 
 		// Stream bytes to output (avoids loading entire file into memory)
-		if err := t.streamBytes(f, output); err != nil {
-			return fmt.Errorf("reading bytes: %w", err)
+		if err := t.streamBytes(r, &chunkWriter{t: t, emit: emit, offset: startPos}); err != nil {
+			return 0, false, fmt.Errorf("reading bytes: %w", err)
 		}
-		pos, _ = f.Seek(0, io.SeekCurrent)
-	} else if t.config.FromStart {
-		// FromStart mode: output from line N onwards
-		lines, err := t.readFromLineN(f)
-		if err != nil {
-			return fmt.Errorf("reading lines: %w", err)
-		}
-
-		t.writeLines(output, lines)
-
-		// Get current position for following
-		pos, err = f.Seek(0, io.SeekCurrent)
-		if err != nil {
-			return fmt.Errorf("getting position: %w", err)
-		}
-	} else {
-		// Lines mode: output last N lines
-		lines, err := t.readLastNLines(f)
-		if err != nil {
-			return fmt.Errorf("reading lines: %w", err)
-		}
-
-		t.writeLines(output, lines)
-
-		// Get current position for following
 		pos, err = f.Seek(0, io.SeekCurrent)
-		if err != nil {
-			return fmt.Errorf("getting position: %w", err)
-		}
+		return pos, emitted, err
 	}
 
-	if !t.config.Follow {
-		f.Close()
-		return nil
+	if err := t.readLines(f, emit); err != nil {
+		return 0, false, err
 	}
 
-	// For follow-by-descriptor (-f), pass the open file handle
-	// For follow-by-name (-F), we'll reopen by path in followByName
-	if t.config.FollowName {
-		f.Close() // Close and reopen by path
-		return t.followByName(ctx, output, pos)
+	// Get current position for following
+	pos, err = f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, false, fmt.Errorf("getting position: %w", err)
 	}
-	return t.followByDescriptor(ctx, f, output, pos)
+	return pos, emitted, nil
 }
 
-// TailReader outputs the last N lines from a reader (e.g., stdin).
+// TailReader outputs the last N lines from a reader (e.g., stdin), or with
+// FollowStream, streams them as they arrive.
 func (t *tailer) TailReader(ctx context.Context, input io.Reader, output io.Writer) error {
-	// Byte mode for stdin
-	if t.config.Bytes > 0 {
-		return t.tailReaderBytes(input, output)
+	emit := t.truncated(t.writerFunc(output))
+	read := func(emit LineFunc) error { return t.readStream(input, emit) }
+	if t.config.FollowStream {
+		read = func(emit LineFunc) error { return t.followStream(ctx, input, emit) }
+	}
+	if t.config.Uniq {
+		u := newUniqFilter(t.clock, emit, t.config.Path, t.config.UniqCount)
+		if err := stopped(read(t.windowed(t.filtered(u.line)))); err != nil {
+			return err
+		}
+		return u.flush()
+	}
+	return stopped(read(t.windowed(t.filtered(emit))))
+}
+
+// readStream emits the selected lines or bytes from a reader that can only be
+// read forward (stdin, pipes, decompressed files).
+func (t *tailer) readStream(input io.Reader, emit LineFunc) error {
+	// Skip to the start offset by reading, since input can't seek
+	if t.config.StartOffsetSet {
+		skipped, err := io.CopyN(io.Discard, input, t.config.StartOffset)
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("reading: %w", err)
+		}
+		if _, err := t.startOffset(skipped); err != nil {
+			return err
+		}
+		return t.readFromLineN(input, skipped, 0, emit)
+	}
+
+	// Byte mode for non-seekable input
+	if t.byteMode() {
+		return t.tailReaderBytes(input, emit)
 	}
 
 	// Line mode
-	var lines []string
-	var err error
+	return t.readLines(input, emit)
+}
 
+// readLines emits the lines selected by the config from r, which must be
+// positioned at the start of the input.
+func (t *tailer) readLines(r io.Reader, emit LineFunc) error {
 	if t.config.FromStart {
-		lines, err = t.readFromLineN(input)
-	} else {
-		lines, err = t.readLastNLines(input)
+		// FromStart mode: output from line N onwards, streamed so that +N on
+		// a huge input doesn't hold everything after line N in memory
+		return t.readFromLineN(r, 0, t.config.Lines, emit)
 	}
-	if err != nil {
-		return fmt.Errorf("reading lines: %w", err)
+
+	if t.config.HeadTail > 0 {
+		return t.readHeadTail(r, emit)
+	}
+
+	if t.config.Lines == 0 && t.config.LinesSet && !t.config.AllButLast {
+		// -n 0: output nothing, but consume the input so that following
+		// starts from its end
+		if s, ok := r.(io.Seeker); ok {
+			if _, err := s.Seek(0, io.SeekEnd); err == nil {
+				return nil
+			}
+		}
+		if _, err := io.Copy(io.Discard, r); err != nil {
+			return fmt.Errorf("reading lines: %w", err)
+		}
+		return nil
 	}
 
-	t.writeLines(output, lines)
+	if !t.config.AllButLast {
+		// Lines mode: output last N lines
+		return t.readLastNLines(r, emit)
+	}
 
-	return nil
+	// AllButLast mode: output everything except the last N lines
+	return t.readAllButLastN(r, 0, emit)
 }
 
 // tailReaderBytes handles byte mode for non-seekable readers (stdin/pipes).
-func (t *tailer) tailReaderBytes(input io.Reader, output io.Writer) error {
+func (t *tailer) tailReaderBytes(input io.Reader, emit LineFunc) error {
 	if t.config.FromStart {
 		// +N means skip first N-1 bytes and output the rest
 		skipBytes := t.config.Bytes - 1
@@ -187,18 +488,33 @@ func (t *tailer) tailReaderBytes(input io.Reader, output io.Writer) error {
 		}
 
 		// Stream remaining bytes to output
-		return t.streamBytes(input, output)
+		return t.streamBytes(t.byteRange(input), &chunkWriter{t: t, emit: emit, offset: skipBytes})
 	}
 
 	// -N means last N bytes - need to buffer since we can't seek
 	// Use a ring buffer approach to avoid loading entire stream
 	n := t.config.Bytes
-	buf := make([]byte, n)
+	if n == 0 {
+		if _, err := io.Copy(io.Discard, input); err != nil {
+			return fmt.Errorf("reading bytes: %w", err)
+		}
+		return nil
+	}
+	buf := make([]byte, n) // ring: byte i of the stream lives at buf[i%n]
+	scratch := make([]byte, chunkSize)
 	total := int64(0)
 
 	for {
-		readN, err := input.Read(buf[total%n : min(n, total%n+chunkSize)])
+		readN, err := input.Read(scratch)
 		if readN > 0 {
+			// Only the trailing n bytes of a read can survive into the result
+			data := scratch[:readN]
+			if int64(len(data)) > n {
+				data = data[int64(len(data))-n:]
+			}
+			pos := (total + int64(readN) - int64(len(data))) % n
+			copied := copy(buf[pos:], data)
+			copy(buf, data[copied:])
 			total += int64(readN)
 		}
 		if err == io.EOF {
@@ -215,135 +531,201 @@ func (t *tailer) tailReaderBytes(input io.Reader, output io.Writer) error {
 
 	// Output the last N bytes (or all if less than N)
 	if total <= n {
-		output.Write(buf[:total])
-	} else {
-		// Ring buffer wraparound - output in correct order
-		start := total % n
-		output.Write(buf[start:])
-		output.Write(buf[:start])
+		_, err := (&chunkWriter{t: t, emit: emit}).Write(buf[:total])
+		return err
 	}
-
-	return nil
+	// Ring buffer wraparound - output in correct order
+	w := &chunkWriter{t: t, emit: emit, offset: total - n}
+	start := total % n
+	if _, err := w.Write(buf[start:]); err != nil {
+		return err
+	}
+	_, err := w.Write(buf[:start])
+	return err
 }
 
 // tailWithRetry keeps trying to open the file until it exists or context is cancelled.
-func (t *tailer) tailWithRetry(ctx context.Context, output io.Writer) error {
-	ticker := time.NewTicker(t.config.PollInterval)
+func (t *tailer) tailWithRetry(ctx context.Context, emit LineFunc) error {
+	ticker := newPollTicker(t.clock, t.config.PollInterval, t.config.PollJitter)
 	defer ticker.Stop()
 
+	// A nil deadline channel never fires, so RetryTimeout == 0 waits forever
+	var deadline <-chan time.Time
+	if t.config.RetryTimeout > 0 {
+		deadline = t.clock.After(t.config.RetryTimeout)
+	}
+
+	attempts := 0
 	for {
 		f, err := t.opener.Open(t.config.Path)
 		if err == nil {
-			// File exists, read it using the same logic as Tail()
-			var pos int64
-
-			if t.config.Bytes > 0 {
-				// Bytes mode: output last N bytes (or from byte N if FromStart)
-				info, err := os.Stat(t.config.Path)
-				if err != nil {
-					f.Close()
-					return fmt.Errorf("stat file: %w", err)
-				}
-
-				var startPos int64
-				if t.config.FromStart {
-					startPos = t.config.Bytes - 1
-					if startPos < 0 {
-						startPos = 0
-					}
-				} else {
-					startPos = info.Size() - t.config.Bytes
-					if startPos < 0 {
-						startPos = 0
-					}
-				}
-
-				_, err = f.Seek(startPos, io.SeekStart)
-				if err != nil {
-					f.Close()
-					return fmt.Errorf("seeking: %w", err)
-				}
-
-				if err := t.streamBytes(f, output); err != nil {
-					f.Close()
-					return fmt.Errorf("reading bytes: %w", err)
-				}
-				pos, _ = f.Seek(0, io.SeekCurrent)
-			} else if t.config.FromStart {
-				// FromStart mode: output from line N onwards
-				lines, err := t.readFromLineN(f)
-				if err != nil {
-					f.Close()
-					return fmt.Errorf("reading lines: %w", err)
-				}
-				t.writeLines(output, lines)
-				pos, _ = f.Seek(0, io.SeekCurrent)
-			} else {
-				// Lines mode: output last N lines
-				lines, err := t.readLastNLines(f)
-				if err != nil {
-					f.Close()
-					return fmt.Errorf("reading lines: %w", err)
-				}
-				t.writeLines(output, lines)
-				pos, _ = f.Seek(0, io.SeekCurrent)
+			t.debugOpened(f)
+			if ok, err := t.tailStream(f, emit); ok {
+				return err
+			}
+			if skip, err := t.skipBinary(f); skip || err != nil {
+				f.Close()
+				return err
 			}
 
+			// File exists, read it using the same logic as TailFunc()
+			pos, err := t.readInitial(f, emit)
 			f.Close()
+			if err != nil {
+				return err
+			}
 
 			if !t.config.Follow {
 				return nil
 			}
 
 			if t.config.FollowName {
-				return t.followByName(ctx, output, pos)
+				return t.followByName(ctx, emit, pos)
 			}
 			// For follow-by-descriptor, reopen and keep the handle
 			f2, err := t.opener.Open(t.config.Path)
 			if err != nil {
 				return fmt.Errorf("reopening file: %w", err)
 			}
-			return t.followByDescriptor(ctx, f2, output, pos)
+			return t.followByDescriptor(ctx, f2, emit, pos)
 		}
 
-		// File doesn't exist, wait and retry
+		// File doesn't exist, or can't be read yet (as when a new log's
+		// permissions are set just after it is created): wait and retry.
+		// Only failed opens count towards RetryAttempts
+		attempts++
+		if t.config.RetryAttempts > 0 && attempts >= t.config.RetryAttempts {
+			if ctx.Err() != nil {
+				return nil // Cancellation takes precedence, as with the timeout
+			}
+			return retryFailed(fmt.Sprintf("after %d attempts", attempts), err)
+		}
+		t.debugf("not available, retrying in %v: %v", t.config.PollInterval, err)
 		select {
 		case <-ctx.Done():
 			return nil
+		case <-deadline:
+			if ctx.Err() != nil {
+				return nil // Cancellation takes precedence over the timeout
+			}
+			return retryFailed(fmt.Sprintf("within %v", t.config.RetryTimeout), err)
 		case <-ticker.C:
 			// Continue to next iteration
 		}
 	}
 }
 
-// newLineReader creates the appropriate LineReader based on config.
-func (t *tailer) newLineReader(r io.Reader) LineReader {
+// newLineReader creates the appropriate line reader based on config.
+func (t *tailer) newLineReader(r io.Reader) *lineReader {
 	if t.config.ZeroTerminated {
-		return NewLineReaderWithDelimiter(r, '\x00')
+		lr := newLineReader(r, makeScanDelimited('\x00'), '\x00')
+		lr.nul = true
+		return lr
+	}
+	if t.config.LineEnding == LineEndingKeep {
+		return newLineReader(r, makeScanDelimited('\n'), '\n')
+	}
+	return newLineReader(r, scanLinesWithCRLF, '\n')
+}
+
+// line builds a Line read from the configured path.
+func (t *tailer) line(text string, offset int64, initial bool) Line {
+	return Line{Text: text, Offset: offset, File: t.config.Path, IsInitial: initial}
+}
+
+// byteMode reports whether output is raw bytes rather than lines.
+func (t *tailer) byteMode() bool {
+	return t.config.Bytes > 0 || t.config.BytesSet
+}
+
+// byteRange limits r, positioned at byte Bytes, to end after BytesThrough,
+// or returns it unchanged if BytesThrough isn't set. A range running past
+// the end of the input stops at the end.
+func (t *tailer) byteRange(r io.Reader) io.Reader {
+	if t.config.BytesThrough <= 0 {
+		return r
 	}
-	return NewLineReader(r)
+	return io.LimitReader(r, t.config.BytesThrough-max(t.config.Bytes-1, 0))
+}
+
+// markerLine builds the LiveMarker separator line, at offset pos.
+func (t *tailer) markerLine(pos int64) Line {
+	line := t.line(t.config.LiveMarker, pos, false)
+	line.marker = true
+	return line
+}
+
+// lineAt builds a Line for text, the line lr most recently returned, where
+// base is the offset of lr's source within the file.
+func (t *tailer) lineAt(lr *lineReader, text string, base int64, initial bool) Line {
+	line := t.line(text, base+lr.offset(), initial)
+	line.noDelim = t.config.LineEnding == LineEndingKeep && !lr.terminated()
+	return line
 }
 
-// writeLines writes lines to output with the appropriate delimiter.
-func (t *tailer) writeLines(output io.Writer, lines []string) {
+// emitLines hands lines to emit in order, stopping at the first error.
+func (t *tailer) emitLines(emit LineFunc, lines []Line) error {
 	for _, line := range lines {
-		if t.config.ZeroTerminated {
-			fmt.Fprint(output, line)
-			output.Write([]byte{'\x00'})
-		} else {
-			fmt.Fprintln(output, line)
+		if err := emit(line); err != nil {
+			return err
 		}
 	}
+	return nil
 }
 
-// writeLine writes a single line to output with the appropriate delimiter.
-func (t *tailer) writeLine(output io.Writer, line string) {
-	if t.config.ZeroTerminated {
-		fmt.Fprint(output, line)
-		output.Write([]byte{'\x00'})
-	} else {
-		fmt.Fprintln(output, line)
+// writerFunc returns a LineFunc that writes each line to output followed by
+// the appropriate delimiter. This is how Tail and TailReader produce output.
+func (t *tailer) writerFunc(output io.Writer) LineFunc {
+	return func(line Line) error {
+		if line.chunk {
+			_, err := io.WriteString(output, line.Text)
+			return err
+		}
+		if line.noDelim {
+			// Byte-faithful: don't invent a delimiter the input didn't have
+			_, err := io.WriteString(output, line.Text)
+			return err
+		}
+		return t.writeLine(output, line.Text)
+	}
+}
+
+// writeLine writes a single line to output with the appropriate delimiter:
+// OutputDelimiter if given, or else the one lines are read with, as
+// LineEnding has it.
+func (t *tailer) writeLine(output io.Writer, line string) error {
+	delimiter := "\n"
+	switch {
+	case t.config.OutputDelimiterSet:
+		delimiter = string([]byte{t.config.OutputDelimiter})
+	case t.config.ZeroTerminated:
+		delimiter = "\x00"
+	case t.config.LineEnding == LineEndingCRLF:
+		delimiter = "\r\n"
+	}
+	_, err := io.WriteString(output, line+delimiter)
+	return err
+}
+
+// chunkWriter adapts a LineFunc to an io.Writer for byte mode, emitting each
+// write as a raw chunk starting at offset.
+type chunkWriter struct {
+	t      *tailer
+	emit   LineFunc
+	offset int64
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
 	}
+	line := w.t.line(string(p), w.offset, true)
+	line.chunk = true
+	if err := w.emit(line); err != nil {
+		return 0, err
+	}
+	w.offset += int64(len(p))
+	return len(p), nil
 }
 
 // chunkSize is the size of chunks for reading
@@ -369,37 +751,101 @@ func (t *tailer) streamBytes(r io.Reader, w io.Writer) error {
 	}
 }
 
-// readLastNLines reads all lines and returns the last N.
+// readLastNLines emits the last N lines of r.
 // For seekable readers, uses efficient backward reading.
-func (t *tailer) readLastNLines(r io.Reader) ([]string, error) {
+func (t *tailer) readLastNLines(r io.Reader, emit LineFunc) error {
+	var lines []Line
+	var err error
 	// Try to use optimized backward reading for seekable files
 	// Note: *os.File implements io.ReadSeeker but stdin/pipes fail on actual seek
 	if seeker, ok := r.(io.ReadSeeker); ok {
 		// Test if seeking actually works (stdin implements Seeker but errors)
 		if _, err := seeker.Seek(0, io.SeekCurrent); err == nil {
-			return t.readLastNLinesBackward(seeker)
+			return t.readLastNLinesBackward(seeker, emit)
 		}
 	}
 	// Fallback to forward reading with ring buffer for non-seekable
-	return t.readLastNLinesForward(r)
+	if lines, err = t.readLastNLinesForward(r, 0); err != nil {
+		return fmt.Errorf("reading lines: %w", err)
+	}
+	return t.emitLines(emit, lines)
 }
 
-// readLastNLinesBackward reads last N lines by reading backwards from EOF.
-func (t *tailer) readLastNLinesBackward(r io.ReadSeeker) ([]string, error) {
-	// Get file size
-	size, err := r.Seek(0, io.SeekEnd)
+// readLastNLinesBackward emits the last N lines of r, found by reading
+// backwards from EOF. They are normally collected and then emitted; if they
+// span more than MaxMemory bytes they are streamed by streamLastNLines
+// instead. If r ends in a hole, r is left at its end, past the hole, so
+// following starts there rather than reading the hole as lines.
+func (t *tailer) readLastNLinesBackward(r io.ReadSeeker, emit LineFunc) error {
+	start, end, size, err := t.lastNLinesRegion(r)
+	if err != nil {
+		return fmt.Errorf("reading lines: %w", err)
+	}
+	if end == 0 {
+		// Nothing but hole
+		return seekPastHole(r, end, size)
+	}
+	if t.config.MaxMemory > 0 && end-start > t.config.MaxMemory {
+		t.debugf("last %d lines span %d bytes, over the %d byte limit; streaming them", t.config.Lines, end-start, t.config.MaxMemory)
+		if err := t.streamLastNLines(r, start, end, emit); err != nil {
+			return err
+		}
+		return seekPastHole(r, end, size)
+	}
+
+	// Read from found position to end of content
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return fmt.Errorf("reading lines: %w", err)
+	}
+	var region io.Reader = r
+	if end < size {
+		region = io.LimitReader(r, end-start)
+	}
+	lines, err := t.readLastNLinesForward(region, start)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("reading lines: %w", err)
+	}
+	if err := t.emitLines(emit, lines); err != nil {
+		return err
 	}
+	return seekPastHole(r, end, size)
+}
+
+// seekPastHole moves r to size when its content ended at end, before a
+// trailing hole, and otherwise leaves it where reading stopped.
+func seekPastHole(r io.Seeker, end, size int64) error {
+	if end >= size {
+		return nil
+	}
+	if _, err := r.Seek(size, io.SeekStart); err != nil {
+		return fmt.Errorf("seeking: %w", err)
+	}
+	return nil
+}
 
-	if size == 0 {
-		return nil, nil
+// lastNLinesRegion returns where in r the last N lines start and where its
+// content ends, along with its size. The region may hold one line more than
+// N, where the input doesn't end with a delimiter, and holds all of r when r
+// is small enough to read in one chunk.
+func (t *tailer) lastNLinesRegion(r io.ReadSeeker) (start, end, size int64, err error) {
+	// Get file size
+	size, err = r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, 0, 0, err
 	}
 
 	// For small files, just read forward
 	if size <= chunkSize {
-		r.Seek(0, io.SeekStart)
-		return t.readLastNLinesForward(r)
+		return 0, size, size, nil
+	}
+
+	// Skip a trailing hole in sparse files so it isn't scanned for delimiters
+	end, err = t.contentEnd(r, size)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if end == 0 {
+		return 0, 0, size, nil
 	}
 
 	// Read backwards to find start position
@@ -410,7 +856,7 @@ func (t *tailer) readLastNLinesBackward(r io.ReadSeeker) ([]string, error) {
 
 	linesNeeded := t.config.Lines + 1 // +1 because last char might be delimiter
 	linesFound := 0
-	pos := size
+	pos := end
 	buf := make([]byte, chunkSize)
 
 	for pos > 0 && linesFound < linesNeeded {
@@ -424,12 +870,14 @@ func (t *tailer) readLastNLinesBackward(r io.ReadSeeker) ([]string, error) {
 		// Read chunk
 		_, err := r.Seek(pos, io.SeekStart)
 		if err != nil {
-			return nil, err
+			return 0, 0, 0, err
 		}
 
-		n, err := r.Read(buf[:readSize])
-		if err != nil && err != io.EOF {
-			return nil, err
+		// A single Read may return less than asked for (sftp files return at
+		// most a packet), which would leave the rest of the chunk unscanned
+		n, err := io.ReadFull(r, buf[:readSize])
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return 0, 0, 0, err
 		}
 
 		// Count delimiters backwards in this chunk
@@ -445,127 +893,764 @@ func (t *tailer) readLastNLinesBackward(r io.ReadSeeker) ([]string, error) {
 		}
 	}
 
-	// Read from found position to end
-	_, err = r.Seek(pos, io.SeekStart)
-	if err != nil {
-		return nil, err
-	}
-
-	return t.readLastNLinesForward(r)
+	return pos, end, size, nil
 }
 
-// readLastNLinesForward reads lines forward, keeping only last N in ring buffer.
-func (t *tailer) readLastNLinesForward(r io.Reader) ([]string, error) {
-	lr := t.newLineReader(r)
-
-	// Use ring buffer for efficiency
-	n := t.config.Lines
-	if n <= 0 {
-		n = 10
+// streamLastNLines emits the lines of r between start and end, less any
+// more than N at the front, without holding them all: a first pass counts
+// them, and a second emits each one past the excess as it is read. This
+// bounds memory for MaxMemory at the cost of reading the region twice. Both
+// passes stop at end, so a line appended in between isn't miscounted.
+func (t *tailer) streamLastNLines(r io.ReadSeeker, start, end int64, emit LineFunc) error {
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return fmt.Errorf("reading lines: %w", err)
 	}
-	ring := make([]string, n)
+	lr := t.newLineReader(io.LimitReader(r, end-start))
 	count := 0
-
 	for {
-		line, err := lr.ReadLine()
+		_, err := lr.ReadLine()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return nil, err
+			return fmt.Errorf("reading lines: %w", err)
 		}
-		ring[count%n] = line
 		count++
 	}
 
-	// Extract lines in order
-	if count <= n {
-		return ring[:count], nil
+	n := t.config.Lines
+	if n <= 0 {
+		n = 10
 	}
-
-	// Reorder from ring buffer
-	result := make([]string, n)
-	start := count % n
-	for i := 0; i < n; i++ {
-		result[i] = ring[(start+i)%n]
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return fmt.Errorf("reading lines: %w", err)
 	}
-	return result, nil
+	return t.readFromLineN(io.LimitReader(r, end-start), start, count-n+1, emit)
 }
 
-// readFromLineN reads all lines starting from line N (1-indexed).
-func (t *tailer) readFromLineN(r io.Reader) ([]string, error) {
-	lr := t.newLineReader(r)
-	var lines []string
-	lineNum := 0
+// statter is implemented by readers that can describe the underlying file.
+type statter interface {
+	Stat() (os.FileInfo, error)
+}
 
-	for {
-		line, err := lr.ReadLine()
+// contentEnd returns the offset just past the last non-NUL byte when r is a
+// sparse file ending in a hole, or size otherwise. Pre-allocated logs report a
+// large size while their data sits at the front; treating the trailing run of
+// NULs as non-content lets the backward scan find the real last lines.
+// This is best-effort and disabled in -z mode, where NUL is the delimiter.
+//
+// The file system is asked where the trailing hole begins, so the hole is
+// never read. The data before it is only allocated in whole blocks, so NULs
+// are still trimmed from its end; where holes can't be asked about, that
+// trim is a scan backwards through the whole hole.
+func (t *tailer) contentEnd(r io.ReadSeeker, size int64) (int64, error) {
+	if t.config.ZeroTerminated {
+		return size, nil
+	}
+	s, ok := r.(statter)
+	if !ok {
+		return size, nil
+	}
+	info, err := s.Stat()
+	if err != nil || !filesystem.IsSparse(info) {
+		return size, nil
+	}
+
+	end := size
+	if f, ok := r.(*os.File); ok {
+		if dataEnd, ok := filesystem.DataEnd(f, size); ok {
+			end = dataEnd
+		}
+	}
+
+	buf := make([]byte, chunkSize)
+	for end > 0 {
+		readSize := min(int64(chunkSize), end)
+		if _, err := r.Seek(end-readSize, io.SeekStart); err != nil {
+			return 0, err
+		}
+		n, err := io.ReadFull(r, buf[:readSize])
+		if err != nil {
+			return 0, err
+		}
+		for i := n - 1; i >= 0; i-- {
+			if buf[i] != 0 {
+				return end - readSize + int64(i) + 1, nil
+			}
+		}
+		end -= readSize
+	}
+	return 0, nil
+}
+
+// lineRing keeps the last n lines pushed to it. It grows as lines arrive
+// rather than being allocated at n up front, so a huge n (-n 1000000000)
+// costs no more than the lines there are.
+type lineRing struct {
+	n     int
+	lines []Line
+	count int // Lines pushed, ever
+}
+
+// push adds line, returning the oldest line kept if it had to make room.
+func (r *lineRing) push(line Line) (evicted Line, ok bool) {
+	if len(r.lines) < r.n {
+		r.lines = append(r.lines, line)
+	} else {
+		i := r.count % r.n
+		evicted, ok = r.lines[i], true
+		r.lines[i] = line
+	}
+	r.count++
+	return evicted, ok
+}
+
+// last returns the lines kept, oldest first.
+func (r *lineRing) last() []Line {
+	if r.count <= r.n {
+		return r.lines
+	}
+	start := r.count % r.n
+	return append(r.lines[start:len(r.lines):len(r.lines)], r.lines[:start]...)
+}
+
+// readLastNLinesForward reads lines forward, keeping only last N in ring buffer.
+// base is the offset of r's first byte within the file.
+func (t *tailer) readLastNLinesForward(r io.Reader, base int64) ([]Line, error) {
+	lr := t.newLineReader(r)
+
+	n := t.config.Lines
+	if n <= 0 {
+		n = 10
+	}
+	ring := &lineRing{n: n}
+
+	for {
+		line, err := lr.ReadLine()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
 			return nil, err
 		}
+		ring.push(t.lineAt(lr, line, base, true))
+	}
+	return ring.last(), nil
+}
+
+// startOffset returns where reading starts for StartOffset, given an input
+// of size bytes: StartOffset itself, or size if that is past the end and
+// ClampStartOffset is set.
+func (t *tailer) startOffset(size int64) (int64, error) {
+	if t.config.StartOffset <= size {
+		return t.config.StartOffset, nil
+	}
+	if t.config.ClampStartOffset {
+		return size, nil
+	}
+	return 0, fmt.Errorf("start offset %d is past the end of the input (%d bytes)", t.config.StartOffset, size)
+}
+
+// readFromLineN emits each line from line n (1-indexed) onwards as it is read;
+// n of 0 or 1 emits every line. base is the offset of r's first byte within
+// the file.
+func (t *tailer) readFromLineN(r io.Reader, base int64, n int, emit LineFunc) error {
+	lr := t.newLineReader(r)
+	lineNum := 0
+
+	for {
+		line, err := lr.ReadLine()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading lines: %w", err)
+		}
 		lineNum++
 		// Include lines starting from line N
-		if lineNum >= t.config.Lines {
-			lines = append(lines, line)
+		if lineNum >= n {
+			if err := emit(t.lineAt(lr, line, base, true)); err != nil {
+				return err
+			}
 		}
 	}
+}
+
+// readHeadTail emits the first and last HeadTail lines of r with a marker
+// saying how many were left out between them, or every line if there are no
+// more than twice HeadTail. The head is emitted as it is read; only the tail
+// is held, in a ring.
+func (t *tailer) readHeadTail(r io.Reader, emit LineFunc) error {
+	lr := t.newLineReader(r)
+	n := t.config.HeadTail
+	ring := &lineRing{n: n}
+	head := 0 // Lines emitted as the head
 
-	return lines, nil
+	for {
+		text, err := lr.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("reading lines: %w", err)
+		}
+		line := t.lineAt(lr, text, 0, true)
+		if head < n {
+			if err := emit(line); err != nil {
+				return err
+			}
+			head++
+			continue
+		}
+		ring.push(line)
+	}
+
+	tail := ring.last()
+	if ring.count > n {
+		omitted := ring.count - n
+		noun := "lines"
+		if omitted == 1 {
+			noun = "line"
+		}
+		marker := t.line(fmt.Sprintf("... (%d %s omitted) ...", omitted, noun), tail[0].Offset, true)
+		marker.marker = true
+		if err := emit(marker); err != nil {
+			return err
+		}
+	}
+	for _, line := range tail {
+		if err := emit(line); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
+// readAllButLastN emits every line of r except the last N. Only N lines are
+// held at a time: each is emitted once N more have been read after it.
+// base is the offset of r's first byte within the file.
+func (t *tailer) readAllButLastN(r io.Reader, base int64, emit LineFunc) error {
+	if t.config.Lines <= 0 {
+		return t.readFromLineN(r, base, 0, emit)
+	}
+	lr := t.newLineReader(r)
+	ring := &lineRing{n: t.config.Lines}
+
+	for {
+		line, err := lr.ReadLine()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading lines: %w", err)
+		}
+		if evicted, ok := ring.push(t.lineAt(lr, line, base, true)); ok {
+			if err := emit(evicted); err != nil {
+				return err
+			}
+		}
+	}
+}
 
 // followByDescriptor follows the open file handle (-f mode).
 // This continues reading from the same file descriptor even if the file is renamed.
-func (t *tailer) followByDescriptor(ctx context.Context, f filesystem.ReadSeekCloser, output io.Writer, startPos int64) error {
-	defer f.Close()
+func (t *tailer) followByDescriptor(ctx context.Context, f filesystem.ReadSeekCloser, emit LineFunc, startPos int64) error {
+	defer func() { f.Close() }() // f may be replaced by a reopen
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	exited := t.watchProcesses(ctx)
 
-	ticker := time.NewTicker(t.config.PollInterval)
+	ticker := t.followTicker(ctx)
 	defer ticker.Stop()
 
 	lastPos := startPos
-
-	for {
-		// Check if monitored process is still alive
-		if t.config.PID > 0 && !processExists(t.config.PID) {
+	consecutiveErrors := 0
+	unchangedCount := 0
+	warnedRotation := false
+
+	// A trailing line with no delimiter yet is held back until the rest of
+	// it arrives, then read again from its start
+	var partial *Line
+	flushPartial := func() error {
+		if partial == nil || t.holding(partial) {
+			partial = nil
 			return nil
 		}
+		line := *partial
+		partial = nil
+		return emit(line)
+	}
+
+	// readFailed counts a failed poll and, once ReopenAfterErrors is reached,
+	// reopens the file by path (e.g. the handle went stale after a network hiccup)
+	readFailed := func() {
+		consecutiveErrors++
+		if t.config.ReopenAfterErrors <= 0 || consecutiveErrors < t.config.ReopenAfterErrors {
+			return
+		}
+		consecutiveErrors = 0
 
+		nf, err := t.opener.Open(t.config.Path)
+		if err != nil {
+			t.debugf("reopen after read errors failed: %v", err)
+			t.stats.ReopenFailed()
+			return
+		}
+		f.Close()
+		f = nf
+		t.debugOpened(f)
+
+		// Start over if the reopened file is smaller than where we were
+		if info, err := f.Stat(); err == nil && info.Size() < lastPos {
+			lastPos = 0
+			partial = nil
+		}
+		t.diagnose("reopened after %d consecutive read errors", t.config.ReopenAfterErrors)
+	}
+
+	for {
+		// What the last poll read goes out before waiting for the next
+		if err := t.flushBatch(); err != nil {
+			return err
+		}
 		select {
 		case <-ctx.Done():
-			return nil
+			return t.drainFinal(f, lastPos, partial, emit)
+		case <-exited:
+			t.debugf("monitored processes exited, stopping")
+			return t.drainFinal(f, lastPos, partial, emit)
 		case <-ticker.C:
-			// Seek to current position and try to read more
-			_, err := f.Seek(lastPos, io.SeekStart)
+			// A repointed symlink is switched to straight away, without
+			// waiting for the old target to go quiet
+			if t.config.FollowSymlinkTarget {
+				if info, err := f.Stat(); err == nil {
+					if nf := t.openIfRepointed(info); nf != nil {
+						if err := flushPartial(); err != nil {
+							nf.Close()
+							return err
+						}
+						if err := t.resetFilters(); err != nil {
+							nf.Close()
+							return err
+						}
+						f.Close()
+						f = nf
+						lastPos = 0
+						t.debugOpened(f)
+						t.stats.Rotated()
+					}
+				}
+			}
+
+			// Size the open descriptor, not the path: after a rename the path
+			// may point at an unrelated file
+			info, err := f.Stat()
 			if err != nil {
+				t.debugf("stat failed: %v", err)
+				readFailed()
 				continue
 			}
-
-			lr := t.newLineReader(f)
-			for {
-				line, err := lr.ReadLine()
-				if err == io.EOF {
-					break
+			if t.config.WarnRotation {
+				warnedRotation = t.warnIfRotated(info, warnedRotation)
+			}
+			if info.Size() < lastPos {
+				// Shrunk in place, e.g. logrotate's copytruncate: the same
+				// file now holds new content from the start
+				t.debugf("truncation detected (size %d -> %d)", lastPos, info.Size())
+				t.stats.Rotated()
+				if err := flushPartial(); err != nil {
+					return err
 				}
-				if err != nil {
-					break
+				if err := t.resetFilters(); err != nil {
+					return err
+				}
+				lastPos = 0
+			}
+			if info.Size() <= lastPos && !t.holding(partial) {
+				consecutiveErrors = 0
+				unchangedCount++
+				if t.config.ReopenOnMaxUnchanged && t.config.MaxUnchangedStats > 0 &&
+					unchangedCount >= t.config.MaxUnchangedStats {
+					unchangedCount = 0
+					if nf := t.openIfReplaced(info); nf != nil {
+						if err := flushPartial(); err != nil {
+							nf.Close()
+							return err
+						}
+						if err := t.resetFilters(); err != nil {
+							nf.Close()
+							return err
+						}
+						f.Close()
+						f = nf
+						lastPos = 0
+						t.debugOpened(f)
+						t.stats.Rotated()
+					}
 				}
-				t.writeLine(output, line)
+				continue
+			}
+			unchangedCount = 0
+
+			// Seek to current position (or the start of a held-back partial
+			// line) and try to read more
+			base := lastPos
+			if partial != nil {
+				base = partial.Offset
+			}
+			_, err = f.Seek(base, io.SeekStart)
+			if err != nil {
+				readFailed()
+				continue
+			}
+
+			var readErr error
+			partial, readErr, err = t.readComplete(f, base, emit)
+			if err != nil {
+				return err
 			}
 
 			// Update position
-			newPos, _ := f.Seek(0, io.SeekCurrent)
+			newPos, err := f.Seek(0, io.SeekCurrent)
+			if err != nil {
+				readFailed()
+				continue
+			}
+			t.debugf("read %d bytes (offset %d -> %d)", newPos-lastPos, lastPos, newPos)
 			lastPos = newPos
+
+			if readErr != nil {
+				readFailed()
+			} else {
+				consecutiveErrors = 0
+			}
+		}
+	}
+}
+
+// throttled wraps emit to enforce MaxRate on live (non-initial) output, or
+// returns it unchanged when no rate is set.
+func (t *tailer) throttled(ctx context.Context, emit LineFunc) LineFunc {
+	if t.config.MaxRate <= 0 {
+		return emit
+	}
+	limited := newThrottle(t.clock, t.config.MaxRate, t.config.RateBytes, t.config.DropOverflow).wrap(ctx, emit, t.config.Path)
+	return func(line Line) error {
+		if line.IsInitial {
+			return emit(line)
 		}
+		return limited(line)
 	}
 }
 
+// filtered wraps emit to pass on only lines matching Filter, with any
+// context lines configured, or returns it unchanged when no filter is set.
+func (t *tailer) filtered(emit LineFunc) LineFunc {
+	if t.config.Filter == nil {
+		t.grep = nil
+		return emit
+	}
+	t.grep = newGrepFilter(emit, t.config.Filter, t.config.ContextBefore, t.config.ContextAfter)
+	return t.grep.line
+}
+
+// hooked wraps emit to hand each live line to OnLive once emit has taken it,
+// or returns it unchanged when no hook is set. It sits behind the filter but
+// ahead of duplicate suppression and rate limiting, so every matching line
+// fires the hook even if it is folded into a repeat count or dropped.
+func (t *tailer) hooked(emit LineFunc) LineFunc {
+	if t.config.OnLive == nil {
+		return emit
+	}
+	return func(line Line) error {
+		if err := emit(line); err != nil {
+			return err
+		}
+		if !line.IsInitial && !line.marker && !line.context {
+			t.config.OnLive(line)
+		}
+		return nil
+	}
+}
+
+// truncated wraps emit to cut lines to MaxLineWidth, or returns it unchanged
+// when no width is set. Byte-mode chunks are passed through whole.
+func (t *tailer) truncated(emit LineFunc) LineFunc {
+	if t.config.MaxLineWidth == nil {
+		return emit
+	}
+	return func(line Line) error {
+		if !line.chunk {
+			line.Text = truncateLine(line.Text, t.config.MaxLineWidth())
+		}
+		return emit(line)
+	}
+}
+
+// ellipsis marks where truncateLine cut a line.
+const ellipsis = "…"
+
+// truncateLine cuts text to width characters, the last being an ellipsis,
+// if it is any longer. Characters are runes, so wide (e.g. CJK) characters
+// count as one.
+func truncateLine(text string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	n := 0
+	for i := range text {
+		if n == width-1 {
+			return text[:i] + ellipsis
+		}
+		n++
+	}
+	return text
+}
+
+// counted wraps emit to count each line it accepts in the file's metrics, or
+// returns it unchanged when metrics are off.
+func (t *tailer) counted(emit LineFunc) LineFunc {
+	if t.stats == nil {
+		return emit
+	}
+	return func(line Line) error {
+		if err := emit(line); err != nil {
+			return err
+		}
+		n := len(line.Text)
+		if !line.chunk && !line.noDelim {
+			n++ // The delimiter writeLine adds
+		}
+		t.stats.Emitted(n)
+		return nil
+	}
+}
+
+// resetFilters restarts duplicate suppression and grep context, e.g. after
+// rotation, so the first line of the new file is always output and context
+// never spans two files.
+func (t *tailer) resetFilters() error {
+	if t.grep != nil {
+		t.grep.reset()
+	}
+	if t.uniq == nil {
+		return nil
+	}
+	return t.uniq.reset()
+}
+
+// sameFile reports whether a and b describe the same file. File IDs on
+// network shares aren't always stable, so there a file that is the same
+// size or larger, and no older, is assumed to be the same one; a rotation
+// is only noticed once the new file is smaller than the old one was.
+func (t *tailer) sameFile(a, b os.FileInfo) bool {
+	if t.network {
+		return b.Size() >= a.Size() && !b.ModTime().Before(a.ModTime())
+	}
+	return os.SameFile(a, b)
+}
+
+// openIfReplaced opens the file now at Path if it is a different file from
+// current (the open descriptor's info), or returns nil if it is the same file
+// or cannot be opened. Used by -f with ReopenOnMaxUnchanged: strict descriptor
+// semantics are kept while the old file is still growing, and a switch only
+// happens after it has gone quiet and the path points elsewhere.
+func (t *tailer) openIfReplaced(current os.FileInfo) filesystem.ReadSeekCloser {
+	pathInfo, err := os.Stat(t.config.Path)
+	if err != nil || t.sameFile(current, pathInfo) {
+		return nil
+	}
+	f, err := t.opener.Open(t.config.Path)
+	if err != nil {
+		t.stats.ReopenFailed()
+		return nil
+	}
+	return f
+}
+
+// warnIfRotated reports, for -f with WarnRotation, that Path no longer names
+// current (the open descriptor's info), unless warned says it has been
+// reported already; the open file is still the one followed. It returns
+// whether the current rotation has been reported, so the warning comes once
+// per rotation and again only after Path names current once more.
+func (t *tailer) warnIfRotated(current os.FileInfo, warned bool) bool {
+	if t.config.Path == "" || filesystem.IsSFTPPath(t.config.Path) {
+		return false
+	}
+	pathInfo, err := os.Stat(t.config.Path)
+	switch {
+	case err == nil && t.sameFile(current, pathInfo):
+		return false
+	case warned:
+		return true
+	case errors.Is(err, os.ErrNotExist):
+		t.diagnose("renamed or removed; still following the open file (id %s), use -F to follow the name", fileID(current))
+	case err == nil:
+		t.diagnose("replaced by another file (id %s); still following the open file (id %s), use -F to follow the name", fileID(pathInfo), fileID(current))
+	default:
+		return false // Can't tell, e.g. a directory on the way lost its permissions
+	}
+	return true
+}
+
+// openIfRepointed opens the file Path resolves to if it no longer resolves
+// to current, or returns nil if there is no symlink along Path, it still
+// resolves to current, or the file cannot be opened. The link may be Path
+// itself or a directory on the way to it, as with a container's log
+// directory swapped on a restart. The resolved target is opened rather
+// than Path, so a second repoint in between can't be picked up half way.
+func (t *tailer) openIfRepointed(current os.FileInfo) filesystem.ReadSeekCloser {
+	target, err := filepath.EvalSymlinks(t.config.Path)
+	if err != nil {
+		return nil
+	}
+	path, err := filepath.Abs(t.config.Path)
+	if err != nil {
+		return nil
+	}
+	if resolved, err := filepath.Abs(target); err != nil || resolved == path {
+		return nil
+	}
+	targetInfo, err := os.Stat(target)
+	if err != nil || t.sameFile(current, targetInfo) {
+		return nil
+	}
+	f, err := t.opener.Open(target)
+	if err != nil {
+		t.debugf("open of new symlink target %s failed: %v", target, err)
+		t.stats.ReopenFailed()
+		return nil
+	}
+	t.debugf("symlink now points to %s", target)
+	return f
+}
+
+// watchProcesses returns a channel that is closed when following should stop
+// because the monitored processes have exited: all of them by default, or any
+// one of them with AnyPIDDies. It returns nil (never ready) when no PIDs are
+// configured. Background waiters stop when ctx is cancelled.
+func (t *tailer) watchProcesses(ctx context.Context) <-chan struct{} {
+	pids := t.config.PIDs
+	if t.config.PID > 0 {
+		pids = append([]int{t.config.PID}, pids...)
+	}
+	if len(pids) == 0 {
+		return nil
+	}
+
+	// Process checks run on their own timer, apart from file polling
+	interval := t.config.PIDPollInterval
+	if interval <= 0 {
+		interval = t.config.PollInterval
+	}
+	waiter := newProcessWaiter(t.clock, interval)
+	done := make(chan struct{})
+	var once sync.Once
+	var wg sync.WaitGroup
+
+	for _, pid := range pids {
+		exited := waiter.Wait(ctx, pid)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case <-exited:
+				t.debugf("process %d exited", pid)
+				if t.config.AnyPIDDies {
+					once.Do(func() { close(done) })
+				}
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	if !t.config.AnyPIDDies {
+		go func() {
+			wg.Wait()
+			if ctx.Err() == nil {
+				close(done)
+			}
+		}()
+	}
+	return done
+}
+
+// diagnose reports a recoverable problem to the configured Stderr, if any.
+func (t *tailer) diagnose(format string, args ...any) {
+	if t.config.Stderr == nil {
+		return
+	}
+	fmt.Fprintf(t.config.Stderr, "wail: %s: %s\n", t.config.Path, fmt.Sprintf(format, args...))
+}
+
+// debugf traces a follow-loop event to Stderr when Debug is set. Lines are
+// timestamped and prefixed "wail[debug]:" so they stand apart from output
+// and from ordinary diagnostics.
+func (t *tailer) debugf(format string, args ...any) {
+	if !t.config.Debug || t.config.Stderr == nil {
+		return
+	}
+	fmt.Fprintf(t.config.Stderr, "wail[debug]: %s %s: %s\n",
+		t.clock.Now().Format("2006-01-02T15:04:05.000Z07:00"), t.config.Path, fmt.Sprintf(format, args...))
+}
+
+// debugOpened traces that f was opened, with its size and identity.
+func (t *tailer) debugOpened(f filesystem.ReadSeekCloser) {
+	if !t.config.Debug {
+		return
+	}
+	info, err := f.Stat()
+	if err != nil {
+		t.debugf("opened (stat failed: %v)", err)
+		return
+	}
+	t.debugf("opened (size %d, id %s)", info.Size(), fileID(info))
+}
+
+// debugReplaced traces that the file at Path is no longer the one followed.
+func (t *tailer) debugReplaced(old, current os.FileInfo) {
+	t.debugf("replacement detected (id %s -> %s)", fileID(old), fileID(current))
+}
+
+// tailStream reads f forward to the end if it is a FIFO or other pipe by
+// path (such as /dev/fd/N from bash's <(...)), which can't seek, and closes
+// it. ok reports whether it was, and so whether tailing is done. Like
+// standard input, a pipe is not followed.
+func (t *tailer) tailStream(f filesystem.ReadSeekCloser, emit LineFunc) (ok bool, err error) {
+	info, err := f.Stat()
+	if err != nil || !isStream(info) {
+		return false, nil
+	}
+	defer f.Close()
+	if t.config.Follow {
+		t.debugf("not a regular file, reading to the end without following")
+	}
+	return true, t.readStream(f, t.unbatched(emit))
+}
+
+// isStream reports whether info describes a pipe, socket or character
+// device: something read as a stream rather than a file with a size.
+func isStream(info os.FileInfo) bool {
+	return info.Mode()&(os.ModeNamedPipe|os.ModeSocket|os.ModeCharDevice) != 0
+}
+
+// fileID is filesystem.FileID with a placeholder where no ID is available.
+func fileID(info os.FileInfo) string {
+	if id := filesystem.FileID(info); id != "" {
+		return id
+	}
+	return "?"
+}
+
 // followByName watches for file changes by path and outputs new lines (-F mode).
 // This reopens the file by path, detecting rotation/replacement.
-func (t *tailer) followByName(ctx context.Context, output io.Writer, startPos int64) error {
-	ticker := time.NewTicker(t.config.PollInterval)
+func (t *tailer) followByName(ctx context.Context, emit LineFunc, startPos int64) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	exited := t.watchProcesses(ctx)
+
+	ticker := t.followTicker(ctx)
 	defer ticker.Stop()
 
 	lastPos := startPos
@@ -573,6 +1658,29 @@ func (t *tailer) followByName(ctx context.Context, output io.Writer, startPos in
 	var lastFileInfo os.FileInfo
 	unchangedCount := 0
 
+	// As in followByDescriptor, a trailing partial line waits for the rest
+	var partial *Line
+	flushPartial := func() error {
+		if partial == nil || t.holding(partial) {
+			partial = nil
+			return nil
+		}
+		line := *partial
+		partial = nil
+		return emit(line)
+	}
+
+	// By default nothing is held open between reads, so following many
+	// files costs no descriptors while they are quiet. With KeepOpen the
+	// descriptor is kept for as long as the path names the same file
+	var held filesystem.ReadSeekCloser
+	var heldInfo os.FileInfo
+	defer func() {
+		if held != nil {
+			held.Close()
+		}
+	}()
+
 	// Get initial file info
 	info, err := os.Stat(t.config.Path)
 	if err == nil {
@@ -580,18 +1688,50 @@ func (t *tailer) followByName(ctx context.Context, output io.Writer, startPos in
 		lastFileInfo = info
 	}
 
-	for {
-		// Check if monitored process is still alive
-		if t.config.PID > 0 && !processExists(t.config.PID) {
+	// drain is drainFinal for the file last read, if it is still at the path
+	drain := func() error {
+		f := held
+		if f == nil {
+			info, err := os.Stat(t.config.Path)
+			if err != nil || lastFileInfo == nil || !t.sameFile(lastFileInfo, info) {
+				return flushPartial()
+			}
+			if f, err = t.opener.Open(t.config.Path); err != nil {
+				return flushPartial()
+			}
+			defer f.Close()
+		}
+		return t.drainFinal(f, lastPos, partial, emit)
+	}
+
+	// drainRotated reads the end of the file last read from where rotation
+	// renamed it, with DrainRotated, ahead of moving to the new file
+	drainRotated := func() error {
+		if t.config.DrainRotated == "" {
 			return nil
 		}
+		ok, err := t.drainRotated(ctx, lastFileInfo, lastPos, partial, emit)
+		if ok {
+			partial = nil
+		}
+		return err
+	}
 
+	for {
+		// What the last poll read goes out before waiting for the next
+		if err := t.flushBatch(); err != nil {
+			return err
+		}
 		select {
 		case <-ctx.Done():
-			return nil
+			return drain()
+		case <-exited:
+			t.debugf("monitored processes exited, stopping")
+			return drain()
 		case <-ticker.C:
 			info, err := os.Stat(t.config.Path)
 			if err != nil {
+				t.debugf("stat failed: %v", err)
 				if t.config.FollowName && t.config.Retry {
 					// File disappeared, wait for it to reappear
 					continue
@@ -601,22 +1741,55 @@ func (t *tailer) followByName(ctx context.Context, output io.Writer, startPos in
 
 			currentSize := info.Size()
 
+			// A held descriptor for a file no longer at the path is let go
+			// at once, so a rotated-away file isn't kept alive
+			if held != nil && !t.sameFile(heldInfo, info) {
+				held.Close()
+				held = nil
+			}
+
 			// Check for file replacement (rotation) when following by name
-			if t.config.FollowName && lastFileInfo != nil && !os.SameFile(lastFileInfo, info) {
-				// File was replaced, read from beginning
+			if t.config.FollowName && lastFileInfo != nil && !t.sameFile(lastFileInfo, info) {
+				// File was replaced: read it from the beginning, or with
+				// ReapplyWindow, from its Lines or Bytes window
+				t.debugReplaced(lastFileInfo, info)
+				t.stats.Rotated()
+				if err := drainRotated(); err != nil {
+					return err
+				}
+				if err := flushPartial(); err != nil {
+					return err
+				}
+				if err := t.resetFilters(); err != nil {
+					return err
+				}
 				lastPos = 0
 				lastSize = 0
 				lastFileInfo = info
 				unchangedCount = 0
+				if t.config.ReapplyWindow {
+					if lastPos, err = t.reapplyWindow(emit); err != nil {
+						return err
+					}
+					lastSize = min(lastPos, currentSize) // It may have grown since the stat
+				}
 			}
 
 			// Check for truncation
 			if currentSize < lastSize {
+				t.debugf("truncation detected (size %d -> %d)", lastSize, currentSize)
+				t.stats.Rotated()
+				if err := flushPartial(); err != nil {
+					return err
+				}
+				if err := t.resetFilters(); err != nil {
+					return err
+				}
 				lastPos = 0
 				lastSize = currentSize
 			}
 
-			if currentSize == lastSize && currentSize == lastPos {
+			if currentSize == lastSize && currentSize == lastPos && !t.holding(partial) {
 				// No change detected
 				unchangedCount++
 
@@ -625,10 +1798,27 @@ func (t *tailer) followByName(ctx context.Context, output io.Writer, startPos in
 					unchangedCount >= t.config.MaxUnchangedStats {
 					// Re-stat to check if file was replaced (some rotations may not change inode immediately)
 					newInfo, err := os.Stat(t.config.Path)
-					if err == nil && lastFileInfo != nil && !os.SameFile(lastFileInfo, newInfo) {
+					if err == nil && lastFileInfo != nil && !t.sameFile(lastFileInfo, newInfo) {
+						t.debugReplaced(lastFileInfo, newInfo)
+						t.stats.Rotated()
+						if err := drainRotated(); err != nil {
+							return err
+						}
+						if err := flushPartial(); err != nil {
+							return err
+						}
+						if err := t.resetFilters(); err != nil {
+							return err
+						}
 						lastPos = 0
 						lastSize = 0
 						lastFileInfo = newInfo
+						if t.config.ReapplyWindow {
+							if lastPos, err = t.reapplyWindow(emit); err != nil {
+								return err
+							}
+							lastSize = min(lastPos, newInfo.Size())
+						}
 					}
 					unchangedCount = 0
 				}
@@ -639,35 +1829,163 @@ func (t *tailer) followByName(ctx context.Context, output io.Writer, startPos in
 			unchangedCount = 0
 
 			// Read new content
-			f, err := t.opener.Open(t.config.Path)
-			if err != nil {
-				continue
+			f := held
+			if f == nil {
+				f, err = t.opener.Open(t.config.Path)
+				if err != nil {
+					t.debugf("open failed: %v", err)
+					t.stats.ReopenFailed()
+					continue
+				}
+				t.debugOpened(f)
 			}
+			held = nil // The failure paths below close f themselves
 
-			_, err = f.Seek(lastPos, io.SeekStart)
+			base := lastPos
+			if partial != nil {
+				base = partial.Offset
+			}
+			_, err = f.Seek(base, io.SeekStart)
 			if err != nil {
 				f.Close()
 				continue
 			}
 
-			lr := t.newLineReader(f)
-			for {
-				line, err := lr.ReadLine()
-				if err == io.EOF {
-					break
-				}
-				if err != nil {
-					break
-				}
-				t.writeLine(output, line)
+			partial, _, err = t.readComplete(f, base, emit)
+			if err != nil {
+				f.Close()
+				return err
 			}
 
 			// Update position and file info
 			newPos, _ := f.Seek(0, io.SeekCurrent)
+			t.debugf("read %d bytes (offset %d -> %d)", newPos-lastPos, lastPos, newPos)
 			lastPos = newPos
 			lastSize = currentSize
 			lastFileInfo = info
-			f.Close()
+			if t.config.KeepOpen {
+				held, heldInfo = f, info
+			} else {
+				f.Close()
+			}
+		}
+	}
+}
+
+// drainFinal reads what has been written to f since lastPos, emitting the
+// complete lines and then any partial one, for when following stops on
+// cancellation or because the monitored processes exited: lines written just
+// before then would otherwise be lost waiting for a poll that never comes.
+// partial is the line held back at the last poll. It is best effort; if f
+// can't be read, only partial is emitted.
+func (t *tailer) drainFinal(f filesystem.ReadSeekCloser, lastPos int64, partial *Line, emit LineFunc) error {
+	base := lastPos
+	if partial != nil {
+		base = partial.Offset
+	}
+	if info, err := f.Stat(); err == nil && info.Size() > lastPos {
+		if _, err := f.Seek(base, io.SeekStart); err == nil {
+			t.debugf("reading to the end before stopping (offset %d)", base)
+			p, _, err := t.readComplete(f, base, emit)
+			if err != nil {
+				return err
+			}
+			partial = p
+		}
+	}
+	if partial == nil || t.holding(partial) {
+		return nil
+	}
+	return emit(*partial)
+}
+
+// reapplyWindow emits the Lines or Bytes window of the file now at Path, as
+// when tailing began, for ReapplyWindow after a rotation. It returns the
+// offset to follow the file from: 0 if it can't be opened, or with
+// StartOffset, which was an offset into the old file. The lines are live
+// output, not part of the initial read, so MaxRate and OnLive apply to them.
+func (t *tailer) reapplyWindow(emit LineFunc) (int64, error) {
+	if t.config.StartOffsetSet {
+		return 0, nil
+	}
+	f, err := t.opener.Open(t.config.Path)
+	if err != nil {
+		t.debugf("open failed: %v", err)
+		t.stats.ReopenFailed()
+		return 0, nil
+	}
+	defer f.Close()
+	t.debugOpened(f)
+
+	live := func(line Line) error {
+		line.IsInitial = false
+		return emit(line)
+	}
+	pos, _, err := t.readInitialBlock(f, live)
+	if err != nil {
+		return 0, err
+	}
+	t.debugf("reapplied window to the new file, following from offset %d", pos)
+	return pos, nil
+}
+
+// readComplete emits the lines in r, which starts at offset base, except a
+// final line with no delimiter: that is returned as partial instead, since
+// the rest of it may still be on its way. So is a line awaitContent holds
+// back, with nothing after it emitted. Callers read a partial line again
+// from its start, so a multi-byte character split between polls is never
+// emitted in halves. readErr reports a failed read, which ends the lines
+// early; err is an error from emit.
+//
+// In byte mode everything in r is emitted as raw chunks instead, and there is
+// never a partial line.
+func (t *tailer) readComplete(r io.Reader, base int64, emit LineFunc) (partial *Line, readErr, err error) {
+	if t.byteMode() {
+		readErr, err = t.readChunks(r, base, emit)
+		return nil, readErr, err
+	}
+	lr := t.newLineReader(r)
+	for {
+		text, err := lr.ReadLine()
+		if err == io.EOF {
+			return partial, nil, nil
+		}
+		if err != nil {
+			return partial, err, nil
+		}
+		line := t.lineAt(lr, text, base, false)
+		if t.awaitContent(line) {
+			return &line, nil, nil
+		}
+		if !lr.terminated() {
+			partial = &line
+			continue
+		}
+		if err := emit(line); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// readChunks emits everything in r, which starts at offset base, as raw
+// chunks, for following in byte mode.
+func (t *tailer) readChunks(r io.Reader, base int64, emit LineFunc) (readErr, err error) {
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			line := t.line(string(buf[:n]), base, false)
+			line.chunk = true
+			if err := emit(line); err != nil {
+				return nil, err
+			}
+			base += int64(n)
+		}
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return err, nil
 		}
 	}
 }
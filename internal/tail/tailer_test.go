@@ -1,14 +1,30 @@
 package tail
 
 import (
+	"archive/zip"
 	"bytes"
+	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
 	"time"
+
+	"github.com/jmurray2011/wail/internal/filesystem"
+	"github.com/jmurray2011/wail/internal/metrics"
+	"github.com/klauspost/compress/zstd"
 )
 
 func TestTailer_LastNLines(t *testing.T) {
@@ -129,46 +145,1230 @@ func TestTailer_FollowMode(t *testing.T) {
 		t.Fatalf("failed to create test file: %v", err)
 	}
 
-	var buf bytes.Buffer
+	// Polling only, on a fake clock, the tailer reads when the test says so
+	clk := newFakeClock()
 	tailer := NewTailer(TailerConfig{
 		Path:         testFile,
 		Lines:        10,
 		Follow:       true,
+		ForcePoll:    true,
+		PollInterval: 10 * time.Millisecond,
+	}).(*tailer)
+	tailer.clock = clk
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lines := make(chan string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- tailer.TailFunc(ctx, func(line Line) error {
+			lines <- line.Text
+			return nil
+		})
+	}()
+	next := func() string {
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(5 * time.Second):
+			t.Fatal("no line output")
+			return ""
+		}
+	}
+
+	if got := next(); got != "initial" {
+		t.Errorf("first line = %q, want %q", got, "initial")
+	}
+	clk.waitForWaiters(t, 1)
+
+	// Append new content; it is read at the next poll
+	f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	f.WriteString("appended\n")
+	f.Close()
+
+	clk.advance(10 * time.Millisecond)
+	if got := next(); got != "appended" {
+		t.Errorf("followed line = %q, want %q", got, "appended")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("TailFunc() error = %v", err)
+	}
+}
+
+func TestTailer_TailFunc(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+
+	if err := os.WriteFile(testFile, []byte("one\ntwo\nthree\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	var mu sync.Mutex
+	var got []Line
+	tailer := NewTailer(TailerConfig{
+		Path:         testFile,
+		Lines:        2,
+		Follow:       true,
+		PollInterval: 10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tailer.TailFunc(ctx, func(line Line) error {
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, line)
+			return nil
+		})
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	f.WriteString("four\n")
+	f.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Fatalf("TailFunc failed: %v", err)
+	}
+
+	want := []Line{
+		{Text: "two", Offset: 4, File: testFile, IsInitial: true},
+		{Text: "three", Offset: 8, File: testFile, IsInitial: true},
+		{Text: "four", Offset: 14, File: testFile, IsInitial: false},
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != len(want) {
+		t.Fatalf("got %d lines %+v, want %+v", len(got), got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestTailer_TailFunc_ErrorStopsTailing(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+
+	if err := os.WriteFile(testFile, []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	tailer := NewTailer(TailerConfig{
+		Path:         testFile,
+		Lines:        10,
+		Follow:       true,
+		PollInterval: 10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stop := errors.New("stop")
+	calls := 0
+	err := tailer.TailFunc(ctx, func(line Line) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("expected callback error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 callback, got %d", calls)
+	}
+	if ctx.Err() != nil {
+		t.Error("TailFunc kept following after the callback failed")
+	}
+}
+
+func TestTailer_LiveMarker(t *testing.T) {
+	tests := []struct {
+		name    string
+		initial string
+		want    string
+	}{
+		{"after initial lines", "one\ntwo\n", "one\ntwo\n--- following ---\nthree\n"},
+		{"suppressed when initial block is empty", "", "three\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			testFile := filepath.Join(dir, "test.log")
+
+			if err := os.WriteFile(testFile, []byte(tt.initial), 0644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			var buf bytes.Buffer
+			tailer := NewTailer(TailerConfig{
+				Path:         testFile,
+				Lines:        10,
+				Follow:       true,
+				LiveMarker:   "--- following ---",
+				PollInterval: 10 * time.Millisecond,
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() {
+				done <- tailer.Tail(ctx, &buf)
+			}()
+
+			time.Sleep(50 * time.Millisecond)
+
+			f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				t.Fatalf("failed to open file: %v", err)
+			}
+			f.WriteString("three\n")
+			f.Close()
+
+			time.Sleep(100 * time.Millisecond)
+			cancel()
+			<-done
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTailer_CompressedFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	var content strings.Builder
+	for i := 1; i <= 10; i++ {
+		fmt.Fprintf(&content, "line%d\n", i)
+	}
+
+	gzFile := filepath.Join(dir, "lines.txt.gz")
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write([]byte(content.String()))
+	gw.Close()
+	if err := os.WriteFile(gzFile, gz.Bytes(), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	zstFile := filepath.Join(dir, "lines.txt.zst")
+	var zs bytes.Buffer
+	zw, err := zstd.NewWriter(&zs)
+	if err != nil {
+		t.Fatalf("zstd.NewWriter error = %v", err)
+	}
+	zw.Write([]byte(content.String()))
+	zw.Close()
+	if err := os.WriteFile(zstFile, zs.Bytes(), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"gzip", gzFile},
+		{"bzip2", filepath.Join("testdata", "lines.txt.bz2")},
+		{"zstd", zstFile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tailer := NewTailer(TailerConfig{Path: tt.path, Lines: 3})
+
+			if err := tailer.Tail(context.Background(), &buf); err != nil {
+				t.Fatalf("Tail() error = %v", err)
+			}
+			if got, want := buf.String(), "line8\nline9\nline10\n"; got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestTailer_CompressedFile_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "broken.gz")
+
+	// A real gzip header, then data that doesn't inflate
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write([]byte(strings.Repeat("line\n", 1000)))
+	gw.Close()
+	data := gz.Bytes()
+	for i := 20; i < len(data)-8; i++ {
+		data[i] ^= 0xff
+	}
+	if err := os.WriteFile(testFile, data, 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	tailer := NewTailer(TailerConfig{Path: testFile, Lines: 3})
+
+	err := tailer.Tail(context.Background(), &buf)
+	if err == nil || !strings.Contains(err.Error(), "gzip") {
+		t.Errorf("expected a gzip error, got %v", err)
+	}
+}
+
+// TestTailer_MagicPrefixedPlainFile tests that a plain log that happens to
+// start with a compression format's magic number is read as text.
+func TestTailer_MagicPrefixedPlainFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"bzip2", "BZh9 looks compressed\nbut is not\n"},
+		{"gzip", "\x1f\x8b looks compressed\nbut is not\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), "app.log")
+			if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			var buf bytes.Buffer
+			tailer := NewTailer(TailerConfig{Path: testFile, Lines: 3, BinaryFiles: BinaryText})
+			if err := tailer.Tail(context.Background(), &buf); err != nil {
+				t.Fatalf("Tail() error = %v", err)
+			}
+			if got := buf.String(); got != tt.content {
+				t.Errorf("got %q, want %q", got, tt.content)
+			}
+		})
+	}
+}
+
+func TestTailer_ArchiveMember(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "bundle.zip")
+
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatalf("failed to create archive: %v", err)
+	}
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("logs/app.log")
+	w.Write([]byte("line1\nline2\nline3\nline4\n"))
+	zw.Close()
+	f.Close()
+
+	tests := []struct {
+		name   string
+		config TailerConfig
+		want   string
+	}{
+		{"lines", TailerConfig{Lines: 2}, "line3\nline4\n"},
+		{"bytes", TailerConfig{Bytes: 6}, "line4\n"},
+		{"from start", TailerConfig{Lines: 3, FromStart: true}, "line3\nline4\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			config := tt.config
+			config.Path = archive + "::logs/app.log"
+
+			if err := NewTailer(config).Tail(context.Background(), &buf); err != nil {
+				t.Fatalf("Tail() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("follow is rejected", func(t *testing.T) {
+		var buf bytes.Buffer
+		tailer := NewTailer(TailerConfig{Path: archive + "::logs/app.log", Lines: 2, Follow: true})
+		if err := tailer.Tail(context.Background(), &buf); err == nil {
+			t.Error("expected an error following an archive member")
+		}
+	})
+}
+
+func TestTailer_LineEnding(t *testing.T) {
+	const mixed = "a\r\nb\nc\r\nd"
+	tests := []struct {
+		name    string
+		mode    LineEnding
+		content string
+		want    string
+	}{
+		{"lf normalises mixed endings", LineEndingLF, mixed, "a\nb\nc\nd\n"},
+		{"crlf normalises mixed endings", LineEndingCRLF, mixed, "a\r\nb\r\nc\r\nd\r\n"},
+		{"keep leaves mixed endings alone", LineEndingKeep, mixed, "a\r\nb\nc\r\nd"},
+		{"embedded CRs survive", LineEndingKeep, "x\na\r\rb\n", "x\na\r\rb\n"},
+		{"CRLF kept", LineEndingKeep, "a\r\nb\r\n", "a\r\nb\r\n"},
+		{"no final newline added", LineEndingKeep, "a\nb", "a\nb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			testFile := filepath.Join(dir, "test.bin")
+			if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			var buf bytes.Buffer
+			tailer := NewTailer(TailerConfig{Path: testFile, Lines: 10, LineEnding: tt.mode})
+			if err := tailer.Tail(context.Background(), &buf); err != nil {
+				t.Fatalf("Tail() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTailer_ZeroLinesWithFollow(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+
+	if err := os.WriteFile(testFile, []byte("old1\nold2\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	tailer := NewTailer(TailerConfig{
+		Path:         testFile,
+		Lines:        0,
+		LinesSet:     true,
+		Follow:       true,
 		PollInterval: 10 * time.Millisecond,
 	})
 
 	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
 	defer cancel()
 
-	// Run tailer in goroutine
 	done := make(chan error, 1)
 	go func() {
 		done <- tailer.Tail(ctx, &buf)
 	}()
 
-	// Wait for initial read
 	time.Sleep(50 * time.Millisecond)
 
-	// Append new content
 	f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
 		t.Fatalf("failed to open file: %v", err)
 	}
-	f.WriteString("appended\n")
+	f.WriteString("new\n")
 	f.Close()
 
-	// Wait for tailer to pick up change
 	time.Sleep(100 * time.Millisecond)
 	cancel()
+	<-done
+
+	if got := buf.String(); got != "new\n" {
+		t.Errorf("got %q, want only the appended line", got)
+	}
+}
+
+func TestTailer_ZeroLinesFromReader(t *testing.T) {
+	var buf bytes.Buffer
+	tailer := NewTailer(TailerConfig{Lines: 0, LinesSet: true})
+	if err := tailer.TailReader(context.Background(), strings.NewReader("a\nb\n"), &buf); err != nil {
+		t.Fatalf("TailReader() error = %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no output, got %q", buf.String())
+	}
+}
+
+// lockedBuffer is a bytes.Buffer safe for concurrent writers.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestTailer_DebugTrace(t *testing.T) {
+	for _, debug := range []bool{true, false} {
+		t.Run(fmt.Sprintf("debug=%v", debug), func(t *testing.T) {
+			dir := t.TempDir()
+			testFile := filepath.Join(dir, "test.log")
+
+			if err := os.WriteFile(testFile, []byte("one\ntwo\n"), 0644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			var out bytes.Buffer
+			var stderr lockedBuffer
+			tailer := NewTailer(TailerConfig{
+				Path:         testFile,
+				Lines:        10,
+				Follow:       true,
+				FollowName:   true,
+				PollInterval: 10 * time.Millisecond,
+				Debug:        debug,
+				Stderr:       &stderr,
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() {
+				done <- tailer.Tail(ctx, &out)
+			}()
+
+			time.Sleep(50 * time.Millisecond)
+			os.WriteFile(testFile, []byte("x\n"), 0644) // truncate in place
+			time.Sleep(100 * time.Millisecond)
+			cancel()
+			<-done
+
+			got := stderr.String()
+			if !debug {
+				if got != "" {
+					t.Errorf("expected no trace without Debug, got %q", got)
+				}
+				return
+			}
+			for _, want := range []string{"wail[debug]: ", "opened (size 8", "truncation detected (size 8 -> 2)", "read 2 bytes"} {
+				if !strings.Contains(got, want) {
+					t.Errorf("trace missing %q, got:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestTailer_Follow_DrainsOnCancel(t *testing.T) {
+	for _, followName := range []bool{false, true} {
+		t.Run(fmt.Sprintf("followName=%v", followName), func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), "test.log")
+			if err := os.WriteFile(testFile, []byte("a\n"), 0644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			// The fake clock never ticks, so only the final drain can read
+			// what is appended
+			clk := newFakeClock()
+			tailer := NewTailer(TailerConfig{
+				Path:         testFile,
+				Lines:        10,
+				Follow:       true,
+				FollowName:   followName,
+				ForcePoll:    true,
+				PollInterval: 10 * time.Millisecond,
+			}).(*tailer)
+			tailer.clock = clk
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var buf lockedBuffer
+			done := make(chan error, 1)
+			go func() {
+				done <- tailer.Tail(ctx, &buf)
+			}()
+			clk.waitForWaiters(t, 1)
+
+			f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				t.Fatalf("failed to open file: %v", err)
+			}
+			f.WriteString("b\nc")
+			f.Close()
+			cancel()
+
+			if err := <-done; err != nil {
+				t.Fatalf("Tail() error: %v", err)
+			}
+			if got, want := buf.String(), "a\nb\nc\n"; got != want {
+				t.Errorf("output = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestTailer_Follow_PartialLines(t *testing.T) {
+	for _, followName := range []bool{false, true} {
+		t.Run(fmt.Sprintf("followName=%v", followName), func(t *testing.T) {
+			dir := t.TempDir()
+			testFile := filepath.Join(dir, "test.log")
+
+			if err := os.WriteFile(testFile, []byte("start\n"), 0644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			tailer := NewTailer(TailerConfig{
+				Path:         testFile,
+				Lines:        10,
+				Follow:       true,
+				FollowName:   followName,
+				PollInterval: 10 * time.Millisecond,
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			var mu sync.Mutex
+			var got []string
+			done := make(chan error, 1)
+			go func() {
+				done <- tailer.TailFunc(ctx, func(line Line) error {
+					mu.Lock()
+					defer mu.Unlock()
+					got = append(got, line.Text)
+					return nil
+				})
+			}()
+
+			time.Sleep(50 * time.Millisecond)
+
+			// Write one line in two pieces, several polls apart, then leave a
+			// partial line pending when following stops
+			f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				t.Fatalf("failed to open file: %v", err)
+			}
+			defer f.Close()
+			f.WriteString("hello")
+			time.Sleep(100 * time.Millisecond)
+			f.WriteString(" world\n")
+			time.Sleep(50 * time.Millisecond)
+			f.WriteString("unfinished")
+			time.Sleep(50 * time.Millisecond)
+
+			cancel()
+			if err := <-done; err != nil {
+				t.Fatalf("TailFunc() error = %v", err)
+			}
+
+			want := []string{"start", "hello world", "unfinished"}
+			mu.Lock()
+			defer mu.Unlock()
+			if !slices.Equal(got, want) {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestTailer_Follow_SplitCRLF(t *testing.T) {
+	for _, followName := range []bool{false, true} {
+		t.Run(fmt.Sprintf("followName=%v", followName), func(t *testing.T) {
+			dir := t.TempDir()
+			testFile := filepath.Join(dir, "test.log")
+			os.WriteFile(testFile, []byte("start\r\n"), 0644)
+
+			var buf lockedBuffer
+			tailer := NewTailer(TailerConfig{
+				Path:         testFile,
+				Lines:        10,
+				Follow:       true,
+				FollowName:   followName,
+				PollInterval: 10 * time.Millisecond,
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			done := make(chan error, 1)
+			go func() {
+				done <- tailer.Tail(ctx, &buf)
+			}()
+			time.Sleep(50 * time.Millisecond)
+
+			// A CRLF split between polls: the CR alone doesn't end the line
+			f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				t.Fatalf("failed to open file: %v", err)
+			}
+			defer f.Close()
+			f.WriteString("hello\r")
+			time.Sleep(100 * time.Millisecond)
+			f.WriteString("\nnext\r\n")
+			time.Sleep(50 * time.Millisecond)
+
+			cancel()
+			if err := <-done; err != nil {
+				t.Fatalf("Tail() error = %v", err)
+			}
+			if got, want := buf.String(), "start\nhello\nnext\n"; got != want {
+				t.Errorf("output = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestTailer_Follow_SplitRune(t *testing.T) {
+	for _, followName := range []bool{false, true} {
+		t.Run(fmt.Sprintf("followName=%v", followName), func(t *testing.T) {
+			dir := t.TempDir()
+			testFile := filepath.Join(dir, "test.log")
+			os.WriteFile(testFile, []byte("start\n"), 0644)
+
+			// Cutting lines to a width works in runes, so it would be the
+			// first to mangle a rune read in halves
+			tailer := NewTailer(TailerConfig{
+				Path:         testFile,
+				Lines:        10,
+				Follow:       true,
+				FollowName:   followName,
+				PollInterval: 10 * time.Millisecond,
+				MaxLineWidth: func() int { return 80 },
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			var mu sync.Mutex
+			var got []string
+			done := make(chan error, 1)
+			go func() {
+				done <- tailer.TailFunc(ctx, func(line Line) error {
+					mu.Lock()
+					defer mu.Unlock()
+					got = append(got, line.Text)
+					return nil
+				})
+			}()
+			time.Sleep(50 * time.Millisecond)
+
+			// "é" is 0xC3 0xA9; the writer flushes it in two pieces, polls apart
+			f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				t.Fatalf("failed to open file: %v", err)
+			}
+			defer f.Close()
+			f.Write([]byte("caf\xc3"))
+			time.Sleep(100 * time.Millisecond)
+			f.Write([]byte("\xa9\n"))
+			time.Sleep(100 * time.Millisecond)
+
+			cancel()
+			if err := <-done; err != nil {
+				t.Fatalf("TailFunc() error = %v", err)
+			}
+
+			want := []string{"start", "café"}
+			mu.Lock()
+			defer mu.Unlock()
+			if !slices.Equal(got, want) {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestTailer_Follow_FileSystemEvents(t *testing.T) {
+	tests := []struct {
+		name      string
+		forcePoll bool
+		want      string
+	}{
+		{"events wake the loop", false, "start\nnew\n"},
+		{"forced polling waits", true, "start\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			testFile := filepath.Join(dir, "test.log")
+			if err := os.WriteFile(testFile, []byte("start\n"), 0644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			// A poll interval far longer than the test, so only an event
+			// can deliver the new line in time
+			var buf lockedBuffer
+			tailer := NewTailer(TailerConfig{
+				Path:         testFile,
+				Lines:        10,
+				Follow:       true,
+				ForcePoll:    tt.forcePoll,
+				PollInterval: time.Minute,
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() {
+				done <- tailer.Tail(ctx, &buf)
+			}()
+
+			time.Sleep(50 * time.Millisecond)
+			f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				t.Fatalf("failed to open file: %v", err)
+			}
+			f.WriteString("new\n")
+			f.Close()
+
+			// Checked before cancelling, which reads to the end regardless
+			time.Sleep(200 * time.Millisecond)
+			got := buf.String()
+			cancel()
+			<-done
+
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTailer_ZeroTerminated_NoDelimiters(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+
+	// Newline-delimited text, far bigger than one record can be
+	content := strings.Repeat("an ordinary log line\n", maxLineSize/20)
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
 
+	tailer := NewTailer(TailerConfig{Path: testFile, Lines: 1, ZeroTerminated: true})
+	err := tailer.Tail(context.Background(), io.Discard)
+	if !errors.Is(err, ErrRecordTooLong) {
+		t.Fatalf("Tail() error = %v, want ErrRecordTooLong", err)
+	}
+	if !strings.Contains(err.Error(), "try without -z") {
+		t.Errorf("error %q lacks the -z hint", err)
+	}
+}
+
+func TestTailer_StartOffset(t *testing.T) {
+	content := "line1\nline2\nline3\n"
+
+	tests := []struct {
+		name    string
+		offset  int64
+		clamp   bool
+		want    string
+		wantErr bool
+	}{
+		{"start of file", 0, false, "line1\nline2\nline3\n", false},
+		{"line boundary", 6, false, "line2\nline3\n", false},
+		{"mid-line", 8, false, "ne2\nline3\n", false},
+		{"end of file", 18, false, "", false},
+		{"past the end", 100, false, "", true},
+		{"past the end, clamped", 100, true, "", false},
+	}
+
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	for _, tt := range tests {
+		config := TailerConfig{Lines: 1, StartOffset: tt.offset, StartOffsetSet: true, ClampStartOffset: tt.clamp}
+
+		t.Run(tt.name+"/file", func(t *testing.T) {
+			config.Path = testFile
+			var buf bytes.Buffer
+			err := NewTailer(config).Tail(context.Background(), &buf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Tail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+
+		t.Run(tt.name+"/reader", func(t *testing.T) {
+			var buf bytes.Buffer
+			err := NewTailer(config).TailReader(context.Background(), strings.NewReader(content), &buf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TailReader() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTailer_StartOffset_Follow(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+	if err := os.WriteFile(testFile, []byte("line1\nline2\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	var buf lockedBuffer
+	tailer := NewTailer(TailerConfig{
+		Path:           testFile,
+		StartOffset:    6,
+		StartOffsetSet: true,
+		Follow:         true,
+		PollInterval:   10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tailer.Tail(ctx, &buf)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	f.WriteString("line3\n")
+	f.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
 	<-done
 
-	got := buf.String()
-	if !strings.Contains(got, "initial") {
-		t.Errorf("expected 'initial' in output, got %q", got)
+	if got, want := buf.String(), "line2\nline3\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
 	}
-	if !strings.Contains(got, "appended") {
-		t.Errorf("expected 'appended' in output, got %q", got)
+}
+
+func TestTailer_Follow_SymlinkRepointed(t *testing.T) {
+	tests := []struct {
+		name         string
+		followName   bool
+		followTarget bool
+		want         string
+	}{
+		{"descriptor stays on old target", false, false, "a1\na2\n"},
+		{"descriptor follows new target", false, true, "a1\nb1\nb2\n"},
+		{"name follows new target", true, false, "a1\nb1\nb2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			a := filepath.Join(dir, "a.log")
+			b := filepath.Join(dir, "b.log")
+			link := filepath.Join(dir, "current.log")
+			if err := os.WriteFile(a, []byte("a1\n"), 0644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+			if err := os.WriteFile(b, []byte("b1\n"), 0644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+			if err := os.Symlink(a, link); err != nil {
+				t.Skipf("symlinks not available: %v", err)
+			}
+
+			var buf lockedBuffer
+			tailer := NewTailer(TailerConfig{
+				Path:                link,
+				Lines:               10,
+				Follow:              true,
+				FollowName:          tt.followName,
+				FollowSymlinkTarget: tt.followTarget,
+				PollInterval:        10 * time.Millisecond,
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() {
+				done <- tailer.Tail(ctx, &buf)
+			}()
+
+			// Repoint the link atomically, then write to both targets
+			time.Sleep(50 * time.Millisecond)
+			tmp := link + ".tmp"
+			if err := os.Symlink(b, tmp); err != nil {
+				t.Fatalf("failed to create symlink: %v", err)
+			}
+			if err := os.Rename(tmp, link); err != nil {
+				t.Fatalf("failed to repoint symlink: %v", err)
+			}
+			time.Sleep(100 * time.Millisecond)
+			for path, text := range map[string]string{a: "a2\n", b: "b2\n"} {
+				f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+				if err != nil {
+					t.Fatalf("failed to open file: %v", err)
+				}
+				f.WriteString(text)
+				f.Close()
+			}
+			time.Sleep(100 * time.Millisecond)
+
+			cancel()
+			if err := <-done; err != nil {
+				t.Fatalf("Tail() error: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTailer_Metrics(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+	if err := os.WriteFile(testFile, []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	registry := metrics.NewRegistry()
+	var buf lockedBuffer
+	tailer := NewTailer(TailerConfig{
+		Path:         testFile,
+		Lines:        10,
+		Follow:       true,
+		PollInterval: 10 * time.Millisecond,
+		Metrics:      registry,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tailer.Tail(ctx, &buf)
+	}()
+
+	// Truncate and rewrite in place, which counts as a rotation
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(testFile, []byte("three\n"), 0644); err != nil {
+		t.Fatalf("failed to rewrite test file: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Tail() error: %v", err)
+	}
+	if got := buf.String(); got != "one\ntwo\nthree\n" {
+		t.Fatalf("output = %q", got)
+	}
+
+	var text strings.Builder
+	registry.WriteText(&text)
+	for _, want := range []string{
+		fmt.Sprintf("wail_lines_emitted_total{file=%q} 3\n", testFile),
+		fmt.Sprintf("wail_bytes_emitted_total{file=%q} 14\n", testFile),
+		fmt.Sprintf("wail_rotations_total{file=%q} 1\n", testFile),
+	} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("metrics missing %q:\n%s", want, text.String())
+		}
+	}
+}
+
+func TestTailer_Filter(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+	if err := os.WriteFile(testFile, []byte("info: up\nerror: disk\ninfo: ok\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	var mu sync.Mutex
+	var live []string
+	var buf lockedBuffer
+	tailer := NewTailer(TailerConfig{
+		Path:         testFile,
+		Lines:        10,
+		Follow:       true,
+		PollInterval: 10 * time.Millisecond,
+		LiveMarker:   "--- following ---",
+		Filter:       regexp.MustCompile(`^error`),
+		OnLive: func(line Line) {
+			mu.Lock()
+			live = append(live, line.Text)
+			mu.Unlock()
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tailer.Tail(ctx, &buf)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	f.WriteString("info: fine\nerror: net\n")
+	f.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Tail() error: %v", err)
+	}
+
+	// The marker passes the filter; only live matches reach the hook
+	if got, want := buf.String(), "error: disk\n--- following ---\nerror: net\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(live, []string{"error: net"}) {
+		t.Errorf("OnLive got %q, want [\"error: net\"]", live)
+	}
+}
+
+func TestTailer_HeadTail(t *testing.T) {
+	numbered := func(from, to int) string {
+		var b strings.Builder
+		for i := from; i <= to; i++ {
+			fmt.Fprintf(&b, "line%d\n", i)
+		}
+		return b.String()
+	}
+
+	tests := []struct {
+		name  string
+		lines int
+		n     int
+		want  string
+	}{
+		{"elides the middle", 20, 3, numbered(1, 3) + "... (14 lines omitted) ...\n" + numbered(18, 20)},
+		{"one line omitted", 7, 3, numbered(1, 3) + "... (1 line omitted) ...\n" + numbered(5, 7)},
+		{"head and tail meet", 6, 3, numbered(1, 6)},
+		{"head and tail overlap", 4, 3, numbered(1, 4)},
+		{"empty", 0, 3, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			testFile := filepath.Join(dir, "test.log")
+			if err := os.WriteFile(testFile, []byte(numbered(1, tt.lines)), 0644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			var buf bytes.Buffer
+			tailer := NewTailer(TailerConfig{Path: testFile, HeadTail: tt.n})
+			if err := tailer.Tail(context.Background(), &buf); err != nil {
+				t.Fatalf("Tail() error: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateLine(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"exactly", 7, "exactly"},
+		{"too long by one", 14, "too long by o…"},
+		{"héllo wörld", 6, "héllo…"},
+		{"abc", 1, "…"},
+		{"unlimited", 0, "unlimited"},
+	}
+
+	for _, tt := range tests {
+		if got := truncateLine(tt.text, tt.width); got != tt.want {
+			t.Errorf("truncateLine(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestTailer_FollowName_KeepOpen(t *testing.T) {
+	for _, keepOpen := range []bool{false, true} {
+		t.Run(fmt.Sprintf("keepOpen=%v", keepOpen), func(t *testing.T) {
+			dir := t.TempDir()
+			testFile := filepath.Join(dir, "test.log")
+			if err := os.WriteFile(testFile, []byte("start\n"), 0644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			var buf lockedBuffer
+			tailer := NewTailer(TailerConfig{
+				Path:         testFile,
+				Lines:        10,
+				Follow:       true,
+				FollowName:   true,
+				KeepOpen:     keepOpen,
+				PollInterval: 10 * time.Millisecond,
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() {
+				done <- tailer.Tail(ctx, &buf)
+			}()
+
+			appendTo := func(text string) {
+				f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+				if err != nil {
+					t.Fatalf("failed to open file: %v", err)
+				}
+				f.WriteString(text)
+				f.Close()
+				time.Sleep(100 * time.Millisecond)
+			}
+
+			// Two reads of the same file, then a rotation to a new one
+			time.Sleep(50 * time.Millisecond)
+			appendTo("one\n")
+			appendTo("two\n")
+			if err := os.Rename(testFile, testFile+".1"); err != nil {
+				t.Fatalf("failed to rotate: %v", err)
+			}
+			if err := os.WriteFile(testFile, []byte("three\n"), 0644); err != nil {
+				t.Fatalf("failed to create new file: %v", err)
+			}
+			time.Sleep(100 * time.Millisecond)
+			appendTo("four\n")
+
+			cancel()
+			if err := <-done; err != nil {
+				t.Fatalf("Tail() error: %v", err)
+			}
+			if got, want := buf.String(), "start\none\ntwo\nthree\nfour\n"; got != want {
+				t.Errorf("output = %q, want %q", got, want)
+			}
+		})
 	}
 }
 
@@ -215,34 +1415,262 @@ func TestTailer_RetryWaitsForFile(t *testing.T) {
 		t.Fatalf("failed to create test file: %v", err)
 	}
 
-	// Wait for tailer to pick up the file
-	time.Sleep(100 * time.Millisecond)
-	cancel()
+	// Wait for tailer to pick up the file
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	<-done
+
+	got := buf.String()
+	if !strings.Contains(got, "delayed content") {
+		t.Errorf("expected 'delayed content' in output, got %q", got)
+	}
+}
+
+func TestTailer_RetryFalseFailsImmediately(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "nonexistent.log")
+
+	var buf bytes.Buffer
+	tailer := NewTailer(TailerConfig{
+		Path:   testFile,
+		Lines:  10,
+		Follow: true,
+		Retry:  false, // Default - should fail immediately
+	})
+
+	ctx := context.Background()
+	err := tailer.Tail(ctx, &buf)
+	if err == nil {
+		t.Error("expected error for non-existent file without retry")
+	}
+}
+
+func TestTailer_RetryTimeout(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "never.log")
+
+	clk := newFakeClock()
+	tailer := NewTailer(TailerConfig{
+		Path:         testFile,
+		Lines:        10,
+		Retry:        true,
+		RetryTimeout: 50 * time.Millisecond,
+		PollInterval: 10 * time.Millisecond,
+	}).(*tailer)
+	tailer.clock = clk
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tailer.Tail(context.Background(), &bytes.Buffer{})
+	}()
+
+	// The retry ticker and the deadline; nothing gives up until the
+	// clock reaches the timeout
+	clk.waitForWaiters(t, 2)
+	clk.advance(40 * time.Millisecond)
+	select {
+	case err := <-done:
+		t.Fatalf("gave up before the retry timeout: %v", err)
+	default:
+	}
+
+	clk.advance(10 * time.Millisecond)
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "did not appear within 50ms") {
+			t.Fatalf("expected timeout error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("did not give up after the retry timeout")
+	}
+}
+
+func TestTailer_RetryTimeout_CancelTakesPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "never.log")
+
+	var buf bytes.Buffer
+	tailer := NewTailer(TailerConfig{
+		Path:         testFile,
+		Lines:        10,
+		Retry:        true,
+		RetryTimeout: time.Second,
+		PollInterval: 10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := tailer.Tail(ctx, &buf); err != nil {
+		t.Errorf("expected nil error on cancellation, got %v", err)
+	}
+}
+
+func TestTailer_RetryAttempts(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		wantErr string
+	}{
+		{"attempts alone", 0, "did not appear after 3 attempts"},
+		{"attempts before timeout", time.Minute, "did not appear after 3 attempts"},
+		{"timeout before attempts", 30 * time.Millisecond, "did not appear within 30ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			testFile := filepath.Join(dir, "never.log")
+
+			// Attempts are one poll apart, so the timeout case gives up
+			// well before its third attempt
+			interval := 10 * time.Millisecond
+			if tt.timeout > 0 && tt.timeout < time.Second {
+				interval = 100 * time.Millisecond
+			}
+			var buf bytes.Buffer
+			tailer := NewTailer(TailerConfig{
+				Path:          testFile,
+				Lines:         10,
+				Retry:         true,
+				RetryAttempts: 3,
+				RetryTimeout:  tt.timeout,
+				PollInterval:  interval,
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			err := tailer.Tail(ctx, &buf)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Tail() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTailer_RetryAttempts_Succeeds(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "late.log")
+
+	var buf bytes.Buffer
+	tailer := NewTailer(TailerConfig{
+		Path:          testFile,
+		Lines:         10,
+		Retry:         true,
+		RetryAttempts: 50,
+		PollInterval:  10 * time.Millisecond,
+	})
+
+	go func() {
+		// Rename into place so the file never appears empty
+		time.Sleep(50 * time.Millisecond)
+		tmp := filepath.Join(dir, "late.tmp")
+		os.WriteFile(tmp, []byte("here\n"), 0644)
+		os.Rename(tmp, testFile)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := tailer.Tail(ctx, &buf); err != nil {
+		t.Fatalf("Tail() error: %v", err)
+	}
+	if got := buf.String(); got != "here\n" {
+		t.Errorf("output = %q, want %q", got, "here\n")
+	}
+}
 
-	<-done
+// deniedOpener refuses to open anything while denied is set, as the OS does
+// a file its reader has no permission for.
+type deniedOpener struct {
+	denied *atomic.Bool
+}
 
-	got := buf.String()
-	if !strings.Contains(got, "delayed content") {
-		t.Errorf("expected 'delayed content' in output, got %q", got)
+func (o *deniedOpener) Open(name string) (filesystem.ReadSeekCloser, error) {
+	if o.denied.Load() {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrPermission}
 	}
+	return filesystem.NewFileOpener().Open(name)
 }
 
-func TestTailer_RetryFalseFailsImmediately(t *testing.T) {
-	dir := t.TempDir()
-	testFile := filepath.Join(dir, "nonexistent.log")
+func TestTailer_RetryPermissionDenied(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "app.log")
+	os.WriteFile(testFile, []byte("line1\n"), 0644)
+
+	t.Run("readable later", func(t *testing.T) {
+		var denied atomic.Bool
+		denied.Store(true)
+		var buf bytes.Buffer
+		tl := NewTailer(TailerConfig{
+			Path:          testFile,
+			Lines:         10,
+			Retry:         true,
+			RetryAttempts: 50,
+			PollInterval:  10 * time.Millisecond,
+		}).(*tailer)
+		tl.opener = &deniedOpener{denied: &denied}
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			denied.Store(false)
+		}()
+		if err := tl.Tail(context.Background(), &buf); err != nil {
+			t.Fatalf("Tail() error: %v", err)
+		}
+		if got := buf.String(); got != "line1\n" {
+			t.Errorf("output = %q, want %q", got, "line1\n")
+		}
+	})
+
+	t.Run("never readable", func(t *testing.T) {
+		var denied atomic.Bool
+		denied.Store(true)
+		tl := NewTailer(TailerConfig{
+			Path:          testFile,
+			Lines:         10,
+			Retry:         true,
+			RetryAttempts: 3,
+			PollInterval:  10 * time.Millisecond,
+		}).(*tailer)
+		tl.opener = &deniedOpener{denied: &denied}
+
+		err := tl.Tail(context.Background(), &bytes.Buffer{})
+		if err == nil || !strings.Contains(err.Error(), "still not readable after 3 attempts") || !errors.Is(err, os.ErrPermission) {
+			t.Errorf("Tail() error = %v, want a permission error after 3 attempts", err)
+		}
+	})
+}
+
+// TestTailer_RetryPermissionDenied_Chmod retries a real file whose mode
+// forbids reading until it is fixed.
+func TestTailer_RetryPermissionDenied_Chmod(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes don't control reading on Windows")
+	}
+	if os.Geteuid() == 0 {
+		t.Skip("root can read any file")
+	}
+	testFile := filepath.Join(t.TempDir(), "app.log")
+	os.WriteFile(testFile, []byte("line1\n"), 0000)
 
 	var buf bytes.Buffer
 	tailer := NewTailer(TailerConfig{
-		Path:   testFile,
-		Lines:  10,
-		Follow: true,
-		Retry:  false, // Default - should fail immediately
+		Path:          testFile,
+		Lines:         10,
+		Retry:         true,
+		RetryAttempts: 50,
+		PollInterval:  10 * time.Millisecond,
 	})
-
-	ctx := context.Background()
-	err := tailer.Tail(ctx, &buf)
-	if err == nil {
-		t.Error("expected error for non-existent file without retry")
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		os.Chmod(testFile, 0644)
+	}()
+	if err := tailer.Tail(context.Background(), &buf); err != nil {
+		t.Fatalf("Tail() error: %v", err)
+	}
+	if got := buf.String(); got != "line1\n" {
+		t.Errorf("output = %q, want %q", got, "line1\n")
 	}
 }
 
@@ -302,6 +1730,274 @@ func TestTailer_FollowName_FileRotation(t *testing.T) {
 	}
 }
 
+func TestTailer_SameFile_NetworkPath(t *testing.T) {
+	dir := t.TempDir()
+	stat := func(name, content string, mtime time.Time) os.FileInfo {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(content), 0644)
+		os.Chtimes(path, mtime, mtime)
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("stat: %v", err)
+		}
+		return info
+	}
+	then := time.Now().Add(-time.Hour)
+	old := stat("old.log", "0123456789\n", then)
+
+	tests := []struct {
+		name        string
+		info        os.FileInfo
+		wantLocal   bool
+		wantNetwork bool
+	}{
+		{"itself", old, true, true},
+		{"another file, grown and newer", stat("grown.log", "0123456789\nmore\n", then.Add(time.Minute)), false, true},
+		{"another file, smaller", stat("small.log", "new\n", then.Add(time.Minute)), false, false},
+		{"another file, older", stat("older.log", "0123456789\n", then.Add(-time.Minute)), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tl := NewTailer(TailerConfig{Path: filepath.Join(dir, "old.log")}).(*tailer)
+			if got := tl.sameFile(old, tt.info); got != tt.wantLocal {
+				t.Errorf("local sameFile() = %v, want %v", got, tt.wantLocal)
+			}
+			tl.network = true
+			if got := tl.sameFile(old, tt.info); got != tt.wantNetwork {
+				t.Errorf("network sameFile() = %v, want %v", got, tt.wantNetwork)
+			}
+		})
+	}
+}
+
+func TestTailer_FollowName_NetworkPath(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "share.log")
+	os.WriteFile(testFile, []byte("original\n"), 0644)
+
+	tl := NewTailer(TailerConfig{
+		Path:         testFile,
+		Lines:        10,
+		Follow:       true,
+		FollowName:   true,
+		Retry:        true,
+		PollInterval: 10 * time.Millisecond,
+	}).(*tailer)
+	tl.network = true // As for a UNC path on Windows
+
+	var buf lockedBuffer
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- tl.Tail(ctx, &buf)
+	}()
+
+	// Growth is read as more of the same file, not a rotation
+	time.Sleep(50 * time.Millisecond)
+	f, _ := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("appended\n")
+	f.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	// A smaller file in its place is a rotation
+	os.Rename(testFile, testFile+".1")
+	os.WriteFile(testFile, []byte("new\n"), 0644)
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Tail() error: %v", err)
+	}
+
+	if got, want := buf.String(), "original\nappended\nnew\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestTailer_FollowName_ReapplyWindow(t *testing.T) {
+	var backlog strings.Builder
+	for i := 1; i <= 100; i++ {
+		fmt.Fprintf(&backlog, "new%d\n", i)
+	}
+
+	tests := []struct {
+		name    string
+		reapply bool
+		want    string
+	}{
+		{"whole new file by default", false, "old1\nold2\n" + backlog.String() + "live\n"},
+		{"last 5 lines of the new file", true, "old1\nold2\nnew96\nnew97\nnew98\nnew99\nnew100\nlive\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			testFile := filepath.Join(dir, "app.log")
+			os.WriteFile(testFile, []byte("old1\nold2\n"), 0644)
+
+			var buf lockedBuffer
+			tailer := NewTailer(TailerConfig{
+				Path:          testFile,
+				Lines:         5,
+				Follow:        true,
+				FollowName:    true,
+				Retry:         true,
+				ForcePoll:     true,
+				PollInterval:  10 * time.Millisecond,
+				ReapplyWindow: tt.reapply,
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			done := make(chan error, 1)
+			go func() {
+				done <- tailer.Tail(ctx, &buf)
+			}()
+			time.Sleep(50 * time.Millisecond)
+
+			// Rotate to a file that already has a backlog, written in full
+			// before it takes the name
+			tmp := filepath.Join(dir, "app.log.new")
+			os.WriteFile(tmp, []byte(backlog.String()), 0644)
+			os.Rename(testFile, testFile+".1")
+			os.Rename(tmp, testFile)
+			time.Sleep(100 * time.Millisecond)
+
+			// Lines written after the rotation follow as usual
+			f, _ := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+			f.WriteString("live\n")
+			f.Close()
+			time.Sleep(100 * time.Millisecond)
+
+			cancel()
+			if err := <-done; err != nil {
+				t.Fatalf("Tail() error: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTailer_FollowName_ReapplyWindowIsLive(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "app.log")
+	os.WriteFile(testFile, []byte("old1\nold2\n"), 0644)
+
+	var mu sync.Mutex
+	var live []string
+	var buf lockedBuffer
+	tailer := NewTailer(TailerConfig{
+		Path:          testFile,
+		Lines:         2,
+		Follow:        true,
+		FollowName:    true,
+		Retry:         true,
+		ForcePoll:     true,
+		PollInterval:  10 * time.Millisecond,
+		ReapplyWindow: true,
+		OnLive: func(line Line) {
+			mu.Lock()
+			defer mu.Unlock()
+			live = append(live, line.Text)
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- tailer.Tail(ctx, &buf)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	tmp := filepath.Join(dir, "app.log.new")
+	os.WriteFile(tmp, []byte("new1\nnew2\nnew3\n"), 0644)
+	os.Rename(testFile, testFile+".1")
+	os.Rename(tmp, testFile)
+	time.Sleep(100 * time.Millisecond)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Tail() error: %v", err)
+	}
+
+	// The window taken from the new file is live output, unlike the
+	// initial read of the old one
+	mu.Lock()
+	defer mu.Unlock()
+	if got, want := strings.Join(live, ","), "new2,new3"; got != want {
+		t.Errorf("OnLive lines = %q, want %q", got, want)
+	}
+}
+
+func TestTailer_FollowName_BytesRotation(t *testing.T) {
+	// Nothing here ends in a newline: byte mode streams raw bytes as they
+	// arrive, never holding back a partial line or adding a delimiter
+	tests := []struct {
+		name    string
+		reapply bool
+		want    string
+	}{
+		{"whole new file by default", false, "6789XYabcdefghijklm"},
+		{"last 4 bytes of the new file", true, "6789XYghijklm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			testFile := filepath.Join(dir, "app.log")
+			os.WriteFile(testFile, []byte("0123456789"), 0644)
+
+			var buf lockedBuffer
+			tailer := NewTailer(TailerConfig{
+				Path:          testFile,
+				Bytes:         4,
+				Follow:        true,
+				FollowName:    true,
+				Retry:         true,
+				ForcePoll:     true,
+				PollInterval:  10 * time.Millisecond,
+				ReapplyWindow: tt.reapply,
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			done := make(chan error, 1)
+			go func() {
+				done <- tailer.Tail(ctx, &buf)
+			}()
+			time.Sleep(50 * time.Millisecond)
+
+			f, _ := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+			f.WriteString("XY")
+			f.Close()
+			time.Sleep(50 * time.Millisecond)
+
+			tmp := filepath.Join(dir, "app.log.new")
+			os.WriteFile(tmp, []byte("abcdefghij"), 0644)
+			os.Rename(testFile, testFile+".1")
+			os.Rename(tmp, testFile)
+			time.Sleep(100 * time.Millisecond)
+
+			f, _ = os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+			f.WriteString("klm")
+			f.Close()
+			time.Sleep(100 * time.Millisecond)
+
+			cancel()
+			if err := <-done; err != nil {
+				t.Fatalf("Tail() error: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestTailer_FromReader(t *testing.T) {
 	input := strings.NewReader("line1\nline2\nline3\nline4\nline5\n")
 	var buf bytes.Buffer
@@ -377,6 +2073,76 @@ func TestTailer_StartFromLine(t *testing.T) {
 	}
 }
 
+func TestTailer_AllButLast(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+
+	content := "line1\nline2\nline3\nline4\nline5\n"
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		lines int
+		want  string
+	}{
+		{0, content},
+		{2, "line1\nline2\nline3\n"},
+		{5, ""},
+		{10, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("~%d", tt.lines), func(t *testing.T) {
+			var buf bytes.Buffer
+			tailer := NewTailer(TailerConfig{
+				Path:       testFile,
+				Lines:      tt.lines,
+				AllButLast: true,
+			})
+
+			if err := tailer.Tail(context.Background(), &buf); err != nil {
+				t.Fatalf("Tail() error = %v", err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTailer_AllButLast_Streams(t *testing.T) {
+	pr, pw := io.Pipe()
+	var buf lockedBuffer
+	tailer := NewTailer(TailerConfig{Lines: 2, AllButLast: true})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tailer.TailReader(context.Background(), pr, &buf)
+	}()
+
+	// A line is written out once two more have followed it, without
+	// waiting for the end of the input
+	pw.Write([]byte("line1\nline2\nline3\nline4\n"))
+	deadline := time.Now().Add(2 * time.Second)
+	for buf.String() != "line1\nline2\n" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got, want := buf.String(), "line1\nline2\n"; got != want {
+		t.Fatalf("before the input ends, got %q, want %q", got, want)
+	}
+
+	pw.Write([]byte("line5\n"))
+	pw.Close()
+	if err := <-done; err != nil {
+		t.Fatalf("TailReader() error = %v", err)
+	}
+	if got, want := buf.String(), "line1\nline2\nline3\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
 func TestTailer_PIDTerminatesWhenProcessDies(t *testing.T) {
 	dir := t.TempDir()
 	testFile := filepath.Join(dir, "test.log")
@@ -419,6 +2185,95 @@ func TestTailer_PIDTerminatesWhenProcessDies(t *testing.T) {
 	}
 }
 
+func TestTailer_PIDPollInterval(t *testing.T) {
+	// Run as a child process that lives a little while, then exits
+	if os.Getenv("WAIL_PID_HELPER") != "" {
+		time.Sleep(200 * time.Millisecond)
+		return
+	}
+
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+	os.WriteFile(testFile, []byte("initial\n"), 0644)
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestTailer_PIDPollInterval$")
+	cmd.Env = append(os.Environ(), "WAIL_PID_HELPER=1")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start child process: %v", err)
+	}
+	go cmd.Wait() // Reap the child so it doesn't linger as a zombie
+
+	// Files are polled slowly, but the process is checked often
+	var buf bytes.Buffer
+	tailer := NewTailer(TailerConfig{
+		Path:            testFile,
+		Lines:           10,
+		Follow:          true,
+		ForcePoll:       true,
+		PID:             cmd.Process.Pid,
+		PollInterval:    10 * time.Second,
+		PIDPollInterval: 10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := tailer.Tail(ctx, &buf); err != nil {
+		t.Fatalf("Tail() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("took %v to notice the process exit, want well under the 10s file poll", elapsed)
+	}
+}
+
+func TestTailer_MultiplePIDs(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+
+	if err := os.WriteFile(testFile, []byte("initial\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	// One live process (ourselves) and one that doesn't exist
+	pids := []int{os.Getpid(), 999999999}
+
+	tests := []struct {
+		name      string
+		anyDies   bool
+		wantEarly bool
+	}{
+		{"all must die keeps following", false, false},
+		{"any dies stops following", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tailer := NewTailer(TailerConfig{
+				Path:         testFile,
+				Lines:        10,
+				Follow:       true,
+				PIDs:         pids,
+				AnyPIDDies:   tt.anyDies,
+				PollInterval: 10 * time.Millisecond,
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			if err := tailer.Tail(ctx, &buf); err != nil {
+				t.Fatalf("Tail() error = %v", err)
+			}
+			early := time.Since(start) < 200*time.Millisecond
+
+			if early != tt.wantEarly {
+				t.Errorf("exited early = %v, want %v", early, tt.wantEarly)
+			}
+		})
+	}
+}
+
 func TestTailer_ZeroTerminated(t *testing.T) {
 	dir := t.TempDir()
 	testFile := filepath.Join(dir, "test.log")
@@ -449,6 +2304,38 @@ func TestTailer_ZeroTerminated(t *testing.T) {
 	}
 }
 
+func TestTailer_OutputDelimiter(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		config  TailerConfig
+		want    string
+	}{
+		{"NUL in, newline out", "line1\x00line2\x00line3\x00", TailerConfig{ZeroTerminated: true, OutputDelimiter: '\n', OutputDelimiterSet: true}, "line2\nline3\n"},
+		{"newline in, NUL out", "line1\nline2\nline3\n", TailerConfig{OutputDelimiter: '\x00', OutputDelimiterSet: true}, "line2\x00line3\x00"},
+		{"unset keeps the input delimiter", "line1\x00line2\x00line3\x00", TailerConfig{ZeroTerminated: true}, "line2\x00line3\x00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), "test.log")
+			os.WriteFile(testFile, []byte(tt.content), 0644)
+
+			config := tt.config
+			config.Path = testFile
+			config.Lines = 2
+
+			var buf bytes.Buffer
+			if err := NewTailer(config).Tail(context.Background(), &buf); err != nil {
+				t.Fatalf("Tail() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestTailer_MaxUnchangedStats(t *testing.T) {
 	// This test verifies that the MaxUnchangedStats config is accepted
 	// Full behavior testing would require complex rotation simulation
@@ -517,6 +2404,268 @@ func TestTailer_LargeFile_BackwardRead(t *testing.T) {
 	}
 }
 
+func TestTailer_BackwardRead_ChunkBoundaries(t *testing.T) {
+	dir := t.TempDir()
+
+	// lastLines is the last n lines of content, split the simple way
+	lastLines := func(content string, n int) []string {
+		lines := strings.SplitAfter(content, "\n")
+		if lines[len(lines)-1] == "" {
+			lines = lines[:len(lines)-1]
+		}
+		lines = lines[max(len(lines)-n, 0):]
+		for i := range lines {
+			lines[i] = strings.TrimSuffix(lines[i], "\n")
+		}
+		return lines
+	}
+
+	// Files just either side of one and two chunks, with a delimiter landing
+	// on each side of a chunk boundary counted from the end, and a last line
+	// without a newline that is longer than a chunk on its own
+	short := strings.Repeat("s", 99) + "\n"
+	var contents []string
+	for _, size := range []int{chunkSize - 1, chunkSize, chunkSize + 1, 2*chunkSize - 1, 2 * chunkSize, 2*chunkSize + 1} {
+		for _, shift := range []int{-2, -1, 0, 1, 2} {
+			tail := strings.Repeat("t", 50) + "\n"
+			head := strings.Repeat(short, (size-len(tail))/len(short))
+			pad := size - len(head) - len(tail) + shift
+			if pad < 1 {
+				continue
+			}
+			// The padding line's newline moves across the boundary with shift
+			content := head + strings.Repeat("p", pad-1) + "\n" + tail
+			contents = append(contents, content, strings.TrimSuffix(content, "\n"))
+		}
+	}
+	long := strings.Repeat("x", chunkSize+100)
+	contents = append(contents,
+		short+short+long,
+		short+long,
+		long,
+		strings.Repeat(short, 700)+long,
+		strings.Repeat(short, 700)+long+"\n",
+	)
+
+	for i, content := range contents {
+		testFile := filepath.Join(dir, fmt.Sprintf("boundary%d.log", i))
+		if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+		for _, n := range []int{1, 2, 3, 10} {
+			for _, maxMemory := range []int64{0, 1} {
+				var got []string
+				tailer := NewTailer(TailerConfig{Path: testFile, Lines: n, MaxMemory: maxMemory})
+				err := tailer.TailFunc(context.Background(), func(line Line) error {
+					got = append(got, line.Text)
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("file %d (%d bytes), -n %d: TailFunc() error = %v", i, len(content), n, err)
+				}
+				if want := lastLines(content, n); !slices.Equal(got, want) {
+					t.Errorf("file %d (%d bytes), -n %d, MaxMemory %d: got %d lines, want %d", i, len(content), n, maxMemory, len(got), len(want))
+				}
+			}
+
+			// A reader returning less than asked for at a time reads the same
+			var buf bytes.Buffer
+			tailer := NewTailer(TailerConfig{Lines: n})
+			if err := tailer.TailReader(context.Background(), &shortReadSeeker{strings.NewReader(content)}, &buf); err != nil {
+				t.Fatalf("file %d, -n %d: TailReader() error = %v", i, n, err)
+			}
+			want := strings.Join(lastLines(content, n), "\n") + "\n"
+			if got := buf.String(); got != want {
+				t.Errorf("file %d (%d bytes), -n %d, short reads: got %d bytes, want %d", i, len(content), n, len(got), len(want))
+			}
+		}
+	}
+}
+
+// shortReadSeeker returns at most 1000 bytes from each Read, as a network
+// file might.
+type shortReadSeeker struct {
+	*strings.Reader
+}
+
+func (r *shortReadSeeker) Read(p []byte) (int, error) {
+	return r.Reader.Read(p[:min(len(p), 1000)])
+}
+
+func TestTailer_MaxMemory_StreamsLongLines(t *testing.T) {
+	dir := t.TempDir()
+
+	// Twenty 20KB lines, so the last few alone are over the bound, with and
+	// without a final newline
+	var content strings.Builder
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(&content, "line%02d %s\n", i, strings.Repeat("x", 20*1024))
+	}
+	full := content.String()
+
+	for _, tt := range []struct {
+		name    string
+		content string
+		lines   int
+	}{
+		{"last 5", full, 5},
+		{"last 5 without a final newline", strings.TrimSuffix(full, "\n"), 5},
+		{"more lines than the file has", full, 50},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(dir, "long.log")
+			if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+			tail := func(maxMemory int64) ([]Line, string) {
+				var debug bytes.Buffer
+				var lines []Line
+				tailer := NewTailer(TailerConfig{
+					Path:      testFile,
+					Lines:     tt.lines,
+					MaxMemory: maxMemory,
+					Debug:     true,
+					Stderr:    &debug,
+				})
+				err := tailer.TailFunc(context.Background(), func(line Line) error {
+					lines = append(lines, line)
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("TailFunc() error = %v", err)
+				}
+				return lines, debug.String()
+			}
+
+			want, _ := tail(0)
+			got, debug := tail(1024)
+			if !strings.Contains(debug, "streaming them") {
+				t.Errorf("debug output = %q, want the lines streamed", debug)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("got %d lines, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("line %d = %.20q at %d, want %.20q at %d", i, got[i].Text, got[i].Offset, want[i].Text, want[i].Offset)
+				}
+			}
+		})
+	}
+}
+
+func TestTailer_SparseFile_SkipsTrailingHole(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "prealloc.log")
+
+	// Simulate a pre-allocated log: a little data followed by a large hole
+	f, err := os.Create(testFile)
+	if err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(f, "line%02d\n", i)
+	}
+	if err := f.Truncate(4 * 1024 * 1024); err != nil {
+		t.Fatalf("failed to extend file: %v", err)
+	}
+	f.Close()
+
+	info, err := os.Stat(testFile)
+	if err != nil {
+		t.Fatalf("stat error = %v", err)
+	}
+	if !filesystem.IsSparse(info) {
+		t.Skip("filesystem does not create sparse files")
+	}
+
+	var buf bytes.Buffer
+	tailer := NewTailer(TailerConfig{
+		Path:  testFile,
+		Lines: 3,
+	})
+
+	if err := tailer.Tail(context.Background(), &buf); err != nil {
+		t.Fatalf("Tail() error = %v", err)
+	}
+
+	got := buf.String()
+	want := "line18\nline19\nline20\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTailer_SparseFile_FollowsPastHole(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"lines before the hole", "line1\nline2\nline3\n", "line1\nline2\nline3\nlive\n"},
+		{"nothing but hole", "", "live\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), "prealloc.log")
+			f, err := os.Create(testFile)
+			if err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+			f.WriteString(tt.content)
+			if err := f.Truncate(4 * 1024 * 1024); err != nil {
+				t.Fatalf("failed to extend file: %v", err)
+			}
+			f.Close()
+
+			info, err := os.Stat(testFile)
+			if err != nil {
+				t.Fatalf("stat error = %v", err)
+			}
+			if !filesystem.IsSparse(info) {
+				t.Skip("filesystem does not create sparse files")
+			}
+
+			var buf lockedBuffer
+			var errs lockedBuffer
+			tailer := NewTailer(TailerConfig{
+				Path:         testFile,
+				Lines:        3,
+				Follow:       true,
+				ForcePoll:    true,
+				PollInterval: 10 * time.Millisecond,
+				Stderr:       &errs,
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			done := make(chan error, 1)
+			go func() {
+				done <- tailer.Tail(ctx, &buf)
+			}()
+			time.Sleep(100 * time.Millisecond)
+
+			// Following picks up after the hole, not inside it
+			f, _ = os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+			f.WriteString("live\n")
+			f.Close()
+			time.Sleep(100 * time.Millisecond)
+
+			cancel()
+			if err := <-done; err != nil {
+				t.Fatalf("Tail() error: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got[:min(len(got), 100)], tt.want)
+			}
+			if got := errs.String(); got != "" {
+				t.Errorf("stderr = %q, want nothing", got)
+			}
+		})
+	}
+}
+
 func TestTailer_FollowWithTruncation(t *testing.T) {
 	dir := t.TempDir()
 	testFile := filepath.Join(dir, "truncate.log")
@@ -909,6 +3058,53 @@ func TestTailer_FromStart_Bytes(t *testing.T) {
 	}
 }
 
+func TestTailer_BytesThrough(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+	if err := os.WriteFile(testFile, []byte("0123456789"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		from, through int64
+		want          string
+	}{
+		{"inside the file", 3, 7, "23456"},
+		{"a single byte", 1, 1, "0"},
+		{"past the end", 8, 100, "789"},
+		{"starting past the end", 20, 30, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := TailerConfig{
+				Path:         testFile,
+				Bytes:        tt.from,
+				BytesSet:     true,
+				FromStart:    true,
+				BytesThrough: tt.through,
+			}
+
+			var buf bytes.Buffer
+			if err := NewTailer(config).Tail(context.Background(), &buf); err != nil {
+				t.Fatalf("Tail() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Tail() = %q, want %q", got, tt.want)
+			}
+
+			// Input that can't seek is cut short the same way
+			buf.Reset()
+			if err := NewTailer(config).TailReader(context.Background(), strings.NewReader("0123456789"), &buf); err != nil {
+				t.Fatalf("TailReader() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("TailReader() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 // TestTailer_FollowDescriptor_KeepsFollowingRenamedFile tests that -f (follow by descriptor)
 // continues reading from the same file handle even after the file is renamed.
 // This is the key behavioral difference from -F.
@@ -987,6 +3183,56 @@ func TestTailer_FollowDescriptor_KeepsFollowingRenamedFile(t *testing.T) {
 	}
 }
 
+// TestTailer_FollowDescriptor_ReopenOnMaxUnchanged tests that -f with
+// ReopenOnMaxUnchanged switches to a replacement file once the original goes quiet.
+func TestTailer_FollowDescriptor_ReopenOnMaxUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+	renamedFile := filepath.Join(dir, "test.log.1")
+
+	if err := os.WriteFile(testFile, []byte("[ORIGINAL] line1\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	tailer := NewTailer(TailerConfig{
+		Path:                 testFile,
+		Lines:                10,
+		Follow:               true,
+		MaxUnchangedStats:    3,
+		ReopenOnMaxUnchanged: true,
+		PollInterval:         10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tailer.Tail(ctx, &buf)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	// Rotate: rename and create a new file at the original path
+	if err := os.Rename(testFile, renamedFile); err != nil {
+		t.Fatalf("failed to rename file: %v", err)
+	}
+	if err := os.WriteFile(testFile, []byte("[NEW FILE] line1\n"), 0644); err != nil {
+		t.Fatalf("failed to create new file: %v", err)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	cancel()
+	<-done
+
+	got := buf.String()
+	want := "[ORIGINAL] line1\n[NEW FILE] line1\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
 // TestTailer_FollowName_SwitchesToNewFile tests that -F (follow by name)
 // switches to reading the new file when the original is renamed.
 func TestTailer_FollowName_SwitchesToNewFile(t *testing.T) {
@@ -1117,6 +3363,53 @@ func TestTailer_BytesMode_WithFollow(t *testing.T) {
 	}
 }
 
+// TestTailer_BytesMode_FollowBinary checks that bytes appended under -c -f
+// come out exactly as written, with CRLFs, NULs and unterminated data intact.
+func TestTailer_BytesMode_FollowBinary(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.bin")
+	if err := os.WriteFile(testFile, []byte("head\r\n\x00\x01"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	var buf lockedBuffer
+	tailer := NewTailer(TailerConfig{
+		Path:         testFile,
+		Bytes:        4,
+		Follow:       true,
+		ForcePoll:    true,
+		PollInterval: 10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- tailer.Tail(ctx, &buf)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	appended := []string{"a\r\nb\r\n", "\n\n\x00\xff\r", "tail"}
+	for _, data := range appended {
+		f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatalf("failed to open file: %v", err)
+		}
+		f.WriteString(data)
+		f.Close()
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Tail() error: %v", err)
+	}
+	want := "\r\n\x00\x01" + strings.Join(appended, "")
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
 // TestTailer_FromStart_Bytes_WithFollow tests -c +N with follow mode.
 func TestTailer_FromStart_Bytes_WithFollow(t *testing.T) {
 	dir := t.TempDir()
@@ -1237,7 +3530,10 @@ func TestTailer_FromStart_Lines_WithFollow(t *testing.T) {
 }
 
 // TestTailer_FollowDescriptor_NoRotationDetection verifies -f doesn't detect truncation.
-func TestTailer_FollowDescriptor_NoTruncationDetection(t *testing.T) {
+// TestTailer_FollowDescriptor_CopyTruncate tests that -f notices a file
+// truncated in place (copytruncate keeps the same inode) and reads the new
+// content from the start.
+func TestTailer_FollowDescriptor_CopyTruncate(t *testing.T) {
 	dir := t.TempDir()
 	testFile := filepath.Join(dir, "test.log")
 
@@ -1266,21 +3562,457 @@ func TestTailer_FollowDescriptor_NoTruncationDetection(t *testing.T) {
 	// Wait for initial read
 	time.Sleep(50 * time.Millisecond)
 
-	// Truncate the file (like copytruncate rotation)
-	if err := os.WriteFile(testFile, []byte("truncated\n"), 0644); err != nil {
+	// Truncate in place, keeping the inode the tailer has open
+	before, _ := os.Stat(testFile)
+	if err := os.Truncate(testFile, 0); err != nil {
 		t.Fatalf("failed to truncate file: %v", err)
 	}
+	time.Sleep(50 * time.Millisecond)
+	f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	f.WriteString("after\n")
+	f.Close()
+	after, _ := os.Stat(testFile)
+	if !os.SameFile(before, after) {
+		t.Fatal("truncation replaced the file; test needs an in-place truncate")
+	}
 
 	time.Sleep(100 * time.Millisecond)
 	cancel()
 	<-done
 
-	got := buf.String()
+	want := "line1\nline2\nline3\nline4\nline5\nafter\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
 
-	// With -f, truncation is NOT detected - it keeps reading from old position
-	// The truncated content may or may not appear depending on timing
-	if !strings.Contains(got, "line1") {
-		t.Errorf("expected initial content 'line1' in output, got %q", got)
+// growingFile wraps a real file and appends to it whenever it is stat'ed,
+// simulating a writer that races with the tailer's initial read.
+type growingFile struct {
+	filesystem.ReadSeekCloser
+	path string
+}
+
+func (f *growingFile) Stat() (os.FileInfo, error) {
+	info, err := f.ReadSeekCloser.Stat()
+	if w, openErr := os.OpenFile(f.path, os.O_APPEND|os.O_WRONLY, 0644); openErr == nil {
+		w.WriteString("grown\n")
+		w.Close()
+	}
+	return info, err
+}
+
+// growingOpener hands out growingFiles.
+type growingOpener struct{}
+
+func (o *growingOpener) Open(name string) (filesystem.ReadSeekCloser, error) {
+	f, err := filesystem.NewFileOpener().Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &growingFile{ReadSeekCloser: f, path: name}, nil
+}
+
+// TestTailer_BytesMode_GrowingFile tests that -c N outputs exactly the last N
+// bytes even when the file grows while the tailer is sizing it.
+func TestTailer_BytesMode_GrowingFile(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+
+	if err := os.WriteFile(testFile, []byte("0123456789\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	tl := NewTailer(TailerConfig{Path: testFile, Bytes: 4}).(*tailer)
+	tl.opener = &growingOpener{}
+
+	if err := tl.Tail(context.Background(), &buf); err != nil {
+		t.Fatalf("Tail() error = %v", err)
+	}
+
+	content, _ := os.ReadFile(testFile)
+	want := string(content[len(content)-4:])
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want the last 4 bytes %q", got, want)
+	}
+}
+
+// fsOpener opens files from an fs.FS, such as an embedded one.
+type fsOpener struct {
+	fsys fs.FS
+}
+
+func (o fsOpener) Open(name string) (filesystem.ReadSeekCloser, error) {
+	f, err := o.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	rsc, ok := f.(filesystem.ReadSeekCloser)
+	if !ok {
+		f.Close()
+		return nil, fmt.Errorf("%s: not seekable", name)
+	}
+	return rsc, nil
+}
+
+func TestNewTailerWithOpener(t *testing.T) {
+	opener := fsOpener{fstest.MapFS{
+		"logs/app.log": {Data: []byte("one\ntwo\nthree\nfour\n")},
+	}}
+
+	tests := []struct {
+		name    string
+		config  TailerConfig
+		want    string
+		wantErr bool
+	}{
+		{"last lines", TailerConfig{Path: "logs/app.log", Lines: 2}, "three\nfour\n", false},
+		{"from a line", TailerConfig{Path: "logs/app.log", Lines: 3, FromStart: true}, "three\nfour\n", false},
+		{"last bytes", TailerConfig{Path: "logs/app.log", Bytes: 5}, "four\n", false},
+		{"missing", TailerConfig{Path: "logs/gone.log", Lines: 2}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := NewTailerWithOpener(tt.config, opener).Tail(context.Background(), &buf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Tail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// failingOpener opens real files, except that the opens fail picks fail as
+// the Windows opener does when another process holds the file open without
+// sharing it, so share modes can be exercised on any platform.
+type failingOpener struct {
+	fail  func(open int) bool // Whether the open numbered open, from 1, fails
+	opens atomic.Int32
+}
+
+func (o *failingOpener) Open(name string) (filesystem.ReadSeekCloser, error) {
+	if o.fail(int(o.opens.Add(1))) {
+		return nil, fmt.Errorf("opening %s: %w", name, filesystem.ErrSharingViolation)
+	}
+	return filesystem.NewFileOpener().Open(name)
+}
+
+// waitForOpens waits until n opens have been attempted.
+func (o *failingOpener) waitForOpens(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for int(o.opens.Load()) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d opens attempted, want %d", o.opens.Load(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestTailer_Retry_FailedOpens(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "app.log")
+	os.WriteFile(testFile, []byte("one\ntwo\n"), 0644)
+
+	// Held open by another process for the first two attempts
+	opener := &failingOpener{fail: func(open int) bool { return open <= 2 }}
+	clk := newFakeClock()
+	tl := NewTailerWithOpener(TailerConfig{
+		Path:         testFile,
+		Lines:        10,
+		Retry:        true,
+		PollInterval: 10 * time.Millisecond,
+	}, opener).(*tailer)
+	tl.clock = clk
+
+	var buf lockedBuffer
+	done := make(chan error, 1)
+	go func() { done <- tl.Tail(context.Background(), &buf) }()
+
+	for open := 1; open <= 2; open++ {
+		opener.waitForOpens(t, open)
+		if got := buf.String(); got != "" {
+			t.Fatalf("output after %d failed opens = %q, want none", open, got)
+		}
+		clk.advance(10 * time.Millisecond)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Tail() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Tail() didn't return once the file could be opened")
+	}
+	if got, want := buf.String(), "one\ntwo\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	if got := opener.opens.Load(); got != 3 {
+		t.Errorf("opens = %d, want 3", got)
+	}
+}
+
+func TestTailer_Retry_FailedOpensExhaustAttempts(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "app.log")
+	os.WriteFile(testFile, []byte("one\n"), 0644)
+
+	opener := &failingOpener{fail: func(int) bool { return true }}
+	clk := newFakeClock()
+	tl := NewTailerWithOpener(TailerConfig{
+		Path:          testFile,
+		Retry:         true,
+		RetryAttempts: 2,
+		PollInterval:  10 * time.Millisecond,
+	}, opener).(*tailer)
+	tl.clock = clk
+
+	done := make(chan error, 1)
+	go func() { done <- tl.Tail(context.Background(), io.Discard) }()
+	opener.waitForOpens(t, 1)
+	clk.advance(10 * time.Millisecond)
+
+	// A file that is there but locked is reported as such, not as missing
+	err := <-done
+	if err == nil || !strings.Contains(err.Error(), "still not readable after 2 attempts") || !errors.Is(err, filesystem.ErrSharingViolation) {
+		t.Errorf("Tail() error = %v, want a failure after 2 attempts wrapping ErrSharingViolation", err)
+	}
+}
+
+func TestTailer_FollowName_FailedOpens(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "app.log")
+	os.WriteFile(testFile, []byte("first\n"), 0644)
+
+	// The initial read succeeds; the next two polls find the file held open
+	opener := &failingOpener{fail: func(open int) bool { return open == 2 || open == 3 }}
+	clk := newFakeClock()
+	tl := NewTailerWithOpener(TailerConfig{
+		Path:         testFile,
+		Lines:        10,
+		Follow:       true,
+		FollowName:   true,
+		ForcePoll:    true,
+		PollInterval: 10 * time.Millisecond,
+	}, opener).(*tailer)
+	tl.clock = clk
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lines := make(chan string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- tl.TailFunc(ctx, func(line Line) error {
+			lines <- line.Text
+			return nil
+		})
+	}()
+	next := func() string {
+		t.Helper()
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(5 * time.Second):
+			t.Fatal("no line output")
+			return ""
+		}
+	}
+
+	if got := next(); got != "first" {
+		t.Fatalf("first line = %q, want %q", got, "first")
+	}
+	clk.waitForWaiters(t, 1)
+
+	f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	f.WriteString("second\n")
+	f.Close()
+
+	// Failed opens skip a poll each, without losing the line or the place
+	for open := 2; open <= 3; open++ {
+		clk.advance(10 * time.Millisecond)
+		opener.waitForOpens(t, open)
+		select {
+		case line := <-lines:
+			t.Fatalf("line %q output after %d failed opens", line, open-1)
+		default:
+		}
+	}
+	clk.advance(10 * time.Millisecond)
+	if got := next(); got != "second" {
+		t.Fatalf("line = %q, want %q", got, "second")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("TailFunc() error = %v", err)
+	}
+}
+
+// unseekableFile is a file whose every Seek fails, as on a pipe.
+type unseekableFile struct {
+	filesystem.ReadSeekCloser
+}
+
+func (unseekableFile) Seek(int64, int) (int64, error) {
+	return 0, errors.New("illegal seek")
+}
+
+// unseekableOpener opens files through FileOpener that can't seek.
+type unseekableOpener struct {
+	filesystem.FileOpener
+}
+
+func (o unseekableOpener) Open(name string) (filesystem.ReadSeekCloser, error) {
+	f, err := o.FileOpener.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return unseekableFile{f}, nil
+}
+
+func TestTailer_UnseekableOpen(t *testing.T) {
+	opener := unseekableOpener{fsOpener{fstest.MapFS{
+		"fifo":    {Data: []byte("one\ntwo\nthree\nfour\n"), Mode: fs.ModeNamedPipe},
+		"app.log": {Data: []byte("one\ntwo\nthree\nfour\n")},
+	}}}
+
+	tests := []struct {
+		name    string
+		config  TailerConfig
+		want    string
+		wantErr string
+	}{
+		// A pipe is read forward, the last lines kept in a ring
+		{"pipe, last lines", TailerConfig{Path: "fifo", Lines: 2}, "three\nfour\n", ""},
+		{"pipe, last bytes", TailerConfig{Path: "fifo", Bytes: 5}, "four\n", ""},
+		{"pipe, from a line", TailerConfig{Path: "fifo", Lines: 3, FromStart: true}, "three\nfour\n", ""},
+		{"pipe, all but the last", TailerConfig{Path: "fifo", Lines: 3, AllButLast: true}, "one\n", ""},
+		{"pipe, followed", TailerConfig{Path: "fifo", Lines: 2, Follow: true}, "three\nfour\n", ""},
+		// A regular file is expected to seek, and says so when it can't
+		{"regular file", TailerConfig{Path: "app.log", Lines: 2}, "", "seeking"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := NewTailerWithOpener(tt.config, opener).Tail(context.Background(), &buf)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Tail() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Tail() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// flakyFile wraps a real file and fails Stat/Seek once broken is set,
+// simulating a handle that went stale (e.g. after a network share hiccup).
+type flakyFile struct {
+	filesystem.ReadSeekCloser
+	broken *atomic.Bool
+}
+
+func (f *flakyFile) Stat() (os.FileInfo, error) {
+	if f.broken.Load() {
+		return nil, fmt.Errorf("stale handle")
+	}
+	return f.ReadSeekCloser.Stat()
+}
+
+func (f *flakyFile) Seek(offset int64, whence int) (int64, error) {
+	if f.broken.Load() {
+		return 0, fmt.Errorf("stale handle")
+	}
+	return f.ReadSeekCloser.Seek(offset, whence)
+}
+
+// flakyOpener hands out a flakyFile on the first open and real files afterwards.
+type flakyOpener struct {
+	broken *atomic.Bool
+	opens  atomic.Int32
+}
+
+func (o *flakyOpener) Open(name string) (filesystem.ReadSeekCloser, error) {
+	f, err := filesystem.NewFileOpener().Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if o.opens.Add(1) == 1 {
+		return &flakyFile{ReadSeekCloser: f, broken: o.broken}, nil
+	}
+	return f, nil
+}
+
+// TestTailer_FollowDescriptor_ReopensAfterErrors tests that -f recovers by
+// reopening the path once reads have failed ReopenAfterErrors times in a row.
+func TestTailer_FollowDescriptor_ReopensAfterErrors(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+
+	if err := os.WriteFile(testFile, []byte("line1\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	var buf, errBuf bytes.Buffer
+	var broken atomic.Bool
+	opener := &flakyOpener{broken: &broken}
+
+	tl := NewTailer(TailerConfig{
+		Path:              testFile,
+		Lines:             10,
+		Follow:            true,
+		PollInterval:      10 * time.Millisecond,
+		ReopenAfterErrors: 3,
+		Stderr:            &errBuf,
+	}).(*tailer)
+	tl.opener = opener
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tl.Tail(ctx, &buf)
+	}()
+
+	// Wait for initial read, then break the handle and append
+	time.Sleep(50 * time.Millisecond)
+	broken.Store(true)
+
+	f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	f.WriteString("line2\n")
+	f.Close()
+
+	time.Sleep(150 * time.Millisecond)
+	cancel()
+	<-done
+
+	if got := buf.String(); got != "line1\nline2\n" {
+		t.Errorf("got %q, want %q", got, "line1\nline2\n")
+	}
+	if n := strings.Count(errBuf.String(), "reopened after 3 consecutive read errors"); n != 1 {
+		t.Errorf("expected exactly one reopen diagnostic, got %q", errBuf.String())
 	}
 }
 
@@ -1566,6 +4298,30 @@ func TestTailer_TailReaderBytesFromStart(t *testing.T) {
 }
 
 // TestTailer_TailReaderBytesLargerThanInput verifies TailReader handles -c N > input size.
+func TestTailer_ZeroBytes(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+	os.WriteFile(testFile, []byte("line1\nline2\n"), 0644)
+
+	// An explicit 0 is byte mode with nothing to output, not line mode
+	var buf bytes.Buffer
+	tailer := NewTailer(TailerConfig{Path: testFile, BytesSet: true})
+	if err := tailer.Tail(context.Background(), &buf); err != nil {
+		t.Fatalf("Tail() error = %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Tail() output = %q, want none", buf.String())
+	}
+
+	buf.Reset()
+	if err := tailer.TailReader(context.Background(), strings.NewReader("line1\nline2\n"), &buf); err != nil {
+		t.Fatalf("TailReader() error = %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("TailReader() output = %q, want none", buf.String())
+	}
+}
+
 func TestTailer_TailReaderBytesLargerThanInput(t *testing.T) {
 	input := strings.NewReader("short")
 	var buf bytes.Buffer
@@ -1587,6 +4343,141 @@ func TestTailer_TailReaderBytesLargerThanInput(t *testing.T) {
 }
 
 // TestTailer_ProcessExists tests the processExists function.
+// byteAtATimeReader returns at most one byte per Read, like a slow pipe.
+type byteAtATimeReader struct {
+	r io.Reader
+}
+
+func (b *byteAtATimeReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return b.r.Read(p[:1])
+}
+
+func TestTailer_TailReaderBytesRing(t *testing.T) {
+	large := strings.Repeat("abcdefghij", 20000) // 200KB, several chunks
+
+	tests := []struct {
+		name  string
+		input io.Reader
+		n     int64
+		want  string
+	}{
+		{"n=3 of 10 bytes", strings.NewReader("0123456789"), 3, "789"},
+		{"n larger than input", strings.NewReader("0123456789"), 50, "0123456789"},
+		{"1-byte reads", &byteAtATimeReader{strings.NewReader("0123456789")}, 4, "6789"},
+		{"n equals input", strings.NewReader("0123456789"), 10, "0123456789"},
+		{"n not dividing chunk size", strings.NewReader(large), 7, large[len(large)-7:]},
+		{"n larger than a chunk", strings.NewReader(large), 70000, large[len(large)-70000:]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tailer := NewTailer(TailerConfig{Bytes: tt.n})
+			if err := tailer.TailReader(context.Background(), tt.input, &buf); err != nil {
+				t.Fatalf("TailReader() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %d bytes %.40q, want %d bytes %.40q", len(got), got, len(tt.want), tt.want)
+			}
+		})
+	}
+}
+
+// TestTailer_HugeLineCount tests that a line count far beyond the input
+// costs only what the input holds, not a ring of that many lines.
+func TestTailer_HugeLineCount(t *testing.T) {
+	const input = "1\n2\n3\n4\n5\n"
+
+	tests := []struct {
+		name   string
+		config TailerConfig
+		want   string
+	}{
+		{"-n", TailerConfig{Lines: 1_000_000_000}, input},
+		{"--head-tail", TailerConfig{HeadTail: 1_000_000_000}, input},
+		{"-n wrapping", TailerConfig{Lines: 2}, "4\n5\n"},
+		{"--head-tail wrapping", TailerConfig{HeadTail: 2}, "1\n2\n... (1 line omitted) ...\n4\n5\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			var buf bytes.Buffer
+			if err := NewTailer(tt.config).TailReader(context.Background(), strings.NewReader(input), &buf); err != nil {
+				t.Fatalf("TailReader() error = %v", err)
+			}
+			runtime.ReadMemStats(&after)
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+			if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 16<<20 {
+				t.Errorf("allocated %d bytes for a 5-line input", alloc)
+			}
+		})
+	}
+}
+
+// TestTailer_FromStart_Streams tests that +N hands lines over as they are
+// read rather than collecting the rest of the input first.
+func TestTailer_FromStart_Streams(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	go func() {
+		// Write a few lines, then keep the stream open as a live pipe would
+		for i := 1; i <= 5; i++ {
+			fmt.Fprintf(pw, "line%d\n", i)
+		}
+	}()
+
+	tl := NewTailer(TailerConfig{Lines: 2, FromStart: true}).(*tailer)
+
+	stop := errors.New("stop")
+	var got []string
+	done := make(chan error, 1)
+	go func() {
+		done <- tl.readStream(pr, func(line Line) error {
+			got = append(got, line.Text)
+			if len(got) == 3 {
+				return stop
+			}
+			return nil
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, stop) {
+			t.Fatalf("expected callback error, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("lines were not emitted until the end of the input")
+	}
+	if want := []string{"line2", "line3", "line4"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTailer_FromStart_LargeInput(t *testing.T) {
+	var input strings.Builder
+	for i := 1; i <= 100000; i++ {
+		fmt.Fprintf(&input, "line%d\n", i)
+	}
+
+	var buf bytes.Buffer
+	tailer := NewTailer(TailerConfig{Lines: 99999, FromStart: true})
+	if err := tailer.TailReader(context.Background(), strings.NewReader(input.String()), &buf); err != nil {
+		t.Fatalf("TailReader() error = %v", err)
+	}
+	if got, want := buf.String(), "line99999\nline100000\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
 func TestTailer_ProcessExists(t *testing.T) {
 	// Test with current process (should exist)
 	if !processExists(os.Getpid()) {
@@ -1599,3 +4490,33 @@ func TestTailer_ProcessExists(t *testing.T) {
 	}
 }
 
+
+// TestProcessWaiter tests that the waiter channel closes when a process exits
+// and stays open while it is running.
+func TestProcessWaiter(t *testing.T) {
+	waiter := NewProcessWaiter(10 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Our own process keeps running
+	select {
+	case <-waiter.Wait(ctx, os.Getpid()):
+		t.Fatal("Wait channel closed for a running process")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Re-run the test binary with no tests selected so it exits right away
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start child process: %v", err)
+	}
+	exited := waiter.Wait(ctx, cmd.Process.Pid)
+	go cmd.Wait() // Reap the child so it doesn't linger as a zombie
+
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Wait channel not closed after child process exited")
+	}
+}
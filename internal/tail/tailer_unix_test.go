@@ -0,0 +1,245 @@
+//go:build !windows
+
+package tail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fifoSource makes a named FIFO and writes content into it once the tailer
+// opens it for reading.
+func fifoSource(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "fifo")
+	if err := syscall.Mkfifo(path, 0644); err != nil {
+		t.Skipf("mkfifo: %v", err)
+	}
+	go func() {
+		w, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return
+		}
+		w.WriteString(content)
+		w.Close()
+	}()
+	return path
+}
+
+// devFDSource makes an anonymous pipe holding content and returns its
+// /dev/fd path, the way bash's <(...) process substitution passes one.
+func devFDSource(t *testing.T, content string) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	t.Cleanup(func() { r.Close() })
+	path := fmt.Sprintf("/dev/fd/%d", r.Fd())
+	if _, err := os.Stat(path); err != nil {
+		w.Close()
+		t.Skipf("no /dev/fd: %v", err)
+	}
+	go func() {
+		w.WriteString(content)
+		w.Close()
+	}()
+	return path
+}
+
+func TestTailer_PipePaths(t *testing.T) {
+	tests := []struct {
+		name   string
+		source func(*testing.T, string) string
+		follow bool
+	}{
+		{"fifo", fifoSource, false},
+		{"fifo with follow", fifoSource, true},
+		{"dev fd", devFDSource, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tt.source(t, "line1\nline2\nline3\n")
+
+			// Pipes are read to the end rather than seeked, and not followed
+			var buf bytes.Buffer
+			tailer := NewTailer(TailerConfig{Path: path, Lines: 2, Follow: tt.follow})
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			if err := tailer.Tail(ctx, &buf); err != nil {
+				t.Fatalf("Tail() error: %v", err)
+			}
+			if got, want := buf.String(), "line2\nline3\n"; got != want {
+				t.Errorf("output = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestTailer_WarnRotation(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "app.log")
+	os.WriteFile(testFile, []byte("initial\n"), 0644)
+
+	var stderr lockedBuffer
+	clk := newFakeClock()
+	tailer := NewTailer(TailerConfig{
+		Path:         testFile,
+		Lines:        10,
+		Follow:       true,
+		ForcePoll:    true,
+		PollInterval: 10 * time.Millisecond,
+		WarnRotation: true,
+		Stderr:       &stderr,
+	}).(*tailer)
+	tailer.clock = clk
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lines := make(chan string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- tailer.TailFunc(ctx, func(line Line) error {
+			lines <- line.Text
+			return nil
+		})
+	}()
+	next := func() string {
+		t.Helper()
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(5 * time.Second):
+			t.Fatal("no line output")
+			return ""
+		}
+	}
+
+	if got := next(); got != "initial" {
+		t.Fatalf("first line = %q, want %q", got, "initial")
+	}
+	clk.waitForWaiters(t, 1)
+
+	// Renamed away, the file is still followed, and the warning says so
+	rotated := filepath.Join(dir, "app.log.1")
+	if err := os.Rename(testFile, rotated); err != nil {
+		t.Fatalf("rotating: %v", err)
+	}
+	f, err := os.OpenFile(rotated, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	defer f.Close()
+	f.WriteString("after rename\n")
+	clk.advance(10 * time.Millisecond)
+	if got := next(); got != "after rename" {
+		t.Fatalf("line = %q, want %q", got, "after rename")
+	}
+	if got := stderr.String(); !strings.Contains(got, "renamed or removed; still following the open file") {
+		t.Errorf("stderr = %q, want a rotation warning", got)
+	}
+
+	// A new file at the name isn't read, and the rotation isn't reported twice
+	os.WriteFile(testFile, []byte("new file\n"), 0644)
+	f.WriteString("still old\n")
+	clk.waitForWaiters(t, 1)
+	clk.advance(10 * time.Millisecond)
+	if got := next(); got != "still old" {
+		t.Fatalf("line = %q, want %q", got, "still old")
+	}
+	if got := strings.Count(stderr.String(), "wail: "); got != 1 {
+		t.Errorf("stderr = %q, want one warning", stderr.String())
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("TailFunc() error = %v", err)
+	}
+}
+
+func TestTailer_DirectorySymlinkSwapped(t *testing.T) {
+	tests := []struct {
+		name   string
+		config TailerConfig
+	}{
+		{"-F", TailerConfig{FollowName: true}},
+		{"-f --follow-symlink-target", TailerConfig{FollowSymlinkTarget: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// As under /var/log/pods, the file is reached through a directory
+			// symlink that is swapped to a new directory on a restart
+			dir := t.TempDir()
+			for _, d := range []string{"v1", "v2"} {
+				os.Mkdir(filepath.Join(dir, d), 0755)
+			}
+			os.WriteFile(filepath.Join(dir, "v1", "0.log"), []byte("old\n"), 0644)
+			os.WriteFile(filepath.Join(dir, "v2", "0.log"), []byte("new\n"), 0644)
+			current := filepath.Join(dir, "current")
+			if err := os.Symlink("v1", current); err != nil {
+				t.Fatalf("symlink: %v", err)
+			}
+
+			config := tt.config
+			config.Path = filepath.Join(current, "0.log")
+			config.Lines = 10
+			config.Follow = true
+			config.ForcePoll = true
+			config.PollInterval = 10 * time.Millisecond
+			clk := newFakeClock()
+			tailer := NewTailer(config).(*tailer)
+			tailer.clock = clk
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			lines := make(chan string, 10)
+			done := make(chan error, 1)
+			go func() {
+				done <- tailer.TailFunc(ctx, func(line Line) error {
+					lines <- line.Text
+					return nil
+				})
+			}()
+			next := func() string {
+				t.Helper()
+				select {
+				case line := <-lines:
+					return line
+				case <-time.After(5 * time.Second):
+					t.Fatal("no line output")
+					return ""
+				}
+			}
+
+			if got := next(); got != "old" {
+				t.Fatalf("first line = %q, want %q", got, "old")
+			}
+			clk.waitForWaiters(t, 1)
+
+			// Swapped atomically, by renaming a new link over the old one
+			tmp := filepath.Join(dir, "current.tmp")
+			if err := os.Symlink("v2", tmp); err != nil {
+				t.Fatalf("symlink: %v", err)
+			}
+			if err := os.Rename(tmp, current); err != nil {
+				t.Fatalf("swapping symlink: %v", err)
+			}
+			clk.advance(10 * time.Millisecond)
+			if got := next(); got != "new" {
+				t.Fatalf("line after swap = %q, want %q", got, "new")
+			}
+
+			cancel()
+			if err := <-done; err != nil {
+				t.Errorf("TailFunc() error = %v", err)
+			}
+		})
+	}
+}
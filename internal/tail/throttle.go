@@ -0,0 +1,94 @@
+package tail
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// dropNoticeInterval is how often drop mode reports discarded lines.
+const dropNoticeInterval = time.Second
+
+// throttle limits the rate of follow output with a token bucket holding up
+// to one second's worth of tokens. Each line costs one token, or its length
+// in bytes (delimiter included) when limiting bytes.
+type throttle struct {
+	rate   float64 // tokens added per second
+	bytes  bool    // cost lines by size rather than count
+	drop   bool    // discard lines over the limit instead of waiting
+	tokens float64
+	last   time.Time
+
+	dropped    int64
+	lastNotice time.Time
+
+	clock clock
+}
+
+// newThrottle returns a throttle allowing rate lines (or bytes) per second,
+// timed by clk.
+func newThrottle(clk clock, rate float64, bytes, drop bool) *throttle {
+	th := &throttle{rate: rate, bytes: bytes, drop: drop, clock: clk}
+	th.tokens = th.burst()
+	th.last = clk.Now()
+	th.lastNotice = th.last
+	return th
+}
+
+// burst is the bucket's capacity.
+func (th *throttle) burst() float64 {
+	return max(th.rate, 1)
+}
+
+// refill adds the tokens accrued since the last call.
+func (th *throttle) refill() {
+	now := th.clock.Now()
+	th.tokens = min(th.burst(), th.tokens+now.Sub(th.last).Seconds()*th.rate)
+	th.last = now
+}
+
+// cost returns the tokens line needs, capped at the bucket's capacity so an
+// oversized line can still pass eventually.
+func (th *throttle) cost(line Line) float64 {
+	if !th.bytes {
+		return 1
+	}
+	return min(float64(len(line.Text)+1), th.burst())
+}
+
+// wrap returns a LineFunc that passes lines to emit no faster than the rate.
+// In delay mode it waits for tokens, returning early (and skipping the line)
+// if ctx is cancelled. In drop mode it discards lines over the limit and,
+// at most once per dropNoticeInterval, emits a notice saying how many.
+func (th *throttle) wrap(ctx context.Context, emit LineFunc, file string) LineFunc {
+	return func(line Line) error {
+		th.refill()
+		cost := th.cost(line)
+
+		if th.tokens < cost {
+			if th.drop {
+				th.dropped++
+				return nil
+			}
+			wait := time.Duration((cost - th.tokens) / th.rate * float64(time.Second))
+			select {
+			case <-ctx.Done():
+				return nil // The follow loop notices the cancellation
+			case <-th.clock.After(wait):
+			}
+			th.refill()
+		}
+
+		if th.dropped > 0 && th.clock.Now().Sub(th.lastNotice) >= dropNoticeInterval {
+			notice := Line{Text: fmt.Sprintf("[wail: dropped %d lines]", th.dropped), Offset: line.Offset, File: file}
+			th.dropped = 0
+			th.lastNotice = th.clock.Now()
+			if err := emit(notice); err != nil {
+				return err
+			}
+		}
+
+		th.tokens -= cost
+		return emit(line)
+	}
+}
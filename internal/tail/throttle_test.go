@@ -0,0 +1,119 @@
+package tail
+
+import (
+	"context"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestThrottle(rate float64, bytes, drop bool) (*throttle, *fakeClock) {
+	clock := newFakeClock()
+	return newThrottle(clock, rate, bytes, drop), clock
+}
+
+func TestThrottle_DropMode(t *testing.T) {
+	th, clock := newTestThrottle(2, false, true)
+
+	var got []string
+	emit := th.wrap(context.Background(), func(line Line) error {
+		got = append(got, line.Text)
+		return nil
+	}, "test.log")
+
+	// A burst of 2 passes, the rest of the second is dropped
+	for _, text := range []string{"a", "b", "c", "d"} {
+		emit(Line{Text: text})
+	}
+	// After a second the bucket has refilled and the drop is reported
+	clock.advance(time.Second)
+	emit(Line{Text: "e"})
+
+	want := []string{"a", "b", "[wail: dropped 2 lines]", "e"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestThrottle_BytesMode(t *testing.T) {
+	th, clock := newTestThrottle(10, true, true)
+
+	var got []string
+	emit := th.wrap(context.Background(), func(line Line) error {
+		got = append(got, line.Text)
+		return nil
+	}, "test.log")
+
+	emit(Line{Text: "1234"}) // 5 bytes with the delimiter
+	emit(Line{Text: "5678"}) // 5 more: bucket empty
+	emit(Line{Text: "dropped"})
+	clock.advance(500 * time.Millisecond) // 5 bytes back, not enough for the notice interval
+	emit(Line{Text: "abcd"})
+
+	want := []string{"1234", "5678", "abcd"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestThrottle_DelayModeCancel(t *testing.T) {
+	th := newThrottle(realClock{}, 0.1, false, false) // one line every 10 seconds
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var got []string
+	emit := th.wrap(ctx, func(line Line) error {
+		got = append(got, line.Text)
+		return nil
+	}, "test.log")
+
+	emit(Line{Text: "first"}) // uses the initial token
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	if err := emit(Line{Text: "second"}); err != nil {
+		t.Fatalf("emit error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cancel took %v to interrupt the throttle", elapsed)
+	}
+	if !slices.Equal(got, []string{"first"}) {
+		t.Errorf("got %q, want only the first line", got)
+	}
+}
+
+func TestThrottle_DelayMode(t *testing.T) {
+	th, clock := newTestThrottle(20, false, false)
+
+	var count atomic.Int32
+	emit := th.wrap(context.Background(), func(line Line) error {
+		count.Add(1)
+		return nil
+	}, "test.log")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 25 {
+			emit(Line{Text: "x"})
+		}
+	}()
+
+	// 20 lines pass from the full bucket; each of the other 5 waits 50ms
+	for range 5 {
+		clock.waitForWaiters(t, 1)
+		if got := count.Load(); got < 20 {
+			t.Fatalf("%d lines passed before the throttle waited, want 20", got)
+		}
+		clock.advance(50 * time.Millisecond)
+	}
+	<-done
+
+	if got := count.Load(); got != 25 {
+		t.Errorf("delay mode lost lines: got %d, want 25", got)
+	}
+}
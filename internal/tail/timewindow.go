@@ -0,0 +1,103 @@
+package tail
+
+import (
+	"errors"
+	"time"
+)
+
+// errPastUntil ends tailing when a live line is stamped after Until: nothing
+// later in the file can fall in the window. TailFunc and TailReader return nil
+// in its place.
+var errPastUntil = errors.New("past the end of the time window")
+
+// maxTimestampLen bounds how far into a line a leading timestamp is sought.
+const maxTimestampLen = 64
+
+// timeFilter passes on lines whose leading timestamp falls between since and
+// until, either of which may be zero to leave that end open. Lines without a
+// timestamp are passed on, or dropped with dropUnparseable.
+type timeFilter struct {
+	emit            LineFunc
+	layout          string
+	since           time.Time
+	until           time.Time
+	dropUnparseable bool
+	now             func() time.Time
+}
+
+// line is the timeFilter's LineFunc.
+func (f *timeFilter) line(line Line) error {
+	if line.marker || line.chunk {
+		return f.emit(line)
+	}
+	stamp, ok := LineTime(line.Text, f.layout, f.now())
+	if !ok {
+		if f.dropUnparseable {
+			return nil
+		}
+		return f.emit(line)
+	}
+	if !f.since.IsZero() && stamp.Before(f.since) {
+		return nil
+	}
+	if !f.until.IsZero() && stamp.After(f.until) {
+		if !line.IsInitial {
+			return errPastUntil
+		}
+		return nil
+	}
+	return f.emit(line)
+}
+
+// LineTime parses the timestamp at the start of text with layout. The
+// timestamp is taken to end at a space or tab, or the end of the line, and
+// each such prefix is tried in turn, so layouts containing spaces work
+// without knowing how long their values are. A timestamp without a zone is
+// local time, and one without a year (as syslog writes them) is in now's.
+func LineTime(text, layout string, now time.Time) (time.Time, bool) {
+	end := min(len(text), maxTimestampLen)
+	for i := 1; i <= end; i++ {
+		if i < len(text) && text[i] != ' ' && text[i] != '\t' {
+			continue
+		}
+		stamp, err := time.ParseInLocation(layout, text[:i], time.Local)
+		if err != nil {
+			continue
+		}
+		if stamp.Year() == 0 {
+			stamp = stamp.AddDate(now.Year(), 0, 0)
+		}
+		return stamp, true
+	}
+	return time.Time{}, false
+}
+
+// windowed wraps emit to pass only lines inside the Since and Until window,
+// or returns it unchanged when neither is set.
+func (t *tailer) windowed(emit LineFunc) LineFunc {
+	if t.config.Since.IsZero() && t.config.Until.IsZero() {
+		return emit
+	}
+	layout := t.config.TimeLayout
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	f := &timeFilter{
+		emit:            emit,
+		layout:          layout,
+		since:           t.config.Since,
+		until:           t.config.Until,
+		dropUnparseable: t.config.DropUnparseable,
+		now:             t.clock.Now,
+	}
+	return f.line
+}
+
+// stopped returns nil for the error that ends tailing at the close of the
+// time window, and any other error unchanged.
+func stopped(err error) error {
+	if errors.Is(err, errPastUntil) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+package tail
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTailer_TimeWindow(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "app.log")
+	os.WriteFile(testFile, []byte(
+		"2024-05-01T09:59:59Z starting\n"+
+			"2024-05-01T10:00:00Z ready\n"+
+			"2024-05-01T10:30:00.250+00:00 request failed\n"+
+			"\tat handler.go:42\n"+
+			"2024-05-01T11:00:01Z stopping\n"), 0644)
+
+	at := func(s string) time.Time {
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+
+	tests := []struct {
+		name  string
+		since time.Time
+		until time.Time
+		drop  bool
+		want  string
+	}{
+		{"since", at("2024-05-01T10:00:00Z"), time.Time{}, false,
+			"2024-05-01T10:00:00Z ready\n2024-05-01T10:30:00.250+00:00 request failed\n\tat handler.go:42\n2024-05-01T11:00:01Z stopping\n"},
+		{"since in another zone", at("2024-05-01T12:30:00+02:00"), time.Time{}, false,
+			"2024-05-01T10:30:00.250+00:00 request failed\n\tat handler.go:42\n2024-05-01T11:00:01Z stopping\n"},
+		{"until", time.Time{}, at("2024-05-01T10:00:00Z"), false,
+			"2024-05-01T09:59:59Z starting\n2024-05-01T10:00:00Z ready\n\tat handler.go:42\n"},
+		{"window dropping unparseable lines", at("2024-05-01T10:00:00Z"), at("2024-05-01T11:00:00Z"), true,
+			"2024-05-01T10:00:00Z ready\n2024-05-01T10:30:00.250+00:00 request failed\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			tailer := NewTailer(TailerConfig{
+				Path:            testFile,
+				Lines:           10,
+				Since:           tt.since,
+				Until:           tt.until,
+				DropUnparseable: tt.drop,
+			})
+			if err := tailer.Tail(context.Background(), &out); err != nil {
+				t.Fatalf("Tail() error: %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineTime(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		name   string
+		text   string
+		layout string
+		want   time.Time
+		ok     bool
+	}{
+		{"rfc3339", "2024-05-01T10:00:00Z ready", time.RFC3339, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), true},
+		{"whole line", "2024-05-01T10:00:00Z", time.RFC3339, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), true},
+		{"layout with a space", "2024-05-01 10:00:00 ready", "2006-01-02 15:04:05", time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local), true},
+		{"syslog takes this year", "May  1 10:00:00 host sshd[1]: ok", time.Stamp, time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local), true},
+		{"no timestamp", "ready at 2024-05-01T10:00:00Z", time.RFC3339, time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LineTime(tt.text, tt.layout, now)
+			if ok != tt.ok || !got.Equal(tt.want) {
+				t.Errorf("LineTime() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestTailer_FollowStopsPastUntil(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "app.log")
+	os.WriteFile(testFile, []byte("2024-05-01T10:00:00Z ready\n2024-05-01T12:00:00Z later\n"), 0644)
+
+	clk := newFakeClock()
+	tailer := NewTailer(TailerConfig{
+		Path:         testFile,
+		Lines:        10,
+		Follow:       true,
+		ForcePoll:    true,
+		PollInterval: 10 * time.Millisecond,
+		Until:        time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC),
+	}).(*tailer)
+	tailer.clock = clk
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var buf lockedBuffer
+	done := make(chan error, 1)
+	go func() {
+		done <- tailer.Tail(ctx, &buf)
+	}()
+
+	// A line already past the window is left out without stopping, since
+	// the file isn't promised to be in order
+	clk.waitForWaiters(t, 1)
+	f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	f.WriteString("2024-05-01T10:59:00Z in time\n2024-05-01T11:00:01Z too late\n")
+	f.Close()
+	clk.advance(10 * time.Millisecond)
+
+	if err := <-done; err != nil {
+		t.Fatalf("Tail() error: %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("Tail() returned only when the context ended")
+	}
+	want := "2024-05-01T10:00:00Z ready\n2024-05-01T10:59:00Z in time\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
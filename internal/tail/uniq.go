@@ -0,0 +1,80 @@
+package tail
+
+import (
+	"fmt"
+	"time"
+)
+
+// uniqNoticeInterval is how often an ongoing run of repeats is reported.
+const uniqNoticeInterval = 5 * time.Second
+
+// uniqFilter suppresses consecutive duplicate lines, like uniq. With count
+// set it reports each suppressed run as "[last line repeated N times]" when
+// the run ends, and every uniqNoticeInterval while it continues.
+type uniqFilter struct {
+	emit    LineFunc
+	file    string
+	count   bool
+	prev    string
+	have    bool
+	repeats int
+	since   time.Time // when the current unreported run of repeats began
+
+	clock clock
+}
+
+// newUniqFilter returns a uniqFilter passing distinct lines to emit, timing
+// runs of repeats by clk.
+func newUniqFilter(clk clock, emit LineFunc, file string, count bool) *uniqFilter {
+	return &uniqFilter{emit: emit, file: file, count: count, clock: clk}
+}
+
+// line is the uniqFilter's LineFunc.
+func (u *uniqFilter) line(line Line) error {
+	if line.chunk {
+		// Byte-mode chunks aren't lines; pass them through and start afresh
+		if err := u.reset(); err != nil {
+			return err
+		}
+		return u.emit(line)
+	}
+
+	if u.have && line.Text == u.prev {
+		if u.repeats == 0 {
+			u.since = u.clock.Now()
+		}
+		u.repeats++
+		if u.count && u.clock.Now().Sub(u.since) >= uniqNoticeInterval {
+			return u.flush()
+		}
+		return nil
+	}
+
+	if err := u.flush(); err != nil {
+		return err
+	}
+	u.prev = line.Text
+	u.have = true
+	return u.emit(line)
+}
+
+// flush reports the current run of repeats, if any and if counting.
+func (u *uniqFilter) flush() error {
+	if u.repeats == 0 {
+		return nil
+	}
+	n := u.repeats
+	u.repeats = 0
+	if !u.count {
+		return nil
+	}
+	return u.emit(Line{Text: fmt.Sprintf("[last line repeated %d times]", n), File: u.file})
+}
+
+// reset flushes and forgets the previous line, so that the next line is
+// always output (e.g. after the file was rotated).
+func (u *uniqFilter) reset() error {
+	err := u.flush()
+	u.have = false
+	return err
+}
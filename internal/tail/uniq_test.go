@@ -0,0 +1,152 @@
+package tail
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUniqFilter(t *testing.T) {
+	tests := []struct {
+		name  string
+		count bool
+		input []string
+		want  []string
+	}{
+		{"suppresses repeats", false, []string{"a", "a", "b", "b", "b", "a"}, []string{"a", "b", "a"}},
+		{"counts repeats", true, []string{"a", "a", "a", "b"}, []string{"a", "[last line repeated 2 times]", "b"}},
+		{"no repeats", true, []string{"a", "b", "c"}, []string{"a", "b", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			u := newUniqFilter(realClock{}, func(line Line) error {
+				got = append(got, line.Text)
+				return nil
+			}, "test.log", tt.count)
+
+			for _, text := range tt.input {
+				u.line(Line{Text: text})
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUniqFilter_PeriodicNotice(t *testing.T) {
+	clock := newFakeClock()
+	var got []string
+	u := newUniqFilter(clock, func(line Line) error {
+		got = append(got, line.Text)
+		return nil
+	}, "test.log", true)
+
+	u.line(Line{Text: "beat"})
+	u.line(Line{Text: "beat"})
+	clock.advance(uniqNoticeInterval)
+	u.line(Line{Text: "beat"}) // run still going, but due a report
+	u.line(Line{Text: "beat"})
+	u.flush()
+
+	want := []string{"beat", "[last line repeated 2 times]", "[last line repeated 1 times]"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTailer_Uniq_FlushesOnCancel(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+
+	if err := os.WriteFile(testFile, []byte("start\nbeat\nbeat\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	tailer := NewTailer(TailerConfig{
+		Path:         testFile,
+		Lines:        10,
+		Follow:       true,
+		Uniq:         true,
+		UniqCount:    true,
+		PollInterval: 10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tailer.Tail(ctx, &buf)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	f.WriteString("beat\nbeat\n")
+	f.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	<-done
+
+	// The run spans the initial dump and the follow stream, and is still
+	// going at cancel
+	if got, want := buf.String(), "start\nbeat\n[last line repeated 3 times]\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTailer_Uniq_ResetsOnRotation(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+
+	if err := os.WriteFile(testFile, []byte("same\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	tailer := NewTailer(TailerConfig{
+		Path:         testFile,
+		Lines:        10,
+		Follow:       true,
+		FollowName:   true,
+		Uniq:         true,
+		PollInterval: 10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tailer.Tail(ctx, &buf)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	// Rotate: the new file starts with the same line, which must still show
+	os.Rename(testFile, testFile+".1")
+	if err := os.WriteFile(testFile, []byte("same\n"), 0644); err != nil {
+		t.Fatalf("failed to create new file: %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	<-done
+
+	if got := buf.String(); strings.Count(got, "same\n") != 2 {
+		t.Errorf("expected the line from each file, got %q", got)
+	}
+}
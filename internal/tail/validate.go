@@ -0,0 +1,67 @@
+package tail
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jmurray2011/wail/internal/filesystem"
+)
+
+// ErrInvalidConfig is returned by NewTailerWithValidation for a config with
+// a setting out of range, or settings that contradict each other.
+var ErrInvalidConfig = errors.New("invalid tailer config")
+
+// NewTailerWithValidation creates a new Tailer like NewTailer, but first
+// checks config, so a mistake is reported up front rather than partway
+// through tailing, or not at all where one setting would silently override
+// another.
+func NewTailerWithValidation(config TailerConfig) (Tailer, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return NewTailer(config), nil
+}
+
+// validate returns an ErrInvalidConfig describing the first problem with c,
+// or nil if there is none.
+func (c TailerConfig) validate() error {
+	byteMode := c.Bytes > 0 || c.BytesSet
+	_, _, archiveMember := filesystem.SplitArchivePath(c.Path)
+	checks := []struct {
+		bad     bool
+		problem string
+	}{
+		{c.Lines < 0, "Lines is negative"},
+		{c.Bytes < 0, "Bytes is negative"},
+		{c.StartOffsetSet && c.StartOffset < 0, "StartOffset is negative"},
+		{c.HeadTail < 0, "HeadTail is negative"},
+		{c.ContextBefore < 0 || c.ContextAfter < 0, "ContextBefore or ContextAfter is negative"},
+		{c.RetryTimeout < 0 || c.RetryAttempts < 0, "RetryTimeout or RetryAttempts is negative"},
+		{c.PollInterval < 0 || c.PIDPollInterval < 0, "PollInterval or PIDPollInterval is negative"},
+		{c.PollJitter < 0 || c.PollJitter >= 1, "PollJitter is not a fraction from 0 up to 1"},
+		{c.MaxRate < 0, "MaxRate is negative"},
+		{c.MaxMemory < 0, "MaxMemory is negative"},
+		{c.LinesSet && byteMode, "Lines and Bytes are both set"},
+		{c.StartOffsetSet && (c.LinesSet || byteMode), "StartOffset is set with Lines or Bytes"},
+		{c.BytesThrough < 0, "BytesThrough is negative"},
+		{c.BytesThrough > 0 && (!byteMode || !c.FromStart || c.BytesThrough < c.Bytes), "BytesThrough is set without FromStart Bytes no greater than it"},
+		{c.BytesThrough > 0 && c.Follow, "BytesThrough is set with Follow"},
+		{c.HeadTail > 0 && (c.LinesSet || byteMode || c.StartOffsetSet), "HeadTail is set with Lines, Bytes or StartOffset"},
+		{c.HeadTail > 0 && c.Follow, "HeadTail is set with Follow"},
+		{c.AllButLast && (c.FromStart || byteMode), "AllButLast is set with FromStart or Bytes"},
+		{c.AllButLast && c.Follow, "AllButLast is set with Follow"},
+		{(c.ContextBefore > 0 || c.ContextAfter > 0) && c.Filter == nil, "ContextBefore or ContextAfter is set without Filter"},
+		{c.Filter != nil && byteMode, "Filter is set with Bytes"},
+		{(!c.Since.IsZero() || !c.Until.IsZero()) && byteMode, "Since or Until is set with Bytes"},
+		{!c.Since.IsZero() && !c.Until.IsZero() && c.Since.After(c.Until), "Since is after Until"},
+		{c.Follow && archiveMember, "Follow is set for an archive member, which can't be followed"},
+		{c.FollowName && filesystem.IsSFTPPath(c.Path), "FollowName is set for an sftp path, which can only be followed by descriptor"},
+		{c.WarnRotation && (!c.Follow || c.FollowName), "WarnRotation is set without Follow by descriptor"},
+	}
+	for _, check := range checks {
+		if check.bad {
+			return fmt.Errorf("%w: %s", ErrInvalidConfig, check.problem)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package tail
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewTailerWithValidation(t *testing.T) {
+	since := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		config  TailerConfig
+		wantErr string
+	}{
+		{"defaults", TailerConfig{Path: "app.log"}, ""},
+		{"follow by name with a filter", TailerConfig{Path: "app.log", Follow: true, FollowName: true, Filter: regexp.MustCompile("x"), ContextAfter: 1}, ""},
+		{"negative lines", TailerConfig{Path: "app.log", Lines: -1}, "Lines is negative"},
+		{"lines and bytes", TailerConfig{Path: "app.log", Lines: 5, LinesSet: true, Bytes: 5}, "Lines and Bytes are both set"},
+		{"head-tail with follow", TailerConfig{Path: "app.log", HeadTail: 3, Follow: true}, "HeadTail is set with Follow"},
+		{"all but last with follow", TailerConfig{Path: "app.log", AllButLast: true, Follow: true}, "AllButLast is set with Follow"},
+		{"context without filter", TailerConfig{Path: "app.log", ContextBefore: 2}, "without Filter"},
+		{"filter in byte mode", TailerConfig{Path: "app.log", Bytes: 10, Filter: regexp.MustCompile("x")}, "Filter is set with Bytes"},
+		{"empty time window", TailerConfig{Path: "app.log", Since: since, Until: since.Add(-time.Hour)}, "Since is after Until"},
+		{"jitter of a whole interval", TailerConfig{Path: "app.log", PollJitter: 1}, "PollJitter"},
+		{"following an archive member", TailerConfig{Path: "logs.zip::app.log", Follow: true}, "archive member"},
+		{"sftp by name", TailerConfig{Path: "sftp://web1/var/log/app.log", Follow: true, FollowName: true}, "sftp path"},
+		{"warn rotation by name", TailerConfig{Path: "app.log", Follow: true, FollowName: true, WarnRotation: true}, "WarnRotation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tailer, err := NewTailerWithValidation(tt.config)
+			if tt.wantErr == "" {
+				if err != nil || tailer == nil {
+					t.Fatalf("NewTailerWithValidation() = %v, %v, want a tailer", tailer, err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidConfig) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewTailerWithValidation() error = %v, want an ErrInvalidConfig about %q", err, tt.wantErr)
+			}
+			if tailer != nil {
+				t.Errorf("NewTailerWithValidation() tailer = %v, want nil", tailer)
+			}
+		})
+	}
+}
@@ -0,0 +1,3 @@
+// Package trigger runs commands or calls webhooks for followed lines, so
+// wail can act on log events as well as print them.
+package trigger
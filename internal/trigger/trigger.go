@@ -0,0 +1,200 @@
+package trigger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is a line that fired a trigger.
+type Event struct {
+	Line string    `json:"line"`
+	File string    `json:"file"`
+	Time time.Time `json:"time"`
+}
+
+// Action is something done for each Event.
+type Action interface {
+	Run(ctx context.Context, ev Event) error
+}
+
+// execAction runs a command with placeholders in its arguments replaced.
+type execAction struct {
+	args []string
+}
+
+// NewExec returns an Action that runs command for each event, with {line},
+// {file} and {time} in its arguments replaced by the event's values. The
+// command is split into arguments like a shell would split it, honoring
+// single and double quotes, but is run directly rather than by a shell, so a
+// line can never inject shell syntax.
+func NewExec(command string) (Action, error) {
+	args, err := splitCommand(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, errors.New("empty command")
+	}
+	return &execAction{args: args}, nil
+}
+
+func (a *execAction) Run(ctx context.Context, ev Event) error {
+	r := strings.NewReplacer("{line}", ev.Line, "{file}", ev.File, "{time}", ev.Time.Format(time.RFC3339))
+	args := make([]string, len(a.args))
+	for i, arg := range a.args {
+		args[i] = r.Replace(arg)
+	}
+
+	out, err := exec.CommandContext(ctx, args[0], args[1:]...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s: %w: %s", args[0], err, msg)
+		}
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	return nil
+}
+
+// splitCommand splits command into arguments at unquoted whitespace. Single
+// or double quotes group words into one argument and are removed.
+func splitCommand(command string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+	for _, r := range command {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// webhookTimeout bounds each webhook request.
+const webhookTimeout = 10 * time.Second
+
+// webhookAction POSTs each event as JSON.
+type webhookAction struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhook returns an Action that POSTs each event to url as a JSON object
+// with line, file and time fields. Any response other than 2xx is an error.
+func NewWebhook(url string) Action {
+	return &webhookAction{url: url, client: &http.Client{Timeout: webhookTimeout}}
+}
+
+func (a *webhookAction) Run(ctx context.Context, ev Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body) // So the connection can be reused
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s: %s", a.url, resp.Status)
+	}
+	return nil
+}
+
+// workers is how many actions may run at once. Events beyond that wait in a
+// queue of queueSize, and once that is full Fire blocks, slowing the follow
+// loop rather than spawning without limit.
+const (
+	workers   = 4
+	queueSize = 64
+)
+
+// Runner runs actions for events on a bounded pool of workers.
+type Runner struct {
+	ctx     context.Context
+	actions []Action
+	stderr  io.Writer
+	events  chan Event
+	wg      sync.WaitGroup
+	mu      sync.Mutex // Serializes error reports
+}
+
+// NewRunner starts workers goroutines running actions for each event passed
+// to Fire. Failures are reported to stderr and don't stop the runner.
+// Actions are cancelled with ctx.
+func NewRunner(ctx context.Context, actions []Action, stderr io.Writer) *Runner {
+	r := &Runner{ctx: ctx, actions: actions, stderr: stderr, events: make(chan Event, queueSize)}
+	for range workers {
+		r.wg.Add(1)
+		go r.work()
+	}
+	return r
+}
+
+func (r *Runner) work() {
+	defer r.wg.Done()
+	for ev := range r.events {
+		for _, a := range r.actions {
+			if err := a.Run(r.ctx, ev); err != nil && r.ctx.Err() == nil {
+				r.mu.Lock()
+				fmt.Fprintf(r.stderr, "wail: %s: trigger failed: %v\n", ev.File, err)
+				r.mu.Unlock()
+			}
+		}
+	}
+}
+
+// Fire queues ev, waiting for room if the queue is full. Events fired once
+// ctx is cancelled are discarded.
+func (r *Runner) Fire(ev Event) {
+	select {
+	case r.events <- ev:
+	case <-r.ctx.Done():
+	}
+}
+
+// Close waits for queued events to be handled and stops the workers. Fire
+// must not be called after Close.
+func (r *Runner) Close() {
+	close(r.events)
+	r.wg.Wait()
+}
@@ -0,0 +1,192 @@
+package trigger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+		wantErr bool
+	}{
+		{"notify {line}", []string{"notify", "{line}"}, false},
+		{"  spaced   out  ", []string{"spaced", "out"}, false},
+		{`logger -t wail "{file}: {line}"`, []string{"logger", "-t", "wail", "{file}: {line}"}, false},
+		{`echo 'it''s' ""`, []string{"echo", "its", ""}, false},
+		{`echo "unterminated`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			got, err := splitCommand(tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestHelperProcess isn't a real test: TestExec runs the test binary as the
+// triggered command, and this appends its arguments to the file named by
+// WAIL_TRIGGER_HELPER.
+func TestHelperProcess(t *testing.T) {
+	out := os.Getenv("WAIL_TRIGGER_HELPER")
+	if out == "" {
+		return
+	}
+	args := os.Args
+	for i, arg := range args {
+		if arg == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+	f, err := os.OpenFile(out, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		os.Exit(2)
+	}
+	f.WriteString(strings.Join(args, "|") + "\n")
+	f.Close()
+	os.Exit(0)
+}
+
+func TestExec(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out.txt")
+	t.Setenv("WAIL_TRIGGER_HELPER", out)
+
+	action, err := NewExec(`"` + os.Args[0] + `" -test.run=^TestHelperProcess$ -- {file} "got {line}"`)
+	if err != nil {
+		t.Fatalf("NewExec() error: %v", err)
+	}
+	ev := Event{Line: "error; rm -rf /", File: "app.log", Time: time.Now()}
+	if err := action.Run(context.Background(), ev); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	// The line arrives as one argument, shell syntax and all
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("reading helper output: %v", err)
+	}
+	if want := "app.log|got error; rm -rf /\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestExec_Failure(t *testing.T) {
+	action, err := NewExec(filepath.Join(t.TempDir(), "no-such-command"))
+	if err != nil {
+		t.Fatalf("NewExec() error: %v", err)
+	}
+	if err := action.Run(context.Background(), Event{}); err == nil {
+		t.Error("expected error running a missing command")
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var mu sync.Mutex
+	var got []Event
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		var ev Event
+		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		mu.Lock()
+		got = append(got, ev)
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	action := NewWebhook(srv.URL)
+	ev := Event{Line: "disk full", File: "app.log", Time: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)}
+	if err := action.Run(context.Background(), ev); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if len(got) != 1 || got[0].Line != ev.Line || got[0].File != ev.File || !got[0].Time.Equal(ev.Time) {
+		t.Errorf("received %+v, want %+v", got, ev)
+	}
+
+	status = http.StatusInternalServerError
+	if err := action.Run(context.Background(), ev); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Run() error = %v, want a 500 status error", err)
+	}
+}
+
+// blockingAction records how many runs overlap, holding each until release
+// is closed.
+type blockingAction struct {
+	running, peak, runs atomic.Int32
+	release             chan struct{}
+}
+
+func (a *blockingAction) Run(ctx context.Context, ev Event) error {
+	n := a.running.Add(1)
+	defer a.running.Add(-1)
+	for {
+		p := a.peak.Load()
+		if n <= p || a.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	<-a.release
+	a.runs.Add(1)
+	return nil
+}
+
+func TestRunner_BoundsConcurrency(t *testing.T) {
+	action := &blockingAction{release: make(chan struct{})}
+	r := NewRunner(context.Background(), []Action{action}, &bytes.Buffer{})
+
+	for range 20 {
+		r.Fire(Event{Line: "match"})
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(action.release)
+	r.Close()
+
+	if peak := action.peak.Load(); peak > workers {
+		t.Errorf("%d actions ran at once, want at most %d", peak, workers)
+	}
+	if runs := action.runs.Load(); runs != 20 {
+		t.Errorf("ran %d times, want 20", runs)
+	}
+}
+
+// failingAction always fails.
+type failingAction struct{}
+
+func (failingAction) Run(ctx context.Context, ev Event) error {
+	return os.ErrPermission
+}
+
+func TestRunner_ReportsErrors(t *testing.T) {
+	var stderr bytes.Buffer
+	r := NewRunner(context.Background(), []Action{failingAction{}}, &stderr)
+	r.Fire(Event{Line: "match", File: "app.log"})
+	r.Close()
+
+	if got := stderr.String(); !strings.Contains(got, "wail: app.log: trigger failed:") {
+		t.Errorf("stderr = %q", got)
+	}
+}
@@ -1,2 +1,3 @@
-// Package watcher provides polling-based file watching.
+// Package watcher provides file watching, by file system events where the
+// platform and file system support them and by polling otherwise.
 package watcher
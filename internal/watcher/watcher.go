@@ -4,18 +4,25 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
+
+	"github.com/fsnotify/fsnotify"
 )
 
 // Event represents a file change event.
 type Event struct {
 	// Size is the new file size.
 	Size int64
+	// PrevSize is the size at the previous event, or when watching started.
+	// When the file grew, bytes PrevSize to Size are the new ones; when it
+	// was truncated, PrevSize-Size bytes were cut.
+	PrevSize int64
 	// Truncated is true if the file was truncated (size decreased).
 	Truncated bool
 }
 
-// Watcher watches a file for changes using polling.
+// Watcher watches a file for changes.
 type Watcher interface {
 	// Watch starts watching the file and sends events on the returned channel.
 	// The channel is closed when the context is cancelled or an error occurs.
@@ -23,12 +30,29 @@ type Watcher interface {
 	Watch(ctx context.Context) (<-chan Event, error)
 }
 
+// Backend selects how a Watcher notices changes.
+type Backend int
+
+const (
+	// BackendAuto uses file system events, falling back to polling if they
+	// are unavailable or fail.
+	BackendAuto Backend = iota
+	// BackendPoll stats the file every PollInterval. It is the reliable
+	// choice on network file systems (SMB, NFS), where events may never come.
+	BackendPoll
+	// BackendEvents uses file system events only; Watch fails if they are
+	// unavailable.
+	BackendEvents
+)
+
 // Config holds watcher configuration.
 type Config struct {
 	// Path is the file to watch.
 	Path string
-	// PollInterval is how often to check for changes.
+	// PollInterval is how often to check for changes when polling.
 	PollInterval time.Duration
+	// Backend selects events or polling; the zero value is BackendAuto.
+	Backend Backend
 }
 
 // pollingWatcher implements Watcher using polling.
@@ -36,9 +60,18 @@ type pollingWatcher struct {
 	config Config
 }
 
-// NewWatcher creates a new polling-based file watcher.
+// eventWatcher implements Watcher using file system events (inotify,
+// kqueue, ReadDirectoryChangesW).
+type eventWatcher struct {
+	config Config
+}
+
+// NewWatcher creates a new file watcher using the configured backend.
 func NewWatcher(config Config) Watcher {
-	return &pollingWatcher{config: config}
+	if config.Backend == BackendPoll {
+		return &pollingWatcher{config: config}
+	}
+	return &eventWatcher{config: config}
 }
 
 // Watch starts watching the file and sends events on the returned channel.
@@ -50,38 +83,108 @@ func (w *pollingWatcher) Watch(ctx context.Context) (<-chan Event, error) {
 	}
 
 	events := make(chan Event)
-	lastSize := info.Size()
-
 	go func() {
 		defer close(events)
+		poll(ctx, w.config, info.Size(), events)
+	}()
+
+	return events, nil
+}
+
+// poll sends an event on events each time the file's size differs from
+// lastSize, checking every PollInterval until ctx is cancelled.
+func poll(ctx context.Context, config Config, lastSize int64, events chan<- Event) {
+	ticker := time.NewTicker(config.PollInterval)
+	defer ticker.Stop()
 
-		ticker := time.NewTicker(w.config.PollInterval)
-		defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(config.Path)
+			if err != nil {
+				// File might be temporarily unavailable during rotation
+				continue
+			}
+
+			currentSize := info.Size()
+			if currentSize == lastSize {
+				continue
+			}
+
+			evt := Event{Size: currentSize, PrevSize: lastSize}
+			if currentSize < lastSize {
+				evt.Truncated = true
+			}
+
+			select {
+			case events <- evt:
+				lastSize = currentSize
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
 
+// Watch starts watching the file and sends events on the returned channel.
+// The file's directory is watched rather than the file itself, so an event
+// is also sent when the file is replaced (e.g. by rotation), even if its
+// size is unchanged.
+func (w *eventWatcher) Watch(ctx context.Context) (<-chan Event, error) {
+	info, err := os.Stat(w.config.Path)
+	if err != nil {
+		return nil, fmt.Errorf("accessing %s: %w", w.config.Path, err)
+	}
+
+	fw, err := fsnotify.NewWatcher()
+	if err == nil {
+		if err = fw.Add(filepath.Dir(w.config.Path)); err != nil {
+			fw.Close()
+		}
+	}
+	if err != nil {
+		if w.config.Backend == BackendEvents {
+			return nil, fmt.Errorf("watching %s for events: %w", w.config.Path, err)
+		}
+		return (&pollingWatcher{config: w.config}).Watch(ctx)
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		defer fw.Close()
+
+		name := filepath.Clean(w.config.Path)
+		lastSize := info.Size()
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-ticker.C:
-				info, err := os.Stat(w.config.Path)
-				if err != nil {
-					// File might be temporarily unavailable during rotation
-					continue
+			case _, ok := <-fw.Errors:
+				// e.g. the kernel's event queue overflowed
+				if ok && w.config.Backend == BackendAuto {
+					fw.Close()
+					poll(ctx, w.config, lastSize, events)
 				}
-
-				currentSize := info.Size()
-				if currentSize == lastSize {
+				return
+			case ev, ok := <-fw.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(ev.Name) != name || ev.Op == fsnotify.Chmod {
 					continue
 				}
-
-				evt := Event{Size: currentSize}
-				if currentSize < lastSize {
-					evt.Truncated = true
+				info, err := os.Stat(w.config.Path)
+				if err != nil {
+					continue // Removed, or mid-rotation; a Create follows
 				}
 
+				evt := Event{Size: info.Size(), PrevSize: lastSize, Truncated: info.Size() < lastSize}
 				select {
 				case events <- evt:
-					lastSize = currentSize
+					lastSize = info.Size()
 				case <-ctx.Done():
 					return
 				}
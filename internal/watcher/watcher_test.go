@@ -51,6 +51,9 @@ func TestWatcher_DetectsGrowth(t *testing.T) {
 		if evt.Size <= 6 { // "line1\n" = 6 bytes
 			t.Errorf("expected Size > 6, got %d", evt.Size)
 		}
+		if evt.PrevSize != 6 {
+			t.Errorf("expected PrevSize 6, got %d", evt.PrevSize)
+		}
 	case <-ctx.Done():
 		t.Fatal("timeout waiting for growth event")
 	}
@@ -93,6 +96,9 @@ func TestWatcher_DetectsTruncation(t *testing.T) {
 		if !evt.Truncated {
 			t.Error("expected Truncated=true for truncation")
 		}
+		if evt.PrevSize != 18 { // The size before truncation
+			t.Errorf("expected PrevSize 18, got %d", evt.PrevSize)
+		}
 	case <-ctx.Done():
 		t.Fatal("timeout waiting for truncation event")
 	}
@@ -153,3 +159,94 @@ func TestWatcher_NonExistentFile(t *testing.T) {
 		t.Error("expected error for non-existent file")
 	}
 }
+
+func TestWatcher_Backends(t *testing.T) {
+	backends := map[string]Backend{"auto": BackendAuto, "poll": BackendPoll, "events": BackendEvents}
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			testFile := filepath.Join(dir, "test.log")
+
+			if err := os.WriteFile(testFile, []byte("line1\n"), 0644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			w := NewWatcher(Config{
+				Path:         testFile,
+				PollInterval: 10 * time.Millisecond,
+				Backend:      backend,
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+
+			events, err := w.Watch(ctx)
+			if err != nil {
+				t.Fatalf("Watch() error = %v", err)
+			}
+
+			time.Sleep(20 * time.Millisecond)
+			if err := os.WriteFile(testFile, []byte("line1\nline2\n"), 0644); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+
+			// Events may report the truncation part of the rewrite first;
+			// each event starts where the one before it ended
+			prev := int64(6)
+			for {
+				select {
+				case evt, ok := <-events:
+					if !ok {
+						t.Fatal("channel closed without event")
+					}
+					if evt.PrevSize != prev {
+						t.Errorf("PrevSize = %d, want %d", evt.PrevSize, prev)
+					}
+					prev = evt.Size
+					if evt.Size == 12 {
+						return
+					}
+				case <-ctx.Done():
+					t.Fatal("timeout waiting for growth event")
+				}
+			}
+		})
+	}
+}
+
+func TestWatcher_EventsReportReplacement(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test.log")
+
+	if err := os.WriteFile(testFile, []byte("line1\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	w := NewWatcher(Config{Path: testFile, Backend: BackendEvents})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	events, err := w.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	// Rotate to a same-sized file, which polling on size alone would miss
+	replacement := filepath.Join(dir, "new.log")
+	if err := os.WriteFile(replacement, []byte("line2\n"), 0644); err != nil {
+		t.Fatalf("failed to create replacement: %v", err)
+	}
+	if err := os.Rename(replacement, testFile); err != nil {
+		t.Fatalf("failed to rename: %v", err)
+	}
+
+	select {
+	case _, ok := <-events:
+		if !ok {
+			t.Fatal("channel closed without event")
+		}
+	case <-ctx.Done():
+		t.Fatal("timeout waiting for replacement event")
+	}
+}